@@ -1,7 +1,13 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"nekozanedex/internal/centrifugo"
@@ -12,6 +18,7 @@ import (
 	"nekozanedex/internal/repositories"
 	"nekozanedex/internal/routes"
 	"nekozanedex/internal/services"
+	"nekozanedex/pkg/cache"
 
 	_ "nekozanedex/docs" // Swagger docs
 
@@ -63,11 +70,24 @@ func main() {
 		&models.RefreshToken{},
 		&models.CommentLike{},
 		&models.CommentReport{},
+		&models.StoryRating{},
+		&models.ChatRoomSetting{},
+		&models.ChatMute{},
+		&models.ChapterRevision{},
+		&models.Volume{},
+		&models.StoryRanking{},
+		&models.StorySimilarity{},
+		&models.MediaAsset{},
 	); err != nil {
 		log.Fatal("Không thể migrate database:", err)
 	}
 	log.Println("Database Đã Migrate Thành Công")
 
+	// Full-text search index cho stories (unaccent + tsvector)
+	if err := database.SetupStorySearch(db); err != nil {
+		log.Fatal("Không thể setup full-text search:", err)
+	}
+
 	// One-time migration: Generate tag_name for existing users
 	var usersWithoutTagName []models.User
 	if err := db.Where("tag_name IS NULL OR tag_name = ''").Find(&usersWithoutTagName).Error; err == nil && len(usersWithoutTagName) > 0 {
@@ -96,6 +116,14 @@ func main() {
 	readingHistoryRepo := repositories.NewReadingHistoryRepository(db)
 	userSettingsRepo := repositories.NewUserSettingsRepository(db)
 	commentReportRepo := repositories.NewCommentReportRepository(db)
+	storyRatingRepo := repositories.NewStoryRatingRepository(db)
+	typoReportRepo := repositories.NewTypoReportRepository(db)
+	chatRepo := repositories.NewChatRepository(db)
+	chapterRevisionRepo := repositories.NewChapterRevisionRepository(db)
+	volumeRepo := repositories.NewVolumeRepository(db)
+	storyRankingRepo := repositories.NewStoryRankingRepository(db)
+	storySimilarityRepo := repositories.NewStorySimilarityRepository(db)
+	mediaAssetRepo := repositories.NewMediaAssetRepository(db)
 
 	// Init Centrifugo client
 	centrifugoClient := centrifugo.NewClient(
@@ -105,6 +133,30 @@ func main() {
 	)
 	log.Printf("[Centrifugo] Client initialized with URL: %s", cfg.Centrifugo.URL)
 
+	// Init cache cho các read public nóng (truyện, danh sách chapter, thể loại)
+	cacheDriver := cfg.Cache.Driver
+	var cacheStore cache.Cache = cache.NewMemory(cfg.Cache.MaxEntries)
+	switch cacheDriver {
+	case "none":
+		cacheStore = cache.Noop{}
+	case "redis":
+		redisCache, err := cache.NewRedis(cache.RedisOptions{
+			Addr:      cfg.Cache.RedisAddr,
+			Password:  cfg.Cache.RedisPassword,
+			DB:        cfg.Cache.RedisDB,
+			Namespace: "nekozanedex:",
+		})
+		if err != nil {
+			log.Printf("⚠️ Redis cache not available, falling back to memory: %v", err)
+			cacheDriver = "memory"
+		} else {
+			cacheStore = redisCache
+			defer redisCache.Close()
+		}
+	}
+	publicCache := services.NewPublicCache(cacheStore, time.Duration(cfg.Cache.TTLSeconds)*time.Second, storyRepo)
+	log.Printf("[Cache] Driver: %s, TTL: %ds", cacheDriver, cfg.Cache.TTLSeconds)
+
 	// Start background cleanup job for refresh tokens
 	go func() {
 		ticker := time.NewTicker(6 * time.Hour)
@@ -126,26 +178,71 @@ func main() {
 
 	// Initialize services - Khởi tạo service
 	authService := services.NewAuthService(userRepo, refreshTokenRepo, cfg) // Cập nhật với refreshTokenRepo
+	notificationService := services.NewNotificationService(notificationRepo, bookmarkRepo, centrifugoClient)
+	viewAggregator := services.NewViewAggregator(storyViewRepo) // Gom lượt xem, flush theo lô
+	chapterService := services.NewChapterService(chapterRepo, storyRepo, chapterRevisionRepo, volumeRepo, readingHistoryRepo, viewAggregator, notificationService, publicCache)
 
-	// Initialize upload service (optional - requires Cloudinary config)
+	// Initialize upload service - Storage driver chọn theo STORAGE_DRIVER (local, s3, cloudinary)
 	var uploadHandler *handlers.UploadHandler
-	uploadService, err := services.NewUploadService(cfg)
+	uploadService, err := services.NewUploadService(cfg, mediaAssetRepo)
 	if err != nil {
 		log.Printf("⚠️ Upload service not initialized: %v", err)
-		log.Println("💡 Add CLOUDINARY_CLOUD_NAME, CLOUDINARY_API_KEY, CLOUDINARY_API_SECRET to .env")
+		log.Println("💡 Check STORAGE_DRIVER and the matching S3_* / CLOUDINARY_* variables in .env")
 	} else {
-		uploadHandler = handlers.NewUploadHandler(uploadService)
-		log.Println("✅ Upload service initialized (Cloudinary)")
+		uploadHandler = handlers.NewUploadHandler(uploadService, chapterService)
+		log.Printf("✅ Upload service initialized (%s)", cfg.Storage.Driver)
 	}
 
-	// Pass uploadService to storyService for old cover image deletion
-	storyService := services.NewStoryService(storyRepo, genreRepo, storyViewRepo, uploadService)
-	genreService := services.NewGenreService(genreRepo)
-	chapterService := services.NewChapterService(chapterRepo, storyRepo)
+	storyService := services.NewStoryService(storyRepo, genreRepo, viewAggregator, storyRatingRepo, publicCache)
+	genreService := services.NewGenreService(genreRepo, publicCache)
 	bookmarkService := services.NewBookmarkService(bookmarkRepo, storyRepo)
 	commentService := services.NewCommentService(commentRepo, storyRepo, chapterRepo)
-	notificationService := services.NewNotificationService(notificationRepo, centrifugoClient)
 	commentReportService := services.NewCommentReportService(commentReportRepo)
+	storyRatingService := services.NewStoryRatingService(storyRatingRepo, storyRepo, publicCache)
+	typoReportService := services.NewTypoReportService(typoReportRepo, chapterRepo, notificationService, publicCache)
+	chatService := services.NewChatService(chatRepo, storyRepo, centrifugoClient)
+	chapterRevisionService := services.NewChapterRevisionService(chapterRevisionRepo, chapterRepo, publicCache)
+	volumeService := services.NewVolumeService(volumeRepo, storyRepo, publicCache)
+	exportService := services.NewExportService(storyRepo)
+	feedService := services.NewFeedService(chapterRepo, storyRepo, genreRepo, cfg.App.SiteURL, cacheStore)
+	sitemapService := services.NewSitemapService(storyRepo, chapterRepo, cfg.App.SiteURL, cfg.SEO, cacheStore)
+	rankingService := services.NewRankingService(storyRankingRepo)
+	recommendationService := services.NewRecommendationService(storySimilarityRepo, storyRepo)
+	analyticsService := services.NewAnalyticsService(storyViewRepo, commentRepo, bookmarkRepo)
+
+	// Start background job for trending rankings (day/week/month)
+	go func() {
+		ticker := time.NewTicker(15 * time.Minute)
+		defer ticker.Stop()
+
+		// Run once at startup
+		if err := rankingService.RefreshRankings(); err != nil {
+			log.Printf("❌ Failed to refresh story rankings: %v", err)
+		}
+
+		for range ticker.C {
+			if err := rankingService.RefreshRankings(); err != nil {
+				log.Printf("❌ Failed to refresh story rankings: %v", err)
+			}
+		}
+	}()
+
+	// Start background job for "readers also read" similarities
+	go func() {
+		ticker := time.NewTicker(6 * time.Hour)
+		defer ticker.Stop()
+
+		// Run once at startup
+		if err := recommendationService.RefreshSimilarities(); err != nil {
+			log.Printf("❌ Failed to refresh story similarities: %v", err)
+		}
+
+		for range ticker.C {
+			if err := recommendationService.RefreshSimilarities(); err != nil {
+				log.Printf("❌ Failed to refresh story similarities: %v", err)
+			}
+		}
+	}()
 
 	// Start background job for scheduled chapter publishing
 	go func() {
@@ -168,9 +265,43 @@ func main() {
 		}
 	}()
 
+	// Start background job for orphaned media GC (cần upload service để xóa file)
+	var mediaGCHandler *handlers.MediaGCHandler
+	if uploadService != nil {
+		mediaGCService := services.NewMediaGCService(mediaAssetRepo, uploadService, time.Duration(cfg.MediaGC.GraceHours)*time.Hour)
+		mediaGCHandler = handlers.NewMediaGCHandler(mediaGCService)
+
+		if cfg.MediaGC.IntervalMinutes > 0 {
+			go func() {
+				ticker := time.NewTicker(time.Duration(cfg.MediaGC.IntervalMinutes) * time.Minute)
+				defer ticker.Stop()
+
+				// Track file upload từ trước khi có media_assets, rồi run once at startup
+				if tracked, err := mediaGCService.Backfill(); err != nil {
+					log.Printf("❌ Failed to backfill media assets: %v", err)
+				} else if tracked > 0 {
+					log.Printf("🗂️ Tracked %d existing media file(s)", tracked)
+				}
+				if report, err := mediaGCService.Sweep(false); err != nil {
+					log.Printf("❌ Failed to sweep orphaned media: %v", err)
+				} else if report.Deleted > 0 {
+					log.Printf("🧹 Deleted %d orphaned media file(s), freed %d bytes", report.Deleted, report.FreedBytes)
+				}
+
+				for range ticker.C {
+					if report, err := mediaGCService.Sweep(false); err != nil {
+						log.Printf("❌ Failed to sweep orphaned media: %v", err)
+					} else if report.Deleted > 0 {
+						log.Printf("🧹 Deleted %d orphaned media file(s), freed %d bytes", report.Deleted, report.FreedBytes)
+					}
+				}
+			}()
+		}
+	}
+
 	// Initialize handlers - Khởi tạo handler
 	h := &routes.Handlers{
-		Auth:           handlers.NewAuthHandler(authService, uploadService, cfg),
+		Auth:           handlers.NewAuthHandler(authService, cfg),
 		Story:          handlers.NewStoryHandler(storyService),
 		Chapter:        handlers.NewChapterHandler(chapterService),
 		Genre:          handlers.NewGenreHandler(genreService),
@@ -183,6 +314,18 @@ func main() {
 		ReadingHistory: handlers.NewReadingHistoryHandler(readingHistoryRepo),
 		UserSettings:   handlers.NewUserSettingsHandler(services.NewUserSettingsService(userSettingsRepo)),
 		Centrifugo:     handlers.NewCentrifugoHandler(centrifugoClient),
+		Rating:         handlers.NewRatingHandler(storyRatingService),
+		TypoReport:     handlers.NewTypoReportHandler(typoReportService),
+		Chat:           handlers.NewChatHandler(chatService),
+		Revision:       handlers.NewChapterRevisionHandler(chapterRevisionService),
+		Volume:         handlers.NewVolumeHandler(volumeService),
+		Export:         handlers.NewExportHandler(exportService),
+		Feed:           handlers.NewFeedHandler(feedService),
+		Sitemap:        handlers.NewSitemapHandler(sitemapService),
+		Ranking:        handlers.NewRankingHandler(rankingService),
+		Recommendation: handlers.NewRecommendationHandler(recommendationService),
+		Analytics:      handlers.NewAnalyticsHandler(analyticsService),
+		MediaGC:        mediaGCHandler,
 	}
 
 	// Setup Gin router - Setup router cho Gin
@@ -193,8 +336,29 @@ func main() {
 	routes.SetupRoutes(r, cfg, h)
 
 	// Start server
-	log.Printf("🚀 Server starting on port %s", cfg.Server.Port)
-	if err := r.Run(":" + cfg.Server.Port); err != nil {
-		log.Fatal("Không thể start server:", err)
+	srv := &http.Server{
+		Addr:    ":" + cfg.Server.Port,
+		Handler: r,
+	}
+	go func() {
+		log.Printf("🚀 Server starting on port %s", cfg.Server.Port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Không thể start server:", err)
+		}
+	}()
+
+	// Graceful shutdown: chờ request đang chạy xong rồi flush lượt xem và fan-out notification còn trong RAM
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	log.Println("🛑 Shutting down server...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("❌ Server forced to shutdown: %v", err)
 	}
+	viewAggregator.Close()
+	notificationService.Close()
+	log.Println("👋 Server stopped")
 }
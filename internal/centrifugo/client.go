@@ -61,6 +61,44 @@ func (c *Client) Publish(channel string, data interface{}) error {
 	return nil
 }
 
+// PublishMessage - 1 lệnh publish trong PublishBatch
+type PublishMessage struct {
+	Channel string      `json:"channel"`
+	Data    interface{} `json:"data"`
+}
+
+// PublishBatch - Gửi nhiều lệnh publish trong 1 request (/api/batch)
+// Dùng khi fan-out tới nhiều channel cá nhân để tránh hàng nghìn HTTP request
+func (c *Client) PublishBatch(messages []PublishMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	commands := make([]map[string]interface{}, 0, len(messages))
+	for _, msg := range messages {
+		commands = append(commands, map[string]interface{}{"publish": msg})
+	}
+	body, _ := json.Marshal(map[string]interface{}{"commands": commands})
+	req, _ := http.NewRequest("POST", c.apiURL+"/api/batch", bytes.NewBuffer(body))
+	req.Header.Set("authorization", "apikey "+c.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		fmt.Printf("[Centrifugo] Batch publish error: %v\n", err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		fmt.Printf("[Centrifugo] Batch publish failed: %d - %s\n", resp.StatusCode, string(bodyBytes))
+		return fmt.Errorf("Centrifugo error: %d", resp.StatusCode)
+	}
+	fmt.Printf("[Centrifugo] Batch published %d message(s)\n", len(messages))
+	return nil
+}
+
 type CommentEvent struct {
 	Type    string      `json:"type"`
 	Comment interface{} `json:"comment,omitempty"`
@@ -20,6 +20,10 @@ type Config struct {
 	Cloudinary CloudinaryConfig
 	CSRF       CSRFConfig
 	CORS       CORSConfig
+	SEO        SEOConfig
+	Cache      CacheConfig
+	Storage    StorageConfig
+	MediaGC    MediaGCConfig
 }
 
 type CentrifugoConfig struct {
@@ -32,6 +36,43 @@ type CentrifugoConfig struct {
 type AppConfig struct {
 	Env          string
 	IsProduction bool
+	SiteURL      string // URL public của frontend, dùng cho link trong feed/sitemap
+}
+
+// SEOConfig - Cấu hình robots.txt
+type SEOConfig struct {
+	RobotsDisallow []string // Các path không cho crawler truy cập
+	RobotsBlockAll bool     // Chặn toàn bộ crawler (dev/staging)
+}
+
+// CacheConfig - Cấu hình cache cho các read public (memory, redis hoặc none)
+type CacheConfig struct {
+	Driver        string
+	TTLSeconds    int
+	MaxEntries    int // Chỉ dùng cho driver memory
+	RedisAddr     string
+	RedisPassword string
+	RedisDB       int
+}
+
+// StorageConfig - Nơi lưu file upload: local (disk, Gin serve /uploads), s3 (S3/MinIO) hoặc cloudinary
+type StorageConfig struct {
+	Driver      string
+	LocalDir    string
+	PublicURL   string // URL public của LocalDir
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	S3PublicURL string // CDN trước bucket, rỗng = URL object trên endpoint
+	S3PathStyle bool
+}
+
+// MediaGCConfig - Job dọn file upload không còn được tham chiếu
+type MediaGCConfig struct {
+	IntervalMinutes int // 0 = tắt job (report dry-run vẫn dùng được)
+	GraceHours      int // File phải mồ côi liên tục ngần này giờ mới bị xóa
 }
 
 type SecurityConfig struct {
@@ -112,15 +153,28 @@ func LoadConfig() (*Config, error) {
 		accessExpireSeconds = accessExpireMinutes * 60
 	}
 	refreshExpire, _ := strconv.Atoi(getEnv("JWT_REFRESH_EXPIRE_DAYS", "7"))
+	cacheTTL, _ := strconv.Atoi(getEnv("CACHE_TTL_SECONDS", "60"))
+	cacheMaxEntries, _ := strconv.Atoi(getEnv("CACHE_MAX_ENTRIES", "10000"))
+	redisDB, _ := strconv.Atoi(getEnv("REDIS_DB", "0"))
 	cookieMaxAge, _ := strconv.Atoi(getEnv("JWT_COOKIE_MAX_AGE", "604800"))
+	port := getEnv("PORT", "9091")
+	mediaGCInterval, _ := strconv.Atoi(getEnv("MEDIA_GC_INTERVAL_MINUTES", "60"))
+	mediaGCGrace, _ := strconv.Atoi(getEnv("MEDIA_GC_GRACE_HOURS", "72"))
+
+	// Mặc định: có Cloudinary thì dùng Cloudinary, không thì lưu local để dev/test vẫn upload được
+	storageDriver := "local"
+	if os.Getenv("CLOUDINARY_CLOUD_NAME") != "" && os.Getenv("CLOUDINARY_API_KEY") != "" && os.Getenv("CLOUDINARY_API_SECRET") != "" {
+		storageDriver = "cloudinary"
+	}
 
 	return &Config{
 		App: AppConfig{
 			Env:          env,
 			IsProduction: isProduction,
+			SiteURL:      strings.TrimRight(getEnv("SITE_URL", "http://localhost:3000"), "/"),
 		},
 		Server: ServerConfig{
-			Port:    getEnv("PORT", "9091"),
+			Port:    port,
 			GinMode: getEnv("GIN_MODE", "debug"),
 		},
 		Database: DatabaseConfig{
@@ -170,6 +224,35 @@ func LoadConfig() (*Config, error) {
 			ProdOrigins:    getEnvAsSlice("CORS_PROD_ORIGINS", "https://nekozanedex.com,https://www.nekozanedex.com"),
 			StagingOrigins: getEnvAsSlice("CORS_STAGING_ORIGINS", "https://staging.nekozanedex.com"),
 		},
+		SEO: SEOConfig{
+			RobotsDisallow: getEnvAsSlice("ROBOTS_DISALLOW", "/api/,/swagger/"),
+			// Mặc định chỉ production mới cho phép index
+			RobotsBlockAll: getEnv("ROBOTS_BLOCK_ALL", strconv.FormatBool(!isProduction)) == "true",
+		},
+		Cache: CacheConfig{
+			Driver:        strings.ToLower(getEnv("CACHE_DRIVER", "memory")),
+			TTLSeconds:    cacheTTL,
+			MaxEntries:    cacheMaxEntries,
+			RedisAddr:     getEnv("REDIS_ADDR", "localhost:6379"),
+			RedisPassword: getEnv("REDIS_PASSWORD", ""),
+			RedisDB:       redisDB,
+		},
+		Storage: StorageConfig{
+			Driver:      strings.ToLower(getEnv("STORAGE_DRIVER", storageDriver)),
+			LocalDir:    getEnv("STORAGE_LOCAL_DIR", "./uploads"),
+			PublicURL:   strings.TrimRight(getEnv("STORAGE_PUBLIC_URL", "http://localhost:"+port+"/uploads"), "/"),
+			S3Endpoint:  getEnv("S3_ENDPOINT", ""),
+			S3Region:    getEnv("S3_REGION", "us-east-1"),
+			S3Bucket:    getEnv("S3_BUCKET", ""),
+			S3AccessKey: getEnv("S3_ACCESS_KEY", ""),
+			S3SecretKey: getEnv("S3_SECRET_KEY", ""),
+			S3PublicURL: getEnv("S3_PUBLIC_URL", ""),
+			S3PathStyle: getEnv("S3_FORCE_PATH_STYLE", "true") == "true",
+		},
+		MediaGC: MediaGCConfig{
+			IntervalMinutes: mediaGCInterval,
+			GraceHours:      mediaGCGrace,
+		},
 	}, nil
 }
 
@@ -0,0 +1,44 @@
+package database
+
+import (
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// StorySearchVectorSQL - Biểu thức tạo tsvector cho một row của bảng stories
+// Trọng số: A = tên truyện/tên gốc/tên phụ, B = tác giả/dịch giả, C = mô tả
+// Dùng config 'simple' + unaccent để "tien hiep" khớp với "tiên hiệp"
+const StorySearchVectorSQL = `
+	setweight(to_tsvector('simple', unaccent(coalesce(title, ''))), 'A') ||
+	setweight(to_tsvector('simple', unaccent(coalesce(original_title, ''))), 'A') ||
+	setweight(to_tsvector('simple', unaccent(coalesce(
+		CASE WHEN jsonb_typeof(alt_titles) = 'array'
+			THEN (SELECT string_agg(value, ' ') FROM jsonb_array_elements_text(alt_titles))
+		END, ''))), 'A') ||
+	setweight(to_tsvector('simple', unaccent(coalesce(author_name, '') || ' ' || coalesce(translator, ''))), 'B') ||
+	setweight(to_tsvector('simple', unaccent(coalesce(description, ''))), 'C')`
+
+// SetupStorySearch - Tạo extension unaccent, cột search_vector + GIN index và backfill các row cũ
+func SetupStorySearch(db *gorm.DB) error {
+	statements := []string{
+		`CREATE EXTENSION IF NOT EXISTS unaccent`,
+		`ALTER TABLE stories ADD COLUMN IF NOT EXISTS search_vector tsvector`,
+		`CREATE INDEX IF NOT EXISTS idx_stories_search_vector ON stories USING GIN (search_vector)`,
+	}
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("setup full-text search thất bại: %w", err)
+		}
+	}
+
+	result := db.Exec(`UPDATE stories SET search_vector = ` + StorySearchVectorSQL + ` WHERE search_vector IS NULL`)
+	if result.Error != nil {
+		return fmt.Errorf("backfill search_vector thất bại: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("🔎 Backfilled search_vector for %d stories", result.RowsAffected)
+	}
+	return nil
+}
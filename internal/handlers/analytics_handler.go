@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"nekozanedex/internal/services"
+	"nekozanedex/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// defaultAnalyticsDays - Khoảng mặc định khi không truyền from/to
+const defaultAnalyticsDays = 30
+
+type AnalyticsHandler struct {
+	analyticsService services.AnalyticsService
+}
+
+func NewAnalyticsHandler(analyticsService services.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{analyticsService: analyticsService}
+}
+
+// GetViewAnalytics godoc
+// @Summary Lượt xem theo thời gian (Admin)
+// @Description Views, unique readers, logged-in vs anonymous theo bucket ngày/tuần/tháng
+// @Tags Admin - Analytics
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "YYYY-MM-DD (mặc định 30 ngày trước)"
+// @Param to query string false "YYYY-MM-DD (mặc định hôm nay)"
+// @Param bucket query string false "day, week, month" default(day)
+// @Param story_id query string false "Story ID (bỏ trống = toàn site)"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/admin/analytics/views [get]
+func (h *AnalyticsHandler) GetViewAnalytics(c *gin.Context) {
+	query, ok := parseAnalyticsQuery(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.analyticsService.GetViewAnalytics(query)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Oke(c, result)
+}
+
+// GetTopChapters godoc
+// @Summary Chapters nhiều lượt xem nhất trong khoảng (Admin)
+// @Tags Admin - Analytics
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "YYYY-MM-DD (mặc định 30 ngày trước)"
+// @Param to query string false "YYYY-MM-DD (mặc định hôm nay)"
+// @Param story_id query string false "Story ID (bỏ trống = toàn site)"
+// @Param limit query int false "Number of chapters" default(20)
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/admin/analytics/chapters/top [get]
+func (h *AnalyticsHandler) GetTopChapters(c *gin.Context) {
+	query, ok := parseAnalyticsQuery(c)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	chapters, err := h.analyticsService.GetTopChapters(query, limit)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Oke(c, chapters)
+}
+
+// GetCommentAnalytics godoc
+// @Summary Comment mới theo thời gian (Admin)
+// @Tags Admin - Analytics
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "YYYY-MM-DD (mặc định 30 ngày trước)"
+// @Param to query string false "YYYY-MM-DD (mặc định hôm nay)"
+// @Param bucket query string false "day, week, month" default(day)
+// @Param story_id query string false "Story ID (bỏ trống = toàn site)"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/admin/analytics/comments [get]
+func (h *AnalyticsHandler) GetCommentAnalytics(c *gin.Context) {
+	query, ok := parseAnalyticsQuery(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.analyticsService.GetCommentAnalytics(query)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Oke(c, result)
+}
+
+// GetBookmarkAnalytics godoc
+// @Summary Bookmark mới theo thời gian (Admin)
+// @Tags Admin - Analytics
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "YYYY-MM-DD (mặc định 30 ngày trước)"
+// @Param to query string false "YYYY-MM-DD (mặc định hôm nay)"
+// @Param bucket query string false "day, week, month" default(day)
+// @Param story_id query string false "Story ID (bỏ trống = toàn site)"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/admin/analytics/bookmarks [get]
+func (h *AnalyticsHandler) GetBookmarkAnalytics(c *gin.Context) {
+	query, ok := parseAnalyticsQuery(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.analyticsService.GetBookmarkAnalytics(query)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Oke(c, result)
+}
+
+// parseAnalyticsQuery - Đọc from/to/bucket/story_id, tự trả 400 nếu sai định dạng
+func parseAnalyticsQuery(c *gin.Context) (services.AnalyticsQuery, bool) {
+	query := services.AnalyticsQuery{
+		To:     time.Now(),
+		Bucket: c.DefaultQuery("bucket", services.BucketDay),
+	}
+
+	if to := c.Query("to"); to != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", to, time.Local)
+		if err != nil {
+			response.BadRequest(c, "Ngày kết thúc không hợp lệ (YYYY-MM-DD)")
+			return query, false
+		}
+		query.To = parsed
+	}
+	query.From = query.To.AddDate(0, 0, -(defaultAnalyticsDays - 1))
+	if from := c.Query("from"); from != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", from, time.Local)
+		if err != nil {
+			response.BadRequest(c, "Ngày bắt đầu không hợp lệ (YYYY-MM-DD)")
+			return query, false
+		}
+		query.From = parsed
+	}
+
+	if storyID := c.Query("story_id"); storyID != "" {
+		id, err := uuid.Parse(storyID)
+		if err != nil {
+			response.BadRequest(c, "Story ID không hợp lệ")
+			return query, false
+		}
+		query.StoryID = &id
+	}
+
+	return query, true
+}
@@ -1,28 +1,24 @@
 package handlers
 
 import (
-	"fmt"
 	"nekozanedex/internal/config"
 	"nekozanedex/internal/middleware"
 	"nekozanedex/internal/services"
 	"nekozanedex/pkg/response"
-	"regexp"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 type AuthHandler struct {
-	authService   services.AuthService
-	uploadService services.UploadService
-	cfg           *config.Config
+	authService services.AuthService
+	cfg         *config.Config
 }
 
-func NewAuthHandler(authService services.AuthService, uploadService services.UploadService, cfg *config.Config) *AuthHandler {
+func NewAuthHandler(authService services.AuthService, cfg *config.Config) *AuthHandler {
 	return &AuthHandler{
-		authService:   authService,
-		uploadService: uploadService,
-		cfg:           cfg,
+		authService: authService,
+		cfg:         cfg,
 	}
 }
 
@@ -46,10 +42,10 @@ type ChangePasswordRequest struct {
 }
 
 // UpdateProfileRequest - Request body cho cập nhật profile
+// Avatar cũ không xóa ở đây nữa, job media GC dọn khi không còn user nào dùng
 type UpdateProfileRequest struct {
-	Username     *string `json:"username" binding:"omitempty,min=3,max=50"`
-	AvatarURL    *string `json:"avatar_url" binding:"omitempty,url"`
-	OldAvatarURL *string `json:"old_avatar_url" binding:"omitempty"`
+	Username  *string `json:"username" binding:"omitempty,min=3,max=50"`
+	AvatarURL *string `json:"avatar_url" binding:"omitempty,url"`
 }
 
 // RefreshRequest - Request body cho refresh token
@@ -212,13 +208,6 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 		return
 	}
 
-	// Debug: Log received request
-	fmt.Printf("[UpdateProfile] Received request - Username: %v, AvatarURL: %v, OldAvatarURL: %v\n",
-		req.Username != nil, req.AvatarURL != nil, req.OldAvatarURL != nil)
-	if req.OldAvatarURL != nil {
-		fmt.Printf("[UpdateProfile] Old avatar URL value: %s\n", *req.OldAvatarURL)
-	}
-
 	// At least one field must be provided
 	if req.Username == nil && req.AvatarURL == nil {
 		response.BadRequest(c, "Không có thông tin để cập nhật")
@@ -231,19 +220,6 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 		return
 	}
 
-	// Delete old avatar from Cloudinary if new avatar was provided
-	if req.AvatarURL != nil && req.OldAvatarURL != nil && *req.OldAvatarURL != "" {
-		publicID := extractCloudinaryPublicID(*req.OldAvatarURL)
-		if publicID != "" {
-			if err := h.uploadService.DeleteImage(publicID); err != nil {
-				// Log error but don't fail the request
-				fmt.Printf("[UpdateProfile] Failed to delete old avatar: %v\n", err)
-			} else {
-				fmt.Printf("[UpdateProfile] Deleted old avatar: %s\n", publicID)
-			}
-		}
-	}
-
 	response.Oke(c, gin.H{
 		"id":         user.ID,
 		"email":      user.Email,
@@ -425,28 +401,3 @@ func (h *AuthHandler) getCSRFConfig() middleware.CSRFConfig {
 	return cfg
 }
 
-// extractCloudinaryPublicID extracts the public ID from a Cloudinary URL
-// Example: https://res.cloudinary.com/xxx/image/upload/v1234/avatars/abc123.webp
-// Returns: avatars/abc123
-func extractCloudinaryPublicID(url string) string {
-	if url == "" {
-		return ""
-	}
-
-	// Match pattern: /upload/v<version>/<folder>/<filename>.<ext>
-	re := regexp.MustCompile(`/upload/v\d+/(.+)\.\w+$`)
-	matches := re.FindStringSubmatch(url)
-	if len(matches) > 1 {
-		return matches[1]
-	}
-
-	// Alternative pattern without version: /upload/<folder>/<filename>.<ext>
-	re2 := regexp.MustCompile(`/upload/(.+)\.\w+$`)
-	matches2 := re2.FindStringSubmatch(url)
-	if len(matches2) > 1 {
-		return matches2[1]
-	}
-
-	return ""
-}
-
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"io"
 	"strconv"
 	"time"
 
@@ -27,8 +28,18 @@ type CreateChapterRequest struct {
 	Ordering     *float64 `json:"ordering"`      
 	Content      string   `json:"content"`       
 	Images       []string `json:"images"`        
+	VolumeID     *uuid.UUID `json:"volume_id"`    // Chỉ dùng khi tạo, chuyển tập qua /volumes/move
+	InsertAfter  *int       `json:"insert_after"` // Chỉ dùng khi tạo: chèn sau chapter số này (0 = lên đầu), chapter mới vẫn nhận số lớn nhất + 1
+	IsPublished  bool       `json:"is_published"` // Chỉ dùng khi tạo: xuất bản ngay và báo cho người bookmark
 }
 
+type ReorderChaptersRequest struct {
+	ChapterIDs []uuid.UUID `json:"chapter_ids" binding:"required,min=1"` // Toàn bộ chapters theo thứ tự mới
+}
+
+// maxImportFileSize - Dung lượng tối đa file import chapters (EPUB/DOCX/ZIP/text)
+const maxImportFileSize = 50 * 1024 * 1024
+
 type ScheduleChapterRequest struct {
 	ScheduledAt string `json:"scheduled_at" binding:"required"` 
 }
@@ -44,7 +55,7 @@ type BulkImportRequest struct {
 // ============ PUBLIC ENDPOINTS ============
 
 // GetChapterByNumber godoc
-// @Summary Lấy chapter theo số
+// @Summary Lấy chapter theo số (kèm chapter trước/sau, thông tin truyện, vị trí đọc)
 // @Tags Chapters
 // @Produce json
 // @Param slug path string true "Story Slug"
@@ -59,12 +70,25 @@ func (h *ChapterHandler) GetChapterByNumber(c *gin.Context) {
 		return
 	}
 
-	chapter, err := h.chapterService.GetChapterByNumber(storySlug, chapterNumber)
+	// Optional auth: có đăng nhập thì trả kèm vị trí đọc đã lưu và đếm view theo user
+	var userID *uuid.UUID
+	if uid, exists := c.Get("user_id"); exists {
+		if id, ok := uid.(uuid.UUID); ok {
+			userID = &id
+		}
+	}
+
+	chapter, err := h.chapterService.GetChapterByNumber(storySlug, chapterNumber, userID)
 	if err != nil {
 		response.NotFound(c, err.Error())
 		return
 	}
 
+	// Record view (1 per user/IP per 24h, bỏ qua bot)
+	_ = h.chapterService.RecordChapterView(chapter.Chapter, userID, c.ClientIP(), c.Request.UserAgent())
+
+	// Chỉ dùng ETag (hash nội dung) + Cache-Control do middleware ConditionalGET gắn.
+	// Không set Last-Modified: response kèm thông tin truyện và chapter trước/sau, chapter.UpdatedAt không phản ánh các thay đổi đó
 	response.Oke(c, chapter)
 }
 
@@ -75,10 +99,25 @@ func (h *ChapterHandler) GetChapterByNumber(c *gin.Context) {
 // @Param slug path string true "Story Slug"
 // @Param page query int false "Page number (default: 1)"
 // @Param limit query int false "Items per page (default: 100, max: 100)"
+// @Param group query string false "volume = nhóm chapters theo tập (bỏ qua phân trang)"
 // @Success 200 {object} response.Response
 // @Router /api/stories/{slug}/chapters [get]
 func (h *ChapterHandler) GetChaptersByStory(c *gin.Context) {
 	storySlug := c.Param("slug")
+
+	if c.Query("group") == "volume" {
+		volumes, ungrouped, err := h.chapterService.GetChaptersGroupedByVolume(storySlug)
+		if err != nil {
+			response.NotFound(c, err.Error())
+			return
+		}
+
+		response.Oke(c, gin.H{
+			"volumes":  volumes,
+			"chapters": ungrouped,
+		})
+		return
+	}
 	
 	// Parse pagination params
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
@@ -127,6 +166,8 @@ func (h *ChapterHandler) GetChaptersByStoryAdmin(c *gin.Context) {
 
 // CreateChapter godoc
 // @Summary Tạo chapter mới (Admin)
+// @Description chapter_number là định danh trong URL (/chapters/{number}), không phải số hiển thị: mọi chapter mới (kể cả khi dùng insert_after) nhận số lớn nhất + 1, vị trí đọc do ordering quyết định.
+// @Description Vì vậy chapter chèn bằng insert_after (extra, 12.5...) nên gửi kèm chapter_label ("12.5", "Extra 1") để hiển thị; client hiển thị chapter_label nếu có, ngược lại mới dùng chapter_number. Chapter thường tạo sau đó vẫn nhận số tiếp theo (extra chiếm 1 số), muốn đánh số liền lại thì dùng /chapters/reorder.
 // @Tags Admin - Chapters
 // @Security BearerAuth
 // @Accept json
@@ -170,12 +211,19 @@ func (h *ChapterHandler) CreateChapter(c *gin.Context) {
 		Content:      req.Content,
 		Images:       imagesJSON,
 		PageCount:    len(req.Images),
+		VolumeID:     req.VolumeID,
+		IsPublished:  req.IsPublished,
 	}
 	if req.Ordering != nil {
 		chapter.Ordering = *req.Ordering
 	}
 
-	if err := h.chapterService.CreateChapter(storyID, chapter); err != nil {
+	if req.InsertAfter != nil {
+		err = h.chapterService.InsertChapter(storyID, chapter, *req.InsertAfter)
+	} else {
+		err = h.chapterService.CreateChapter(storyID, chapter)
+	}
+	if err != nil {
 		response.BadRequest(c, err.Error())
 		return
 	}
@@ -183,6 +231,37 @@ func (h *ChapterHandler) CreateChapter(c *gin.Context) {
 	response.Created(c, chapter)
 }
 
+// ReorderChapters godoc
+// @Summary Sắp xếp lại và đánh số lại chapters (Admin)
+// @Tags Admin - Chapters
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Story ID"
+// @Param body body ReorderChaptersRequest true "Danh sách ID theo thứ tự mới"
+// @Success 200 {object} response.Response
+// @Router /api/admin/stories/{id}/chapters/reorder [put]
+func (h *ChapterHandler) ReorderChapters(c *gin.Context) {
+	storyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Story ID không hợp lệ")
+		return
+	}
+
+	var req ReorderChaptersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Dữ liệu không hợp lệ")
+		return
+	}
+
+	if err := h.chapterService.ReorderChapters(storyID, req.ChapterIDs); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Oke(c, gin.H{"message": "Sắp xếp lại thành công"})
+}
+
 // UpdateChapter godoc
 // @Summary Cập nhật chapter (Admin)
 // @Tags Admin - Chapters
@@ -228,7 +307,13 @@ func (h *ChapterHandler) UpdateChapter(c *gin.Context) {
 		chapter.Ordering = *req.Ordering
 	}
 
-	if err := h.chapterService.UpdateChapter(id, chapter); err != nil {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "Chưa đăng nhập")
+		return
+	}
+
+	if err := h.chapterService.UpdateChapter(id, chapter, userID.(uuid.UUID)); err != nil {
 		response.BadRequest(c, err.Error())
 		return
 	}
@@ -324,12 +409,17 @@ func (h *ChapterHandler) ScheduleChapter(c *gin.Context) {
 
 // BulkImportChapters godoc
 // @Summary Import nhiều chapters cùng lúc (Admin)
+// @Description JSON: mảng chapters đã tách sẵn.
+// @Description Multipart: file EPUB/DOCX/ZIP(.txt/.md)/.txt/.md, tự tách chapter. Mặc định dry_run=true chỉ trả về bản xem trước.
 // @Tags Admin - Chapters
 // @Security BearerAuth
-// @Accept json
+// @Accept json,mpfd
 // @Produce json
 // @Param id path string true "Story ID"
-// @Param body body BulkImportRequest true "Chapters Info"
+// @Param body body BulkImportRequest false "Chapters Info (JSON)"
+// @Param file formData file false "File cần import (multipart)"
+// @Param pattern formData string false "Regex nhận dạng tiêu đề, group 'number' và 'title' (multipart)"
+// @Param dry_run formData bool false "Chỉ xem trước, không lưu (multipart, mặc định: true)"
 // @Success 201 {object} response.Response
 // @Router /api/admin/stories/{id}/chapters/bulk [post]
 func (h *ChapterHandler) BulkImportChapters(c *gin.Context) {
@@ -340,6 +430,11 @@ func (h *ChapterHandler) BulkImportChapters(c *gin.Context) {
 		return
 	}
 
+	if c.ContentType() == "multipart/form-data" {
+		h.importChaptersFromFile(c, storyID)
+		return
+	}
+
 	var req BulkImportRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		response.BadRequest(c, "Dữ liệu không hợp lệ")
@@ -371,6 +466,55 @@ func (h *ChapterHandler) BulkImportChapters(c *gin.Context) {
 	})
 }
 
+// importChaptersFromFile - Nhánh multipart của BulkImportChapters
+func (h *ChapterHandler) importChaptersFromFile(c *gin.Context, storyID uuid.UUID) {
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		response.BadRequest(c, "Vui lòng chọn file")
+		return
+	}
+	defer file.Close()
+
+	if header.Size > maxImportFileSize {
+		response.BadRequest(c, "File không được vượt quá 50MB")
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, maxImportFileSize+1))
+	if err != nil {
+		response.BadRequest(c, "Không thể đọc file")
+		return
+	}
+
+	pattern := c.PostForm("pattern")
+	dryRun := c.DefaultPostForm("dry_run", "true") != "false"
+
+	if dryRun {
+		preview, err := h.chapterService.PreviewImportFile(storyID, header.Filename, data, pattern)
+		if err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		response.Oke(c, gin.H{
+			"dry_run": true,
+			"preview": preview,
+		})
+		return
+	}
+
+	preview, err := h.chapterService.ImportFile(storyID, header.Filename, data, pattern)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Created(c, gin.H{
+		"message": "Import thành công",
+		"count":   preview.Total,
+		"preview": preview,
+	})
+}
+
 // GetChapterByID godoc
 // @Summary Lấy chapter theo ID (Admin)
 // @Tags Admin - Chapters
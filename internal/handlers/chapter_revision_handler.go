@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"strconv"
+
+	"nekozanedex/internal/services"
+	"nekozanedex/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ChapterRevisionHandler struct {
+	revisionService services.ChapterRevisionService
+}
+
+func NewChapterRevisionHandler(revisionService services.ChapterRevisionService) *ChapterRevisionHandler {
+	return &ChapterRevisionHandler{revisionService: revisionService}
+}
+
+// GetRevisions godoc
+// @Summary Lịch sử chỉnh sửa chapter (Admin)
+// @Tags Admin - Chapters
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Chapter ID"
+// @Success 200 {object} response.Response
+// @Router /api/admin/chapters/{id}/revisions [get]
+func (h *ChapterRevisionHandler) GetRevisions(c *gin.Context) {
+	chapterID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "ID không hợp lệ")
+		return
+	}
+
+	revisions, err := h.revisionService.GetRevisions(chapterID)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Oke(c, revisions)
+}
+
+// GetRevision godoc
+// @Summary Xem nội dung 1 revision (Admin)
+// @Tags Admin - Chapters
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Chapter ID"
+// @Param number path int true "Revision Number"
+// @Success 200 {object} response.Response
+// @Router /api/admin/chapters/{id}/revisions/{number} [get]
+func (h *ChapterRevisionHandler) GetRevision(c *gin.Context) {
+	chapterID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "ID không hợp lệ")
+		return
+	}
+	number, err := strconv.Atoi(c.Param("number"))
+	if err != nil {
+		response.BadRequest(c, "Số revision không hợp lệ")
+		return
+	}
+
+	revision, err := h.revisionService.GetRevision(chapterID, number)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Oke(c, revision)
+}
+
+// DiffRevisions godoc
+// @Summary So sánh 2 revision theo từng dòng (Admin)
+// @Tags Admin - Chapters
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Chapter ID"
+// @Param from query int true "Revision cũ"
+// @Param to query int true "Revision mới"
+// @Success 200 {object} response.Response
+// @Router /api/admin/chapters/{id}/revisions/diff [get]
+func (h *ChapterRevisionHandler) DiffRevisions(c *gin.Context) {
+	chapterID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "ID không hợp lệ")
+		return
+	}
+	from, err := strconv.Atoi(c.Query("from"))
+	if err != nil {
+		response.BadRequest(c, "Revision 'from' không hợp lệ")
+		return
+	}
+	to, err := strconv.Atoi(c.Query("to"))
+	if err != nil {
+		response.BadRequest(c, "Revision 'to' không hợp lệ")
+		return
+	}
+
+	diff, err := h.revisionService.DiffRevisions(chapterID, from, to)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Oke(c, diff)
+}
+
+// RestoreRevision godoc
+// @Summary Khôi phục revision cũ thành revision mới (Admin)
+// @Tags Admin - Chapters
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Chapter ID"
+// @Param number path int true "Revision Number"
+// @Success 200 {object} response.Response
+// @Router /api/admin/chapters/{id}/revisions/{number}/restore [post]
+func (h *ChapterRevisionHandler) RestoreRevision(c *gin.Context) {
+	chapterID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "ID không hợp lệ")
+		return
+	}
+	number, err := strconv.Atoi(c.Param("number"))
+	if err != nil {
+		response.BadRequest(c, "Số revision không hợp lệ")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "Chưa đăng nhập")
+		return
+	}
+
+	revision, err := h.revisionService.RestoreRevision(chapterID, number, userID.(uuid.UUID))
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Oke(c, revision)
+}
@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"nekozanedex/internal/services"
+	"nekozanedex/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ChatHandler struct {
+	chatService services.ChatService
+}
+
+func NewChatHandler(chatService services.ChatService) *ChatHandler {
+	return &ChatHandler{chatService: chatService}
+}
+
+type SendChatMessageRequest struct {
+	Content string `json:"content" binding:"required,max=500"`
+}
+
+type SetSlowModeRequest struct {
+	Seconds int `json:"seconds" binding:"min=0,max=3600"`
+}
+
+type MuteUserRequest struct {
+	UserID          string  `json:"user_id" binding:"required,uuid"`
+	RoomID          *string `json:"room_id"`                          // Bỏ trống = tất cả phòng
+	DurationMinutes int     `json:"duration_minutes" binding:"min=0"` // 0 = vĩnh viễn
+	Reason          *string `json:"reason" binding:"omitempty,max=255"`
+}
+
+// GetMessages godoc
+// @Summary Lấy lịch sử chat của phòng
+// @Tags Chat
+// @Produce json
+// @Param roomId path string true "Room ID (general hoặc story-{storyId})"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(50)
+// @Success 200 {object} response.Pagination
+// @Router /api/chat/rooms/{roomId}/messages [get]
+func (h *ChatHandler) GetMessages(c *gin.Context) {
+	roomID := c.Param("roomId")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 50
+	}
+
+	messages, total, err := h.chatService.GetMessages(roomID, page, limit)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.PaginatedResponse(c, messages, page, limit, total)
+}
+
+// SendMessage godoc
+// @Summary Gửi tin nhắn vào phòng chat
+// @Tags Chat
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param roomId path string true "Room ID (general hoặc story-{storyId})"
+// @Param body body SendChatMessageRequest true "Message"
+// @Success 201 {object} response.Response
+// @Router /api/chat/rooms/{roomId}/messages [post]
+func (h *ChatHandler) SendMessage(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "Chưa đăng nhập")
+		return
+	}
+
+	var req SendChatMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Dữ liệu không hợp lệ")
+		return
+	}
+
+	role, _ := c.Get("role")
+	isAdmin := role == "admin"
+
+	message, err := h.chatService.SendMessage(userID.(uuid.UUID), c.Param("roomId"), req.Content, isAdmin)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Created(c, message)
+}
+
+// GetRoomSetting godoc
+// @Summary Lấy cấu hình phòng chat (slow-mode)
+// @Tags Chat
+// @Produce json
+// @Param roomId path string true "Room ID"
+// @Success 200 {object} response.Response
+// @Router /api/chat/rooms/{roomId}/settings [get]
+func (h *ChatHandler) GetRoomSetting(c *gin.Context) {
+	setting, err := h.chatService.GetRoomSetting(c.Param("roomId"))
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Oke(c, setting)
+}
+
+// ============ ADMIN ENDPOINTS ============
+
+// DeleteMessage godoc
+// @Summary Xóa tin nhắn (Admin)
+// @Tags Admin - Chat
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Message ID"
+// @Success 200 {object} response.Response
+// @Router /api/admin/chat/messages/{id} [delete]
+func (h *ChatHandler) DeleteMessage(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "ID không hợp lệ")
+		return
+	}
+
+	if err := h.chatService.DeleteMessage(id); err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Oke(c, gin.H{"message": "Đã xóa tin nhắn"})
+}
+
+// SetSlowMode godoc
+// @Summary Đặt slow-mode cho phòng chat (Admin)
+// @Tags Admin - Chat
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param roomId path string true "Room ID"
+// @Param body body SetSlowModeRequest true "Slow-mode seconds (0 = off)"
+// @Success 200 {object} response.Response
+// @Router /api/admin/chat/rooms/{roomId}/slow-mode [put]
+func (h *ChatHandler) SetSlowMode(c *gin.Context) {
+	var req SetSlowModeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Dữ liệu không hợp lệ")
+		return
+	}
+
+	setting, err := h.chatService.SetSlowMode(c.Param("roomId"), req.Seconds)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Oke(c, setting)
+}
+
+// GetMutes godoc
+// @Summary Danh sách user đang bị cấm chat (Admin)
+// @Tags Admin - Chat
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} response.Pagination
+// @Router /api/admin/chat/mutes [get]
+func (h *ChatHandler) GetMutes(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	mutes, total, err := h.chatService.GetActiveMutes(page, limit)
+	if err != nil {
+		response.InternalServerError(c, "Không thể lấy danh sách cấm chat")
+		return
+	}
+
+	response.PaginatedResponse(c, mutes, page, limit, total)
+}
+
+// MuteUser godoc
+// @Summary Cấm chat user (Admin)
+// @Tags Admin - Chat
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body MuteUserRequest true "Mute info"
+// @Success 201 {object} response.Response
+// @Router /api/admin/chat/mutes [post]
+func (h *ChatHandler) MuteUser(c *gin.Context) {
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "Chưa đăng nhập")
+		return
+	}
+
+	var req MuteUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Dữ liệu không hợp lệ")
+		return
+	}
+
+	userID, _ := uuid.Parse(req.UserID)
+	if req.RoomID != nil && *req.RoomID == "" {
+		req.RoomID = nil
+	}
+	duration := time.Duration(req.DurationMinutes) * time.Minute
+
+	mute, err := h.chatService.MuteUser(adminID.(uuid.UUID), userID, req.RoomID, duration, req.Reason)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Created(c, mute)
+}
+
+// UnmuteUser godoc
+// @Summary Gỡ cấm chat (Admin)
+// @Tags Admin - Chat
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Mute ID"
+// @Success 200 {object} response.Response
+// @Router /api/admin/chat/mutes/{id} [delete]
+func (h *ChatHandler) UnmuteUser(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "ID không hợp lệ")
+		return
+	}
+
+	if err := h.chatService.UnmuteUser(id); err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Oke(c, gin.H{"message": "Đã gỡ cấm chat"})
+}
@@ -93,6 +93,7 @@ func parseTagNames(content string) []string {
 // @Param storyId path string true "Story ID"
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(20)
+// @Param cursor query string false "Cursor từ meta.next_cursor/prev_cursor (keyset, để trống = trang đầu)"
 // @Success 200 {object} response.Pagination
 // @Router /api/stories/{storyId}/comments [get]
 func (h *CommentHandler) GetCommentsByStory(c *gin.Context) {
@@ -106,6 +107,23 @@ func (h *CommentHandler) GetCommentsByStory(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 
+	if cursor, ok, err := parseCursor(c); ok {
+		if err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		if limit < 1 || limit > 100 {
+			limit = 20
+		}
+		comments, cursors, err := h.commentService.GetCommentsByStoryCursor(storyID, cursor, limit)
+		if err != nil {
+			response.InternalServerError(c, "Không thể lấy comments")
+			return
+		}
+		response.CursorPaginatedResponse(c, h.enrichCommentsWithLikeStatus(c, comments), limit, cursors.Next, cursors.Prev)
+		return
+	}
+
 	comments, total, err := h.commentService.GetCommentsByStory(storyID, page, limit)
 	if err != nil {
 		response.InternalServerError(c, "Không thể lấy comments")
@@ -123,6 +141,7 @@ func (h *CommentHandler) GetCommentsByStory(c *gin.Context) {
 // @Param chapterId path string true "Chapter ID"
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(20)
+// @Param cursor query string false "Cursor từ meta.next_cursor/prev_cursor (keyset, để trống = trang đầu)"
 // @Success 200 {object} response.Pagination
 // @Router /api/chapters/{chapterId}/comments [get]
 func (h *CommentHandler) GetCommentsByChapter(c *gin.Context) {
@@ -136,6 +155,23 @@ func (h *CommentHandler) GetCommentsByChapter(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 
+	if cursor, ok, err := parseCursor(c); ok {
+		if err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		if limit < 1 || limit > 100 {
+			limit = 20
+		}
+		comments, cursors, err := h.commentService.GetCommentsByChapterCursor(chapterID, cursor, limit)
+		if err != nil {
+			response.InternalServerError(c, "Không thể lấy comments")
+			return
+		}
+		response.CursorPaginatedResponse(c, h.enrichCommentsWithLikeStatus(c, comments), limit, cursors.Next, cursors.Prev)
+		return
+	}
+
 	comments, total, err := h.commentService.GetCommentsByChapter(chapterID, page, limit)
 	if err != nil {
 		response.InternalServerError(c, "Không thể lấy comments")
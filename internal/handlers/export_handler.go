@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"log"
+	"strconv"
+
+	"nekozanedex/internal/services"
+	"nekozanedex/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExportHandler struct {
+	exportService services.ExportService
+}
+
+func NewExportHandler(exportService services.ExportService) *ExportHandler {
+	return &ExportHandler{exportService: exportService}
+}
+
+// ExportEpub godoc
+// @Summary Tải truyện dạng EPUB để đọc offline
+// @Tags Stories
+// @Produce application/epub+zip
+// @Param slug path string true "Story Slug"
+// @Param from query int false "Từ chapter số (mặc định: đầu tiên)"
+// @Param to query int false "Đến chapter số (mặc định: cuối cùng)"
+// @Success 200 {file} file
+// @Router /api/stories/{slug}/export.epub [get]
+func (h *ExportHandler) ExportEpub(c *gin.Context) {
+	from, err := strconv.Atoi(c.DefaultQuery("from", "0"))
+	if err != nil {
+		response.BadRequest(c, "Chapter bắt đầu không hợp lệ")
+		return
+	}
+	to, err := strconv.Atoi(c.DefaultQuery("to", "0"))
+	if err != nil {
+		response.BadRequest(c, "Chapter kết thúc không hợp lệ")
+		return
+	}
+
+	export, err := h.exportService.PrepareStoryEpub(c.Param("slug"), from, to)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	c.Header("Content-Type", "application/epub+zip")
+	c.Header("Content-Disposition", `attachment; filename="`+export.FileName+`"`)
+	c.Status(200)
+
+	// Đã bắt đầu stream nên không thể trả JSON lỗi nữa
+	if err := h.exportService.WriteEpub(export, c.Writer); err != nil {
+		log.Printf("❌ EPUB export failed for %s: %v", export.Story.Slug, err)
+		c.Abort()
+	}
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"nekozanedex/internal/services"
+	"nekozanedex/pkg/httpcache"
+	"nekozanedex/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FeedHandler struct {
+	feedService services.FeedService
+}
+
+func NewFeedHandler(feedService services.FeedService) *FeedHandler {
+	return &FeedHandler{feedService: feedService}
+}
+
+// LatestFeed godoc
+// @Summary Feed chương mới toàn site (RSS: .xml, Atom: .atom)
+// @Tags Feeds
+// @Produce xml
+// @Success 200 {string} string "RSS/Atom XML"
+// @Success 304 "Not Modified"
+// @Router /feeds/latest.xml [get]
+func (h *FeedHandler) LatestFeed(c *gin.Context) {
+	format := services.FeedFormatRSS
+	if strings.HasSuffix(c.Request.URL.Path, ".atom") {
+		format = services.FeedFormatAtom
+	}
+	h.serveFeed(c, services.FeedLatest, "", format)
+}
+
+// GenreFeed godoc
+// @Summary Feed chương mới theo thể loại (RSS: .xml, Atom: .atom)
+// @Tags Feeds
+// @Produce xml
+// @Param file path string true "Genre slug + .xml hoặc .atom"
+// @Success 200 {string} string "RSS/Atom XML"
+// @Success 304 "Not Modified"
+// @Router /feeds/genres/{file} [get]
+func (h *FeedHandler) GenreFeed(c *gin.Context) {
+	slug, format, ok := parseFeedFile(c.Param("file"))
+	if !ok {
+		response.NotFound(c, "Feed không tồn tại")
+		return
+	}
+	h.serveFeed(c, services.FeedGenre, slug, format)
+}
+
+// StoryFeed godoc
+// @Summary Feed chương mới của 1 truyện (RSS: .xml, Atom: .atom)
+// @Tags Feeds
+// @Produce xml
+// @Param file path string true "Story slug + .xml hoặc .atom"
+// @Success 200 {string} string "RSS/Atom XML"
+// @Success 304 "Not Modified"
+// @Router /feeds/stories/{file} [get]
+func (h *FeedHandler) StoryFeed(c *gin.Context) {
+	slug, format, ok := parseFeedFile(c.Param("file"))
+	if !ok {
+		response.NotFound(c, "Feed không tồn tại")
+		return
+	}
+	h.serveFeed(c, services.FeedStory, slug, format)
+}
+
+func (h *FeedHandler) serveFeed(c *gin.Context, kind, slug, format string) {
+	doc, err := h.feedService.GetFeed(kind, slug, format)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	writeConditional(c, doc.ETag, doc.LastModified, 300, doc.ContentType, doc.Body)
+}
+
+// writeConditional - Gửi ETag/Last-Modified/Cache-Control, trả 304 nếu client đã có bản mới nhất
+func writeConditional(c *gin.Context, etag string, lastModified time.Time, maxAge int, contentType string, body []byte) {
+	c.Header("ETag", etag)
+	httpcache.SetLastModified(c.Writer.Header(), lastModified)
+	c.Header("Cache-Control", "public, max-age="+strconv.Itoa(maxAge))
+
+	if httpcache.NotModified(c.Request, etag, lastModified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// parseFeedFile - "one-piece.xml" -> ("one-piece", rss), "one-piece.atom" -> ("one-piece", atom)
+func parseFeedFile(file string) (string, string, bool) {
+	switch {
+	case strings.HasSuffix(file, ".xml"):
+		return strings.TrimSuffix(file, ".xml"), services.FeedFormatRSS, true
+	case strings.HasSuffix(file, ".atom"):
+		return strings.TrimSuffix(file, ".atom"), services.FeedFormatAtom, true
+	default:
+		return "", "", false
+	}
+}
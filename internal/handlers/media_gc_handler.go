@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"nekozanedex/internal/services"
+	"nekozanedex/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MediaGCHandler struct {
+	mediaGCService services.MediaGCService
+}
+
+func NewMediaGCHandler(mediaGCService services.MediaGCService) *MediaGCHandler {
+	return &MediaGCHandler{mediaGCService: mediaGCService}
+}
+
+// GetReport godoc
+// @Summary Báo cáo dry-run của media GC (Admin)
+// @Description Liệt kê ảnh upload không còn được cover truyện/tập, ảnh chapter hay avatar tham chiếu. action = delete là sẽ bị xóa ở lần sweep tới, pending là còn trong grace period. Các bộ đếm tính trên toàn bộ asset mồ côi, items chỉ liệt kê tối đa 500 (truncated = true nếu còn nữa). Không xóa gì.
+// @Tags Media
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.Response
+// @Router /api/admin/media/gc/report [get]
+func (h *MediaGCHandler) GetReport(c *gin.Context) {
+	report, err := h.mediaGCService.Sweep(true)
+	if err != nil {
+		response.InternalServerError(c, err.Error())
+		return
+	}
+
+	response.Oke(c, report)
+}
@@ -25,6 +25,7 @@ func NewNotificationHandler(notificationService services.NotificationService) *N
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(20)
+// @Param cursor query string false "Cursor từ meta.next_cursor/prev_cursor (keyset, để trống = trang đầu)"
 // @Success 200 {object} response.Pagination
 // @Router /api/notifications [get]
 func (h *NotificationHandler) GetMyNotifications(c *gin.Context) {
@@ -37,6 +38,23 @@ func (h *NotificationHandler) GetMyNotifications(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 
+	if cursor, ok, err := parseCursor(c); ok {
+		if err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		if limit < 1 || limit > 100 {
+			limit = 20
+		}
+		notifications, cursors, err := h.notificationService.GetUserNotificationsCursor(userID.(uuid.UUID), cursor, limit)
+		if err != nil {
+			response.InternalServerError(c, "Không thể lấy thông báo")
+			return
+		}
+		response.CursorPaginatedResponse(c, notifications, limit, cursors.Next, cursors.Prev)
+		return
+	}
+
 	notifications, total, err := h.notificationService.GetUserNotifications(userID.(uuid.UUID), page, limit)
 	if err != nil {
 		response.InternalServerError(c, "Không thể lấy thông báo")
@@ -0,0 +1,17 @@
+package handlers
+
+import (
+	"nekozanedex/pkg/pagination"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseCursor - Có tham số ?cursor= (kể cả rỗng = trang đầu) thì dùng keyset pagination thay cho page
+func parseCursor(c *gin.Context) (*pagination.Cursor, bool, error) {
+	raw, ok := c.GetQuery("cursor")
+	if !ok {
+		return nil, false, nil
+	}
+	cursor, err := pagination.Decode(raw)
+	return cursor, true, err
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"strconv"
+
+	"nekozanedex/internal/services"
+	"nekozanedex/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RankingHandler struct {
+	rankingService services.RankingService
+}
+
+func NewRankingHandler(rankingService services.RankingService) *RankingHandler {
+	return &RankingHandler{rankingService: rankingService}
+}
+
+// GetRanking godoc
+// @Summary Bảng xếp hạng trending theo ngày/tuần/tháng
+// @Description Tính từ lượt xem theo ngày có suy giảm theo thời gian, làm mới định kỳ. rank_change > 0 là tăng hạng so với kỳ trước, null là mới lọt bảng
+// @Tags Stories
+// @Produce json
+// @Param period query string false "day, week, month" default(week)
+// @Param limit query int false "Number of stories" default(20)
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/stories/ranking [get]
+func (h *RankingHandler) GetRanking(c *gin.Context) {
+	period := c.DefaultQuery("period", services.RankingWeek)
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	entries, err := h.rankingService.GetRanking(period, limit)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Oke(c, entries)
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"nekozanedex/internal/services"
+	"nekozanedex/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type RatingHandler struct {
+	ratingService services.StoryRatingService
+}
+
+func NewRatingHandler(ratingService services.StoryRatingService) *RatingHandler {
+	return &RatingHandler{ratingService: ratingService}
+}
+
+type RateStoryRequest struct {
+	Score int `json:"score" binding:"required,min=1,max=5"`
+}
+
+// GetMyRating godoc
+// @Summary Lấy đánh giá của tôi cho truyện
+// @Tags Ratings
+// @Security BearerAuth
+// @Produce json
+// @Param storyId path string true "Story ID"
+// @Success 200 {object} response.Response
+// @Router /api/ratings/{storyId} [get]
+func (h *RatingHandler) GetMyRating(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "Chưa đăng nhập")
+		return
+	}
+
+	storyID, err := uuid.Parse(c.Param("storyId"))
+	if err != nil {
+		response.BadRequest(c, "Story ID không hợp lệ")
+		return
+	}
+
+	rating, err := h.ratingService.GetMyRating(userID.(uuid.UUID), storyID)
+	if err != nil {
+		response.InternalServerError(c, "Không thể lấy đánh giá")
+		return
+	}
+
+	// Chưa đánh giá - trả về null
+	response.Oke(c, rating)
+}
+
+// RateStory godoc
+// @Summary Đánh giá hoặc đổi điểm đánh giá truyện
+// @Tags Ratings
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param storyId path string true "Story ID"
+// @Param body body RateStoryRequest true "Score (1-5)"
+// @Success 200 {object} response.Response
+// @Router /api/ratings/{storyId} [put]
+func (h *RatingHandler) RateStory(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "Chưa đăng nhập")
+		return
+	}
+
+	storyID, err := uuid.Parse(c.Param("storyId"))
+	if err != nil {
+		response.BadRequest(c, "Story ID không hợp lệ")
+		return
+	}
+
+	var req RateStoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Điểm đánh giá phải từ 1 đến 5")
+		return
+	}
+
+	story, err := h.ratingService.RateStory(userID.(uuid.UUID), storyID, req.Score)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Oke(c, gin.H{
+		"score":        req.Score,
+		"rating":       story.Rating,
+		"rating_count": story.RatingCount,
+	})
+}
+
+// RemoveRating godoc
+// @Summary Rút lại đánh giá truyện
+// @Tags Ratings
+// @Security BearerAuth
+// @Produce json
+// @Param storyId path string true "Story ID"
+// @Success 200 {object} response.Response
+// @Router /api/ratings/{storyId} [delete]
+func (h *RatingHandler) RemoveRating(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "Chưa đăng nhập")
+		return
+	}
+
+	storyID, err := uuid.Parse(c.Param("storyId"))
+	if err != nil {
+		response.BadRequest(c, "Story ID không hợp lệ")
+		return
+	}
+
+	story, err := h.ratingService.RemoveRating(userID.(uuid.UUID), storyID)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Oke(c, gin.H{
+		"message":      "Đã rút lại đánh giá",
+		"rating":       story.Rating,
+		"rating_count": story.RatingCount,
+	})
+}
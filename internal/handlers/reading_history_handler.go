@@ -75,6 +75,7 @@ func (h *ReadingHistoryHandler) SaveProgress(c *gin.Context) {
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(20)
+// @Param cursor query string false "Cursor từ meta.next_cursor/prev_cursor (keyset, để trống = trang đầu)"
 // @Success 200 {object} response.Response
 // @Router /api/reading-history [get]
 func (h *ReadingHistoryHandler) GetHistory(c *gin.Context) {
@@ -94,6 +95,20 @@ func (h *ReadingHistoryHandler) GetHistory(c *gin.Context) {
 		limit = 20
 	}
 
+	if cursor, ok, err := parseCursor(c); ok {
+		if err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		histories, cursors, err := h.historyRepo.GetByUserCursor(userID.(uuid.UUID), cursor, limit)
+		if err != nil {
+			response.InternalServerError(c, "Không thể lấy lịch sử đọc")
+			return
+		}
+		response.CursorPaginatedResponse(c, histories, limit, cursors.Next, cursors.Prev)
+		return
+	}
+
 	histories, total, err := h.historyRepo.GetByUser(userID.(uuid.UUID), page, limit)
 	if err != nil {
 		response.InternalServerError(c, "Không thể lấy lịch sử đọc")
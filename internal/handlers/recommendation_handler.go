@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"strconv"
+
+	"nekozanedex/internal/services"
+	"nekozanedex/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type RecommendationHandler struct {
+	recommendationService services.RecommendationService
+}
+
+func NewRecommendationHandler(recommendationService services.RecommendationService) *RecommendationHandler {
+	return &RecommendationHandler{recommendationService: recommendationService}
+}
+
+// GetSimilarStories godoc
+// @Summary Truyện tương tự ("người đọc truyện này cũng đọc")
+// @Tags Stories
+// @Produce json
+// @Param slug path string true "Story slug"
+// @Param limit query int false "Number of stories" default(10)
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/stories/{slug}/similar [get]
+func (h *RecommendationHandler) GetSimilarStories(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit < 1 || limit > 30 {
+		limit = 10
+	}
+
+	stories, err := h.recommendationService.GetSimilarStories(c.Param("slug"), limit)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Oke(c, stories)
+}
+
+// GetRecommendations godoc
+// @Summary Gợi ý truyện cho user (bỏ qua truyện đã đọc/bookmark)
+// @Tags Recommendations
+// @Security BearerAuth
+// @Produce json
+// @Param limit query int false "Number of stories" default(20)
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /api/recommendations [get]
+func (h *RecommendationHandler) GetRecommendations(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "Chưa đăng nhập")
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit < 1 || limit > 50 {
+		limit = 20
+	}
+
+	stories, err := h.recommendationService.GetRecommendations(userID.(uuid.UUID), limit)
+	if err != nil {
+		response.InternalServerError(c, "Không thể lấy gợi ý truyện")
+		return
+	}
+
+	response.Oke(c, stories)
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"nekozanedex/internal/services"
+	"nekozanedex/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+const sitemapContentType = "application/xml; charset=utf-8"
+
+type SitemapHandler struct {
+	sitemapService services.SitemapService
+}
+
+func NewSitemapHandler(sitemapService services.SitemapService) *SitemapHandler {
+	return &SitemapHandler{sitemapService: sitemapService}
+}
+
+// SitemapIndex godoc
+// @Summary Sitemap index (trỏ tới các sitemap stories/chapters/genres)
+// @Tags SEO
+// @Produce xml
+// @Success 200 {string} string "Sitemap index XML"
+// @Success 304 "Not Modified"
+// @Router /sitemap.xml [get]
+func (h *SitemapHandler) SitemapIndex(c *gin.Context) {
+	doc, err := h.sitemapService.GetIndex()
+	if err != nil {
+		response.InternalServerError(c, "Không thể tạo sitemap")
+		return
+	}
+	writeConditional(c, doc.ETag, doc.LastModified, 3600, sitemapContentType, doc.Body)
+}
+
+// Sitemap godoc
+// @Summary Sitemap con đã phân trang, ví dụ stories-1.xml, chapters-3.xml, genres-1.xml
+// @Tags SEO
+// @Produce xml
+// @Param file path string true "<kind>-<page>.xml"
+// @Success 200 {string} string "Sitemap XML"
+// @Success 304 "Not Modified"
+// @Failure 404 {object} response.Response
+// @Router /sitemaps/{file} [get]
+func (h *SitemapHandler) Sitemap(c *gin.Context) {
+	kind, page, ok := parseSitemapFile(c.Param("file"))
+	if !ok {
+		response.NotFound(c, "Sitemap không tồn tại")
+		return
+	}
+
+	doc, err := h.sitemapService.GetSitemap(kind, page)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+	writeConditional(c, doc.ETag, doc.LastModified, 3600, sitemapContentType, doc.Body)
+}
+
+// Robots godoc
+// @Summary robots.txt
+// @Tags SEO
+// @Produce plain
+// @Success 200 {string} string "robots.txt"
+// @Router /robots.txt [get]
+func (h *SitemapHandler) Robots(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.String(http.StatusOK, h.sitemapService.GetRobots())
+}
+
+// parseSitemapFile - "chapters-2.xml" -> ("chapters", 2)
+func parseSitemapFile(file string) (string, int, bool) {
+	name, ok := strings.CutSuffix(file, ".xml")
+	if !ok {
+		return "", 0, false
+	}
+	idx := strings.LastIndex(name, "-")
+	if idx <= 0 {
+		return "", 0, false
+	}
+	page, err := strconv.Atoi(name[idx+1:])
+	if err != nil {
+		return "", 0, false
+	}
+	return name[:idx], page, true
+}
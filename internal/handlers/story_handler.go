@@ -2,8 +2,10 @@ package handlers
 
 import (
 	"strconv"
+	"strings"
 
 	"nekozanedex/internal/models"
+	"nekozanedex/internal/repositories"
 	"nekozanedex/internal/services"
 	"nekozanedex/pkg/response"
 
@@ -46,6 +48,7 @@ type CreateStoryRequest struct {
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(20)
+// @Param cursor query string false "Cursor từ meta.next_cursor/prev_cursor (keyset, để trống = trang đầu)"
 // @Success 200 {object} response.Pagination
 // @Router /api/stories [get]
 func (h *StoryHandler) GetStories(c *gin.Context) {
@@ -59,6 +62,20 @@ func (h *StoryHandler) GetStories(c *gin.Context) {
 		limit = 20
 	}
 
+	if cursor, ok, err := parseCursor(c); ok {
+		if err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		stories, cursors, err := h.storyService.GetAllStoriesCursor(cursor, limit)
+		if err != nil {
+			response.InternalServerError(c, "Không thể lấy danh sách truyện")
+			return
+		}
+		response.CursorPaginatedResponse(c, stories, limit, cursors.Next, cursors.Prev)
+		return
+	}
+
 	stories, total, err := h.storyService.GetAllStories(page, limit)
 	if err != nil {
 		response.InternalServerError(c, "Không thể lấy danh sách truyện")
@@ -94,8 +111,9 @@ func (h *StoryHandler) GetStoryBySlug(c *gin.Context) {
 	}
 
 	// Record view (1 per user/IP per 24h)
-	_ = h.storyService.RecordStoryView(story.ID, userID, clientIP)
+	_ = h.storyService.RecordStoryView(story.ID, userID, clientIP, c.Request.UserAgent())
 
+	// Không set Last-Modified: response kèm chapters, thể loại và rating, story.UpdatedAt không phản ánh hết. ETag do ConditionalGET gắn
 	response.Oke(c, story)
 }
 
@@ -143,6 +161,28 @@ func (h *StoryHandler) GetHotStories(c *gin.Context) {
 	response.Oke(c, stories)
 }
 
+// GetTopRatedStories godoc
+// @Summary Lấy truyện được đánh giá cao
+// @Tags Stories
+// @Produce json
+// @Param limit query int false "Number of stories" default(10)
+// @Success 200 {object} response.Response
+// @Router /api/stories/top-rated [get]
+func (h *StoryHandler) GetTopRatedStories(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit < 1 || limit > 50 {
+		limit = 10
+	}
+
+	stories, err := h.storyService.GetTopRatedStories(limit)
+	if err != nil {
+		response.InternalServerError(c, "Không thể lấy truyện đánh giá cao")
+		return
+	}
+
+	response.Oke(c, stories)
+}
+
 // GetRandomStory godoc
 // @Summary Lấy truyện ngẫu nhiên
 // @Tags Stories
@@ -187,6 +227,95 @@ func (h *StoryHandler) SearchStories(c *gin.Context) {
 	response.PaginatedResponse(c, stories, page, limit, total)
 }
 
+// BrowseStories godoc
+// @Summary Duyệt truyện với bộ lọc nhiều điều kiện (kèm facet counts)
+// @Tags Stories
+// @Produce json
+// @Param genres query string false "Genre slugs phải có (comma-separated)"
+// @Param exclude_genres query string false "Genre slugs loại trừ (comma-separated)"
+// @Param status query string false "ongoing, completed, hiatus"
+// @Param country query string false "JP, CN, KR, VN"
+// @Param year_from query int false "Năm ra mắt từ"
+// @Param year_to query int false "Năm ra mắt đến"
+// @Param min_chapters query int false "Số chapter tối thiểu"
+// @Param min_rating query number false "Rating tối thiểu (0-5)"
+// @Param sort query string false "updated, views, rating, title, created" default(updated)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} response.Response
+// @Router /api/stories/browse [get]
+func (h *StoryHandler) BrowseStories(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	filter := repositories.StoryBrowseFilter{
+		IncludeGenres: splitQueryList(c.Query("genres")),
+		ExcludeGenres: splitQueryList(c.Query("exclude_genres")),
+		Status:        c.Query("status"),
+		Country:       c.Query("country"),
+		Sort:          c.DefaultQuery("sort", "updated"),
+	}
+	if v, err := strconv.Atoi(c.Query("year_from")); err == nil {
+		filter.YearFrom = &v
+	}
+	if v, err := strconv.Atoi(c.Query("year_to")); err == nil {
+		filter.YearTo = &v
+	}
+	if v, err := strconv.Atoi(c.Query("min_chapters")); err == nil {
+		filter.MinChapters = v
+	}
+	if v, err := strconv.ParseFloat(c.Query("min_rating"), 64); err == nil {
+		filter.MinRating = &v
+	}
+
+	if filter.YearFrom != nil && filter.YearTo != nil && *filter.YearFrom > *filter.YearTo {
+		response.BadRequest(c, "năm bắt đầu phải nhỏ hơn năm kết thúc")
+		return
+	}
+	if filter.MinRating != nil && (*filter.MinRating < 0 || *filter.MinRating > 5) {
+		response.BadRequest(c, "rating tối thiểu phải từ 0 đến 5")
+		return
+	}
+
+	stories, total, facets, err := h.storyService.BrowseStories(filter, page, limit)
+	if err != nil {
+		response.InternalServerError(c, "Không thể duyệt truyện")
+		return
+	}
+
+	response.Oke(c, gin.H{
+		"stories": stories,
+		"facets":  facets,
+		"meta":    response.NewMeta(page, limit, total),
+	})
+}
+
+// splitQueryList - "a, b,,c,a" => ["a", "b", "c"] (bỏ phần tử rỗng và trùng lặp)
+func splitQueryList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	parts := strings.Split(value, ",")
+	result := make([]string, 0, len(parts))
+	seen := make(map[string]bool, len(parts))
+	for _, part := range parts {
+		trimmed := strings.TrimSpace(part)
+		if trimmed == "" || seen[trimmed] {
+			continue
+		}
+		seen[trimmed] = true
+		result = append(result, trimmed)
+	}
+	return result
+}
+
 // GetStoriesByGenre godoc
 // @Summary Lấy truyện theo thể loại
 // @Tags Stories
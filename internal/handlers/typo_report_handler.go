@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"strconv"
+
+	"nekozanedex/internal/services"
+	"nekozanedex/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TypoReportHandler struct {
+	typoReportService services.TypoReportService
+}
+
+func NewTypoReportHandler(typoReportService services.TypoReportService) *TypoReportHandler {
+	return &TypoReportHandler{typoReportService: typoReportService}
+}
+
+type SubmitTypoReportRequest struct {
+	ChapterID     string  `json:"chapter_id" binding:"required,uuid"`
+	OriginalText  string  `json:"original_text" binding:"required,max=1000"`
+	SuggestedText *string `json:"suggested_text" binding:"omitempty,max=1000"`
+	PositionHint  *string `json:"position_hint" binding:"omitempty,max=50"`
+}
+
+type ResolveTypoReportRequest struct {
+	Status        string  `json:"status" binding:"required,oneof=fixed rejected"`
+	SuggestedText *string `json:"suggested_text" binding:"omitempty,max=1000"` // Admin có thể sửa lại đề xuất trước khi áp dụng
+}
+
+// SubmitTypoReport godoc
+// @Summary Báo lỗi chính tả trong chapter (ẩn danh hoặc đã đăng nhập)
+// @Tags Typo Reports
+// @Accept json
+// @Produce json
+// @Param body body SubmitTypoReportRequest true "Typo report"
+// @Success 201 {object} response.Response
+// @Router /api/typo-reports [post]
+func (h *TypoReportHandler) SubmitTypoReport(c *gin.Context) {
+	var req SubmitTypoReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Dữ liệu không hợp lệ")
+		return
+	}
+
+	chapterID, _ := uuid.Parse(req.ChapterID)
+
+	var userID *uuid.UUID
+	if uid, exists := c.Get("user_id"); exists {
+		if id, ok := uid.(uuid.UUID); ok {
+			userID = &id
+		}
+	}
+
+	report, err := h.typoReportService.SubmitReport(userID, chapterID, req.OriginalText, req.SuggestedText, req.PositionHint)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Created(c, report)
+}
+
+// GetTypoReports godoc
+// @Summary Lấy hàng đợi báo lỗi chính tả (Admin)
+// @Tags Admin - Typo Reports
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Param status query string false "Report status (pending, fixed, rejected)"
+// @Success 200 {object} response.Pagination
+// @Router /api/admin/typo-reports [get]
+func (h *TypoReportHandler) GetTypoReports(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	status := c.DefaultQuery("status", "pending")
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	reports, total, err := h.typoReportService.GetReports(page, limit, status)
+	if err != nil {
+		response.InternalServerError(c, "Không thể lấy danh sách báo lỗi")
+		return
+	}
+
+	response.PaginatedResponse(c, reports, page, limit, total)
+}
+
+// ResolveTypoReport godoc
+// @Summary Xử lý báo lỗi chính tả (Admin)
+// @Tags Admin - Typo Reports
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Report ID"
+// @Param body body ResolveTypoReportRequest true "Resolve action"
+// @Success 200 {object} response.Response
+// @Router /api/admin/typo-reports/{id} [put]
+func (h *TypoReportHandler) ResolveTypoReport(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "ID không hợp lệ")
+		return
+	}
+
+	var req ResolveTypoReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Dữ liệu không hợp lệ")
+		return
+	}
+
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "Chưa đăng nhập")
+		return
+	}
+
+	report, err := h.typoReportService.ResolveReport(id, adminID.(uuid.UUID), req.Status, req.SuggestedText)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Oke(c, report)
+}
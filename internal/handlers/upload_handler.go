@@ -2,24 +2,40 @@ package handlers
 
 import (
 	"fmt"
+	"io"
 	"path/filepath"
 	"strings"
+	"sync"
 
+	"nekozanedex/internal/models"
 	"nekozanedex/internal/services"
+	"nekozanedex/pkg/importer"
 	"nekozanedex/pkg/response"
 	"nekozanedex/pkg/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type UploadHandler struct {
-	uploadService services.UploadService
+	uploadService  services.UploadService
+	chapterService services.ChapterService
 }
 
-func NewUploadHandler(uploadService services.UploadService) *UploadHandler {
-	return &UploadHandler{uploadService: uploadService}
+func NewUploadHandler(uploadService services.UploadService, chapterService services.ChapterService) *UploadHandler {
+	return &UploadHandler{
+		uploadService:  uploadService,
+		chapterService: chapterService,
+	}
 }
 
+const (
+	maxArchiveSize     = 300 * 1024 * 1024 // Dung lượng tối đa file CBZ/ZIP
+	maxArchivePageSize = 20 * 1024 * 1024  // Dung lượng tối đa 1 trang trong archive
+	maxArchivePages    = 500
+	archiveWorkers     = 4 // Số trang xử lý + upload song song
+)
+
 // UploadSingleImage godoc
 // @Summary Upload single image (Admin)
 // @Tags Media
@@ -28,6 +44,8 @@ func NewUploadHandler(uploadService services.UploadService) *UploadHandler {
 // @Produce json
 // @Param image formance file true "Image file"
 // @Param folder formance string false "Folder name"
+// @Param owner_type formData string false "Entity sở hữu ảnh (story, chapter, volume, user)"
+// @Param owner_id formData string false "ID entity sở hữu ảnh"
 // @Success 200 {object} response.Response
 // @Router /api/admin/media [post]
 func (h *UploadHandler) UploadSingleImage(c *gin.Context) {
@@ -45,7 +63,24 @@ func (h *UploadHandler) UploadSingleImage(c *gin.Context) {
 
 	folder := c.DefaultPostForm("folder", "manga")
 
-	url, err := h.uploadService.UploadImage(file, header.Filename, folder)
+	owner := services.MediaOwner{UploadedBy: uploaderID(c)}
+	if ownerType := c.PostForm("owner_type"); ownerType != "" {
+		if !isValidMediaOwnerType(ownerType) {
+			response.BadRequest(c, "owner_type không hợp lệ")
+			return
+		}
+		owner.Type = ownerType
+	}
+	if ownerID := c.PostForm("owner_id"); ownerID != "" {
+		id, err := uuid.Parse(ownerID)
+		if err != nil {
+			response.BadRequest(c, "owner_id không hợp lệ")
+			return
+		}
+		owner.ID = &id
+	}
+
+	url, err := h.uploadService.UploadImage(file, header.Filename, folder, owner)
 	if err != nil {
 		response.BadRequest(c, err.Error())
 		return
@@ -88,7 +123,9 @@ func (h *UploadHandler) UploadAvatar(c *gin.Context) {
 	fmt.Printf("[UploadAvatar] Processed: %s, Original: %d bytes -> New: %d bytes (%dx%d)\n",
 		processed.Filename, processed.OriginalSize, processed.NewSize, processed.Width, processed.Height)
 
-	url, err := h.uploadService.UploadImageBytes(processed.Data, processed.Filename, "avatars")
+	userID := uploaderID(c)
+	owner := services.MediaOwner{Type: models.MediaOwnerUser, ID: userID, UploadedBy: userID}
+	url, err := h.uploadService.UploadImageBytes(processed.Data, processed.Filename, "avatars", owner)
 	if err != nil {
 		response.BadRequest(c, err.Error())
 		return
@@ -145,7 +182,9 @@ func (h *UploadHandler) UploadChapterImages(c *gin.Context) {
 
 	folder := fmt.Sprintf("manga/%s/chapter-%s", sanitizeSlug(storySlug), chapterNumber)
 
-	urls, err := h.uploadService.UploadMultipleImages(files, folder)
+	// Chapter có thể chưa được tạo lúc upload nên chưa biết ID
+	owner := services.MediaOwner{Type: models.MediaOwnerChapter, UploadedBy: uploaderID(c)}
+	urls, err := h.uploadService.UploadMultipleImages(files, folder, owner)
 	if err != nil {
 		response.BadRequest(c, err.Error())
 		return
@@ -159,18 +198,196 @@ func (h *UploadHandler) UploadChapterImages(c *gin.Context) {
 	})
 }
 
+// UploadChapterArchive godoc
+// @Summary Upload chapter manga từ file CBZ/ZIP (Admin)
+// @Description Gửi chapter_id để thay ảnh của chapter có sẵn, hoặc story_id + title để tạo chapter mới.
+// @Tags Upload
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "File CBZ/ZIP"
+// @Param chapter_id formData string false "Chapter cần cập nhật ảnh"
+// @Param story_id formData string false "Truyện để tạo chapter mới"
+// @Param title formData string false "Tiêu đề chapter mới"
+// @Param chapter_label formData string false "Nhãn hiển thị chapter mới"
+// @Success 200 {object} response.Response
+// @Router /api/admin/media/chapter/archive [post]
+func (h *UploadHandler) UploadChapterArchive(c *gin.Context) {
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		response.BadRequest(c, "Không tìm thấy file CBZ/ZIP")
+		return
+	}
+	defer file.Close()
+
+	ext := strings.ToLower(filepath.Ext(header.Filename))
+	if ext != ".cbz" && ext != ".zip" {
+		response.BadRequest(c, "Chỉ chấp nhận file .cbz hoặc .zip")
+		return
+	}
+	if header.Size > maxArchiveSize {
+		response.BadRequest(c, "File quá lớn (tối đa 300MB)")
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, maxArchiveSize+1))
+	if err != nil {
+		response.BadRequest(c, "Không thể đọc file")
+		return
+	}
+
+	pages, err := importer.ReadImageArchive(data, maxArchivePageSize, maxArchivePages)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	editorID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "Chưa đăng nhập")
+		return
+	}
+
+	// Xác định chapter: cập nhật chapter có sẵn hoặc tạo chapter nháp mới
+	created := false
+	var chapterID uuid.UUID
+	if idStr := c.PostForm("chapter_id"); idStr != "" {
+		chapterID, err = uuid.Parse(idStr)
+		if err != nil {
+			response.BadRequest(c, "Chapter ID không hợp lệ")
+			return
+		}
+	} else {
+		storyID, err := uuid.Parse(c.PostForm("story_id"))
+		if err != nil {
+			response.BadRequest(c, "Cần chapter_id hoặc story_id hợp lệ")
+			return
+		}
+
+		chapter := &models.Chapter{Title: c.PostForm("title")}
+		if label := strings.TrimSpace(c.PostForm("chapter_label")); label != "" {
+			chapter.ChapterLabel = &label
+		}
+		if err := h.chapterService.CreateChapter(storyID, chapter); err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		chapterID = chapter.ID
+		created = true
+	}
+
+	chapter, err := h.chapterService.GetChapterByID(chapterID)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	folder := fmt.Sprintf("manga/%s/chapter-%d", sanitizeSlug(chapter.Story.Slug), chapter.ChapterNumber)
+	owner := services.MediaOwner{Type: models.MediaOwnerChapter, ID: &chapterID, UploadedBy: uploaderID(c)}
+	urls, originalSize, newSize, err := h.processAndUploadPages(pages, folder, owner)
+	if err != nil {
+		if created {
+			_ = h.chapterService.DeleteChapter(chapterID)
+		}
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.chapterService.SetChapterImages(chapterID, urls, editorID.(uuid.UUID)); err != nil {
+		if created {
+			_ = h.chapterService.DeleteChapter(chapterID)
+		}
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Oke(c, gin.H{
+		"chapter_id":     chapterID,
+		"chapter_number": chapter.ChapterNumber,
+		"created":        created,
+		"urls":           urls,
+		"count":          len(urls),
+		"original_size":  originalSize,
+		"total_size":     newSize,
+		"folder":         folder,
+	})
+}
+
+// processAndUploadPages - Resize từng trang qua ProcessChapterImage rồi upload, giữ đúng thứ tự trang
+// Dừng ở lỗi đầu tiên: các trang chưa bắt đầu sẽ không được xử lý nữa (ảnh đã upload do media GC dọn)
+func (h *UploadHandler) processAndUploadPages(pages []importer.ArchivePage, folder string, owner services.MediaOwner) ([]string, int64, int64, error) {
+	urls := make([]string, len(pages))
+	var originalSize, newSize int64
+	var firstErr error
+	var mu sync.Mutex
+
+	fail := func(page importer.ArchivePage, err error) {
+		mu.Lock()
+		if firstErr == nil {
+			firstErr = fmt.Errorf("trang %s: %w", page.Name, err)
+		}
+		mu.Unlock()
+	}
+
+	sem := make(chan struct{}, archiveWorkers)
+	var wg sync.WaitGroup
+	for i, page := range pages {
+		sem <- struct{}{}
+		mu.Lock()
+		failed := firstErr != nil
+		mu.Unlock()
+		if failed {
+			<-sem
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, page importer.ArchivePage) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			processed, err := utils.ProcessChapterImageBytes(page.Data, filepath.Base(page.Name))
+			if err != nil {
+				fail(page, err)
+				return
+			}
+			url, err := h.uploadService.UploadImageBytes(processed.Data, processed.Filename, folder, owner)
+			if err != nil {
+				fail(page, err)
+				return
+			}
+			urls[i] = url
+
+			mu.Lock()
+			originalSize += processed.OriginalSize
+			newSize += processed.NewSize
+			mu.Unlock()
+		}(i, page)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, 0, 0, firstErr
+	}
+	return urls, originalSize, newSize, nil
+}
+
 // DeleteImage godoc
-// @Summary Delete image from Cloudinary
+// @Summary Delete image from storage
 // @Tags Upload
 // @Security BearerAuth
 // @Produce json
-// @Param public_id query string true "Cloudinary public ID"
+// @Param public_id query string false "Public ID (Cloudinary) hoặc key (local, S3)"
+// @Param url query string false "URL ảnh, dùng khi không có public_id"
 // @Success 200 {object} response.Response
 // @Router /api/admin/upload [delete]
 func (h *UploadHandler) DeleteImage(c *gin.Context) {
 	publicID := c.Query("public_id")
 	if publicID == "" {
-		response.BadRequest(c, "Thiếu public_id")
+		publicID = h.uploadService.PublicIDFromURL(c.Query("url"))
+	}
+	if publicID == "" {
+		response.BadRequest(c, "Thiếu public_id hoặc url không thuộc storage hiện tại")
 		return
 	}
 
@@ -182,6 +399,27 @@ func (h *UploadHandler) DeleteImage(c *gin.Context) {
 	response.Oke(c, gin.H{"message": "Xóa ảnh thành công"})
 }
 
+// uploaderID - User đang upload (nil nếu route không qua AuthMiddleware)
+func uploaderID(c *gin.Context) *uuid.UUID {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		return nil
+	}
+	id, ok := userID.(uuid.UUID)
+	if !ok {
+		return nil
+	}
+	return &id
+}
+
+func isValidMediaOwnerType(ownerType string) bool {
+	switch ownerType {
+	case models.MediaOwnerStory, models.MediaOwnerChapter, models.MediaOwnerVolume, models.MediaOwnerUser:
+		return true
+	}
+	return false
+}
+
 func sanitizeSlug(slug string) string {
 	slug = strings.ToLower(slug)
 	slug = strings.ReplaceAll(slug, " ", "-")
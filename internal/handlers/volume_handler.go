@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"nekozanedex/internal/models"
+	"nekozanedex/internal/services"
+	"nekozanedex/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type VolumeHandler struct {
+	volumeService services.VolumeService
+}
+
+func NewVolumeHandler(volumeService services.VolumeService) *VolumeHandler {
+	return &VolumeHandler{volumeService: volumeService}
+}
+
+type VolumeRequest struct {
+	VolumeNumber  int     `json:"volume_number" binding:"min=0"` // 0 = tự lấy số tiếp theo
+	Title         string  `json:"title"`
+	CoverImageURL *string `json:"cover_image_url"` // Update: bỏ trống = giữ nguyên, "" = xóa
+	Description   *string `json:"description"`
+}
+
+type MoveChaptersRequest struct {
+	ChapterIDs []uuid.UUID `json:"chapter_ids" binding:"required,min=1"`
+	VolumeID   *uuid.UUID  `json:"volume_id"` // null = bỏ khỏi tập
+}
+
+// GetVolumesByStory godoc
+// @Summary Danh sách tập của truyện (Admin)
+// @Tags Admin - Volumes
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Story ID"
+// @Success 200 {object} response.Response
+// @Router /api/admin/stories/{id}/volumes [get]
+func (h *VolumeHandler) GetVolumesByStory(c *gin.Context) {
+	storyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Story ID không hợp lệ")
+		return
+	}
+
+	volumes, err := h.volumeService.GetVolumesByStory(storyID)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Oke(c, volumes)
+}
+
+// CreateVolume godoc
+// @Summary Tạo tập mới (Admin)
+// @Tags Admin - Volumes
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Story ID"
+// @Param body body VolumeRequest true "Volume Info"
+// @Success 201 {object} response.Response
+// @Router /api/admin/stories/{id}/volumes [post]
+func (h *VolumeHandler) CreateVolume(c *gin.Context) {
+	storyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Story ID không hợp lệ")
+		return
+	}
+
+	var req VolumeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Dữ liệu không hợp lệ")
+		return
+	}
+
+	volume := &models.Volume{
+		VolumeNumber:  req.VolumeNumber,
+		Title:         req.Title,
+		CoverImageURL: req.CoverImageURL,
+		Description:   req.Description,
+	}
+	if err := h.volumeService.CreateVolume(storyID, volume); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Created(c, volume)
+}
+
+// UpdateVolume godoc
+// @Summary Cập nhật tập (Admin)
+// @Tags Admin - Volumes
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Volume ID"
+// @Param body body VolumeRequest true "Volume Info"
+// @Success 200 {object} response.Response
+// @Router /api/admin/volumes/{id} [put]
+func (h *VolumeHandler) UpdateVolume(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "ID không hợp lệ")
+		return
+	}
+
+	var req VolumeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Dữ liệu không hợp lệ")
+		return
+	}
+
+	volume, err := h.volumeService.UpdateVolume(id, &models.Volume{
+		VolumeNumber:  req.VolumeNumber,
+		Title:         req.Title,
+		CoverImageURL: req.CoverImageURL,
+		Description:   req.Description,
+	})
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Oke(c, volume)
+}
+
+// DeleteVolume godoc
+// @Summary Xóa tập, giữ lại chapters (Admin)
+// @Tags Admin - Volumes
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Volume ID"
+// @Success 200 {object} response.Response
+// @Router /api/admin/volumes/{id} [delete]
+func (h *VolumeHandler) DeleteVolume(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "ID không hợp lệ")
+		return
+	}
+
+	if err := h.volumeService.DeleteVolume(id); err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Oke(c, gin.H{"message": "Xóa thành công"})
+}
+
+// MoveChapters godoc
+// @Summary Chuyển chapters giữa các tập (Admin)
+// @Tags Admin - Volumes
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Story ID"
+// @Param body body MoveChaptersRequest true "Chapters và tập đích"
+// @Success 200 {object} response.Response
+// @Router /api/admin/stories/{id}/volumes/move [put]
+func (h *VolumeHandler) MoveChapters(c *gin.Context) {
+	storyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Story ID không hợp lệ")
+		return
+	}
+
+	var req MoveChaptersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Dữ liệu không hợp lệ")
+		return
+	}
+
+	moved, err := h.volumeService.MoveChapters(storyID, req.ChapterIDs, req.VolumeID)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Oke(c, gin.H{"moved": moved})
+}
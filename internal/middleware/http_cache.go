@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+
+	"nekozanedex/pkg/httpcache"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Cache-Control theo loại route
+const (
+	// CacheChapterContent - Nội dung chapter hầu như không đổi sau khi xuất bản, cho CDN giữ lâu hơn
+	CacheChapterContent = "public, max-age=300, s-maxage=3600, stale-while-revalidate=600"
+	// CachePublicPage - Trang truyện, danh sách chapter, list truyện/thể loại
+	CachePublicPage = "public, max-age=60, s-maxage=300, stale-while-revalidate=60"
+	// CachePrivate - Admin/người dùng đã đăng nhập: chỉ browser được giữ, luôn revalidate bằng ETag
+	CachePrivate = "private, no-cache"
+)
+
+// ConditionalGET - Buffer response GET 200, gắn strong ETag (hash nội dung nếu handler chưa set)
+// cùng Cache-Control, trả 304 khi khớp If-None-Match/If-Modified-Since.
+// Request có Authorization/cookie đăng nhập luôn nhận CachePrivate để CDN không lưu dữ liệu riêng.
+func ConditionalGET(cacheControl string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		writer := &bufferedWriter{ResponseWriter: original, status: http.StatusOK}
+		c.Writer = writer
+		c.Next()
+		c.Writer = original
+
+		if writer.status != http.StatusOK {
+			writer.flush(writer.status)
+			return
+		}
+
+		header := original.Header()
+		if header.Get("Cache-Control") == "" {
+			policy := cacheControl
+			if isAuthenticatedRequest(c) {
+				policy = CachePrivate
+			}
+			header.Set("Cache-Control", policy)
+		}
+		header.Add("Vary", "Authorization")
+		// Vary: Cookie chỉ cho response private: response public giống nhau với mọi cookie,
+		// thêm Cookie sẽ làm CDN tách cache theo từng cookie (theme, analytics...) và gần như không hit
+		if strings.Contains(header.Get("Cache-Control"), "private") {
+			header.Add("Vary", "Cookie")
+		}
+
+		etag := header.Get("ETag")
+		if etag == "" {
+			etag = httpcache.ETag(writer.body.Bytes())
+			header.Set("ETag", etag)
+		}
+		lastModified, _ := http.ParseTime(header.Get("Last-Modified"))
+
+		if httpcache.NotModified(c.Request, etag, lastModified) {
+			header.Del("Content-Type")
+			header.Del("Content-Length")
+			original.WriteHeader(http.StatusNotModified)
+			original.WriteHeaderNow()
+			return
+		}
+		writer.flush(http.StatusOK)
+	}
+}
+
+// isAuthenticatedRequest - Có token (header hoặc cookie) thì response có thể chứa dữ liệu riêng của user
+func isAuthenticatedRequest(c *gin.Context) bool {
+	if c.GetHeader("Authorization") != "" {
+		return true
+	}
+	if _, exists := c.Get("user_id"); exists {
+		return true
+	}
+	token, err := c.Cookie("access_token")
+	return err == nil && token != ""
+}
+
+// bufferedWriter - Giữ status và body lại cho tới khi ConditionalGET quyết định 200 hay 304
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.body.Len() > 0
+}
+
+func (w *bufferedWriter) flush(status int) {
+	w.ResponseWriter.WriteHeader(status)
+	w.ResponseWriter.WriteHeaderNow()
+	if w.body.Len() > 0 {
+		_, _ = w.ResponseWriter.Write(w.body.Bytes())
+	}
+}
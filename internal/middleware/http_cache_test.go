@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"nekozanedex/pkg/httpcache"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newConditionalGETRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ConditionalGET(CachePublicPage))
+	r.GET("/ok", func(c *gin.Context) { c.String(http.StatusOK, "hello") })
+	r.GET("/missing", func(c *gin.Context) { c.String(http.StatusNotFound, "nope") })
+	r.POST("/ok", func(c *gin.Context) { c.String(http.StatusOK, "posted") })
+	return r
+}
+
+func TestConditionalGET(t *testing.T) {
+	etag := httpcache.ETag([]byte("hello"))
+	varyPublic := []string{"Authorization"}
+	varyPrivate := []string{"Authorization", "Cookie"}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		headers    map[string]string
+		wantStatus int
+		wantBody   string
+		wantETag   string
+		wantCache  string
+		wantVary   []string
+	}{
+		{
+			name: "anonymous", method: http.MethodGet, path: "/ok",
+			wantStatus: http.StatusOK, wantBody: "hello", wantETag: etag, wantCache: CachePublicPage, wantVary: varyPublic,
+		},
+		{
+			name: "etag match", method: http.MethodGet, path: "/ok", headers: map[string]string{"If-None-Match": etag},
+			wantStatus: http.StatusNotModified, wantBody: "", wantETag: etag, wantCache: CachePublicPage, wantVary: varyPublic,
+		},
+		{
+			name: "etag mismatch", method: http.MethodGet, path: "/ok", headers: map[string]string{"If-None-Match": `"old"`},
+			wantStatus: http.StatusOK, wantBody: "hello", wantETag: etag, wantCache: CachePublicPage, wantVary: varyPublic,
+		},
+		{
+			name: "authorization header", method: http.MethodGet, path: "/ok", headers: map[string]string{"Authorization": "Bearer x"},
+			wantStatus: http.StatusOK, wantBody: "hello", wantETag: etag, wantCache: CachePrivate, wantVary: varyPrivate,
+		},
+		{
+			name: "access token cookie", method: http.MethodGet, path: "/ok", headers: map[string]string{"Cookie": "access_token=x"},
+			wantStatus: http.StatusOK, wantBody: "hello", wantETag: etag, wantCache: CachePrivate, wantVary: varyPrivate,
+		},
+		{
+			name: "other cookie stays public", method: http.MethodGet, path: "/ok", headers: map[string]string{"Cookie": "theme=dark"},
+			wantStatus: http.StatusOK, wantBody: "hello", wantETag: etag, wantCache: CachePublicPage, wantVary: varyPublic,
+		},
+		{
+			name: "non-200 passes through", method: http.MethodGet, path: "/missing",
+			wantStatus: http.StatusNotFound, wantBody: "nope",
+		},
+		{
+			name: "non-GET untouched", method: http.MethodPost, path: "/ok", headers: map[string]string{"If-None-Match": etag},
+			wantStatus: http.StatusOK, wantBody: "posted",
+		},
+	}
+
+	router := newConditionalGETRouter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Body.String(); got != tt.wantBody {
+				t.Fatalf("body = %q, want %q", got, tt.wantBody)
+			}
+			if got := w.Header().Get("ETag"); got != tt.wantETag {
+				t.Fatalf("ETag = %q, want %q", got, tt.wantETag)
+			}
+			if got := w.Header().Get("Cache-Control"); got != tt.wantCache {
+				t.Fatalf("Cache-Control = %q, want %q", got, tt.wantCache)
+			}
+			if got := w.Header().Values("Vary"); !slices.Equal(got, tt.wantVary) {
+				t.Fatalf("Vary = %v, want %v", got, tt.wantVary)
+			}
+		})
+	}
+}
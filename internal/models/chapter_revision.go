@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ChapterRevision - Snapshot nội dung chapter sau mỗi lần chỉnh sửa
+type ChapterRevision struct {
+	ID             uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	ChapterID      uuid.UUID      `json:"chapter_id" gorm:"type:uuid;not null;uniqueIndex:idx_chapter_revision_number"`
+	RevisionNumber int            `json:"revision_number" gorm:"not null;uniqueIndex:idx_chapter_revision_number"`
+	Title          string         `json:"title" gorm:"not null;size:255"`
+	Content        string         `json:"content,omitempty" gorm:"type:text;default:''"`
+	Images         datatypes.JSON `json:"images,omitempty" gorm:"type:jsonb"`
+	EditorID       *uuid.UUID     `json:"editor_id" gorm:"type:uuid;index"` // NULL = bản gốc trước khi có lịch sử
+	Note           *string        `json:"note" gorm:"size:255"`             // "restore từ #3", "typo fix"...
+	CreatedAt      time.Time      `json:"created_at"`
+
+	// Relations
+	Editor *User `json:"editor,omitempty" gorm:"foreignKey:EditorID"`
+}
+
+func (ChapterRevision) TableName() string {
+	return "chapter_revisions"
+}
+
+func (r *ChapterRevision) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ChatMute - Cấm chat một user (trong 1 phòng hoặc toàn bộ phòng)
+type ChatMute struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID     uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	RoomID     *string    `json:"room_id" gorm:"size:100;index"` // NULL = tất cả phòng
+	Reason     *string    `json:"reason" gorm:"size:255"`
+	MutedUntil *time.Time `json:"muted_until" gorm:"index"` // NULL = vĩnh viễn
+	CreatedBy  uuid.UUID  `json:"created_by" gorm:"type:uuid;not null"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	// Relations
+	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+func (ChatMute) TableName() string {
+	return "chat_mutes"
+}
+
+func (m *ChatMute) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
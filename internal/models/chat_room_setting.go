@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// ChatRoomSetting - Cấu hình riêng của phòng chat (slow-mode)
+type ChatRoomSetting struct {
+	RoomID          string    `json:"room_id" gorm:"primaryKey;size:100"`
+	SlowModeSeconds int       `json:"slow_mode_seconds" gorm:"default:0"` // 0 = tắt
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+func (ChatRoomSetting) TableName() string {
+	return "chat_room_settings"
+}
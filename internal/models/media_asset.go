@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Loại entity sở hữu file upload
+const (
+	MediaOwnerStory   = "story"
+	MediaOwnerChapter = "chapter"
+	MediaOwnerVolume  = "volume"
+	MediaOwnerUser    = "user"
+)
+
+// MediaAsset - File đã upload lên storage, job GC dọn file không còn được tham chiếu
+type MediaAsset struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	URL            string     `json:"url" gorm:"type:text;not null;uniqueIndex"`
+	StorageKey     string     `json:"storage_key" gorm:"type:text;not null"`                            // ID truyền vào DeleteImage của driver
+	Driver         string     `json:"driver" gorm:"size:20;not null"`                                   // local, s3, cloudinary
+	OwnerType      string     `json:"owner_type" gorm:"size:20;index:idx_media_asset_owner,priority:1"` // Rỗng = chưa rõ (upload tự do)
+	OwnerID        *uuid.UUID `json:"owner_id" gorm:"type:uuid;index:idx_media_asset_owner,priority:2"`
+	UploadedBy     *uuid.UUID `json:"uploaded_by" gorm:"type:uuid"`
+	Size           int64      `json:"size"`
+	UnreferencedAt *time.Time `json:"unreferenced_at" gorm:"index"` // Lần đầu GC thấy không còn tham chiếu
+	CreatedAt      time.Time  `json:"created_at" gorm:"index"`
+}
+
+func (MediaAsset) TableName() string {
+	return "media_assets"
+}
+
+func (m *MediaAsset) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
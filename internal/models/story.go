@@ -38,6 +38,9 @@ type Story struct {
 	// Relations
 	Chapters []Chapter `json:"chapters,omitempty" gorm:"foreignKey:StoryID"`
 	Genres   []Genre   `json:"genres,omitempty" gorm:"many2many:story_genres"`
+
+	// Computed - không lưu DB
+	RatingDistribution map[int]int64 `json:"rating_distribution,omitempty" gorm:"-"` // Số lượt đánh giá theo từng mức sao (1-5)
 }
 
 // TableName - custom table name
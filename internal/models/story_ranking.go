@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// StoryRanking - Bảng xếp hạng đã tính sẵn theo kỳ (day/week/month) từ story_views
+type StoryRanking struct {
+	ID           uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Period       string    `json:"period" gorm:"size:10;not null;uniqueIndex:idx_story_ranking_period_story;index:idx_story_ranking_period_rank,priority:1"`
+	StoryID      uuid.UUID `json:"story_id" gorm:"type:uuid;not null;uniqueIndex:idx_story_ranking_period_story"`
+	Rank         int       `json:"rank" gorm:"not null;index:idx_story_ranking_period_rank,priority:2"`
+	PreviousRank *int      `json:"previous_rank"` // Hạng ở kỳ trước, NULL = kỳ trước không lọt bảng
+	Score        float64   `json:"score"`         // Tổng lượt xem đã nhân hệ số suy giảm theo tuổi
+	Views        int64     `json:"views"`         // Tổng lượt xem thô trong kỳ
+	ComputedAt   time.Time `json:"computed_at"`
+
+	// Relations
+	Story Story `json:"story,omitempty" gorm:"foreignKey:StoryID"`
+}
+
+func (StoryRanking) TableName() string {
+	return "story_rankings"
+}
+
+func (r *StoryRanking) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// StoryRating - Điểm đánh giá của một user cho một truyện (1 user = 1 lượt / truyện)
+type StoryRating struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_story_rating_user_story"`
+	StoryID   uuid.UUID `json:"story_id" gorm:"type:uuid;not null;uniqueIndex:idx_story_rating_user_story;index"`
+	Score     int       `json:"score" gorm:"not null;check:score >= 1 AND score <= 5"` // 1 - 5
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relations
+	User  User  `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Story Story `json:"story,omitempty" gorm:"foreignKey:StoryID"`
+}
+
+func (StoryRating) TableName() string {
+	return "story_ratings"
+}
+
+func (r *StoryRating) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
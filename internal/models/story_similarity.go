@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// StorySimilarity - "Người đọc truyện này cũng đọc", tính sẵn bởi job nền
+type StorySimilarity struct {
+	ID             uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	StoryID        uuid.UUID `json:"story_id" gorm:"type:uuid;not null;uniqueIndex:idx_story_similarity_pair;index:idx_story_similarity_score,priority:1"`
+	SimilarStoryID uuid.UUID `json:"similar_story_id" gorm:"type:uuid;not null;uniqueIndex:idx_story_similarity_pair"`
+	Score          float64   `json:"score" gorm:"index:idx_story_similarity_score,priority:2,sort:desc"`
+	Source         string    `json:"source" gorm:"size:20;not null"` // co_read, genre
+	ComputedAt     time.Time `json:"computed_at"`
+
+	// Relations
+	SimilarStory Story `json:"similar_story,omitempty" gorm:"foreignKey:SimilarStoryID"`
+}
+
+func (StorySimilarity) TableName() string {
+	return "story_similarities"
+}
+
+func (s *StorySimilarity) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
@@ -13,6 +13,8 @@ type BookmarkRepository interface {
 	FindBookmarkByUserAndStory(userID, storyID uuid.UUID) (*models.BookMark, error)
 	GetBookmarksByUser(userID uuid.UUID, page, limit int) ([]models.BookMark, int64, error)
 	IsBookmarked(userID, storyID uuid.UUID) bool
+	GetBookmarkerIDs(storyID, afterUserID uuid.UUID, limit int) ([]uuid.UUID, error)
+	CountBookmarksByBucket(r AnalyticsRange) ([]CountBucket, error)
 }
 
 type bookmarkRepository struct {
@@ -67,3 +69,30 @@ func (r *bookmarkRepository) IsBookmarked(userID, storyID uuid.UUID) bool {
 	r.db.Model(&models.BookMark{}).Where("user_id = ? AND story_id = ?", userID, storyID).Count(&count)
 	return count > 0
 }
+
+// GetBookmarkerIDs - Lấy user_id đã bookmark truyện theo từng lô (keyset theo user_id)
+// Lô đầu tiên truyền afterUserID = uuid.Nil
+func (r *bookmarkRepository) GetBookmarkerIDs(storyID, afterUserID uuid.UUID, limit int) ([]uuid.UUID, error) {
+	var userIDs []uuid.UUID
+	err := r.db.Model(&models.BookMark{}).
+		Distinct("user_id").
+		Where("story_id = ? AND user_id > ?", storyID, afterUserID).
+		Order("user_id ASC").
+		Limit(limit).
+		Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
+
+// CountBookmarksByBucket - Số bookmark mới theo bucket
+func (r *bookmarkRepository) CountBookmarksByBucket(ar AnalyticsRange) ([]CountBucket, error) {
+	query := r.db.Model(&models.BookMark{}).
+		Select(analyticsBucketExpr("created_at")+" AS bucket, COUNT(*) AS count", ar.Bucket).
+		Where("created_at >= ? AND created_at < ?", ar.From.Format("2006-01-02"), ar.To.Format("2006-01-02"))
+	if ar.StoryID != nil {
+		query = query.Where("story_id = ?", *ar.StoryID)
+	}
+
+	var buckets []CountBucket
+	err := query.Group("bucket").Order("bucket").Scan(&buckets).Error
+	return buckets, err
+}
@@ -1,12 +1,28 @@
 package repositories
 
 import (
+	"strings"
+	"time"
+
 	"nekozanedex/internal/models"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// ChapterFeedFilter - Giới hạn feed theo truyện hoặc thể loại (nil = toàn site)
+type ChapterFeedFilter struct {
+	StoryID *uuid.UUID
+	GenreID *uuid.UUID
+}
+
+// ChapterSitemapEntry - 1 chapter trong sitemap
+type ChapterSitemapEntry struct {
+	StorySlug     string
+	ChapterNumber int
+	UpdatedAt     time.Time
+}
+
 type ChapterRepository interface {
 	Create(chapter *models.Chapter) error
 	FindByID(id uuid.UUID) (*models.Chapter, error)
@@ -15,8 +31,16 @@ type ChapterRepository interface {
 	Delete(id uuid.UUID) error
 	GetByStory(storyID uuid.UUID, published bool) ([]models.Chapter, error)
 	GetByStoryPaginated(storyID uuid.UUID, published bool, offset, limit int) ([]models.Chapter, int64, error)
-	IncrementViewCount(id uuid.UUID) error
 	GetScheduledChapters() ([]models.Chapter, error)
+	GetMaxChapterNumber(storyID uuid.UUID) (int, error)
+	RenumberChapters(storyID uuid.UUID, orderedIDs []uuid.UUID) error
+	AppendChapters(storyID uuid.UUID, chapters []*models.Chapter) error
+	InsertChapterAfter(chapter *models.Chapter, afterNumber int) error
+	DeleteAndRecount(chapter *models.Chapter) error
+	FindPublishedNeighbours(chapter *models.Chapter) (prev, next *models.Chapter, err error)
+	GetLatestPublished(filter ChapterFeedFilter, limit int) ([]models.Chapter, error)
+	CountPublishedChapters() (int64, error)
+	GetPublishedChapterSitemap(offset, limit int) ([]ChapterSitemapEntry, error)
 }
 
 type chapterRepository struct {
@@ -64,18 +88,18 @@ func (r *chapterRepository) Delete(id uuid.UUID) error {
 	return r.db.Delete(&models.Chapter{}, "id = ?", id).Error
 }
 
-//Get By Story - Lấy Theo Story
+//Get By Story - Lấy Theo Story (danh sách, không kèm content/images)
 func (r *chapterRepository) GetByStory(storyID uuid.UUID, published bool) ([]models.Chapter, error) {
 	var chapters []models.Chapter
-	query := r.db.Where("story_id = ?", storyID)
+	query := r.db.Omit(chapterListOmit...).Where("story_id = ?", storyID)
 	if published {
 		query = query.Where("is_published = ?", true)
 	}
-	err := query.Order("chapter_number ASC").Find(&chapters).Error
+	err := query.Order(chapterSortKey + " ASC, chapter_number ASC").Find(&chapters).Error
 	return chapters, err
 }
 
-// GetByStoryPaginated - Lấy chapters theo trang với tổng số
+// GetByStoryPaginated - Lấy chapters theo trang với tổng số (không kèm content/images)
 func (r *chapterRepository) GetByStoryPaginated(storyID uuid.UUID, published bool, offset, limit int) ([]models.Chapter, int64, error) {
 	var chapters []models.Chapter
 	var total int64
@@ -91,19 +115,276 @@ func (r *chapterRepository) GetByStoryPaginated(storyID uuid.UUID, published boo
 	}
 
 	// Get paginated results
-	err := query.Order("chapter_number DESC").Offset(offset).Limit(limit).Find(&chapters).Error
+	err := query.Omit(chapterListOmit...).Order(chapterSortKey + " DESC, chapter_number DESC").Offset(offset).Limit(limit).Find(&chapters).Error
 	return chapters, total, err
 }
 
-//Increment View Count - Tăng Lượt Xem
-func (r *chapterRepository) IncrementViewCount(id uuid.UUID) error {
-	return r.db.Model(&models.Chapter{}).Where("id = ?", id).
-		UpdateColumn("view_count", gorm.Expr("view_count + 1")).Error
-}
-
 //Get Scheduled Chapters - Lấy Chương Được Lên Kệ
 func (r *chapterRepository) GetScheduledChapters() ([]models.Chapter, error) {
 	var chapters []models.Chapter
 	err := r.db.Where("is_published = ? AND scheduled_at <= NOW()", false).Find(&chapters).Error
 	return chapters, err
-}
\ No newline at end of file
+}
+// GetMaxChapterNumber - Số chapter lớn nhất hiện có của truyện (0 nếu chưa có)
+func (r *chapterRepository) GetMaxChapterNumber(storyID uuid.UUID) (int, error) {
+	return maxChapterNumber(r.db, storyID)
+}
+
+// RenumberChapters - Đánh số lại chapters theo đúng thứ tự orderedIDs (1, 2, 3...)
+// ChapterNumber và Ordering được ghi lại trong 1 câu UPDATE, TotalChapters tính lại từ số row thật
+func (r *chapterRepository) RenumberChapters(storyID uuid.UUID, orderedIDs []uuid.UUID) error {
+	ids := make([]string, len(orderedIDs))
+	for i, id := range orderedIDs {
+		ids[i] = id.String()
+	}
+	idArray := "{" + strings.Join(ids, ",") + "}"
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockStory(tx, storyID); err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`
+			UPDATE chapters c
+			SET chapter_number = v.position, ordering = v.position, updated_at = NOW()
+			FROM unnest(?::uuid[]) WITH ORDINALITY AS v(id, position)
+			WHERE c.id = v.id AND c.story_id = ? AND c.deleted_at IS NULL
+		`, idArray, storyID).Error; err != nil {
+			return err
+		}
+
+		return recountStoryChapters(tx, storyID)
+	})
+}
+
+// AppendChapters - Thêm chapters vào cuối truyện, số chapter lấy tiếp từ số lớn nhất ngay trong transaction
+// (khóa row truyện nên 2 request tạo chapter cùng lúc không ra trùng số). Ordering = 0 thì lấy theo số chapter
+func (r *chapterRepository) AppendChapters(storyID uuid.UUID, chapters []*models.Chapter) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockStory(tx, storyID); err != nil {
+			return err
+		}
+
+		maxNumber, err := maxChapterNumber(tx, storyID)
+		if err != nil {
+			return err
+		}
+		for i, chapter := range chapters {
+			chapter.StoryID = storyID
+			chapter.ChapterNumber = maxNumber + 1 + i
+			if chapter.Ordering == 0 {
+				chapter.Ordering = float64(chapter.ChapterNumber)
+			}
+			if err := tx.Create(chapter).Error; err != nil {
+				return err
+			}
+		}
+
+		return recountStoryChapters(tx, storyID)
+	})
+}
+
+// InsertChapterAfter - Chèn chapter (extra, 12.5...) vào thứ tự đọc ngay sau chapter số afterNumber (0 = lên đầu).
+// Không dời số các chapter khác để giữ nguyên link, bookmark, lịch sử đọc, feed và sitemap:
+// chapter mới lấy số lớn nhất + 1, vị trí do Ordering nằm giữa 2 chapter kề bên quyết định.
+// Muốn đánh số lại thì dùng RenumberChapters.
+func (r *chapterRepository) InsertChapterAfter(chapter *models.Chapter, afterNumber int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockStory(tx, chapter.StoryID); err != nil {
+			return err
+		}
+
+		if chapter.Ordering == 0 {
+			ordering, err := orderingAfter(tx, chapter.StoryID, afterNumber)
+			if err != nil {
+				return err
+			}
+			chapter.Ordering = ordering
+		}
+
+		maxNumber, err := maxChapterNumber(tx, chapter.StoryID)
+		if err != nil {
+			return err
+		}
+		chapter.ChapterNumber = maxNumber + 1
+		if err := tx.Create(chapter).Error; err != nil {
+			return err
+		}
+
+		return recountStoryChapters(tx, chapter.StoryID)
+	})
+}
+
+// DeleteAndRecount - Xóa mềm chapter và tính lại total_chapters trong cùng transaction
+func (r *chapterRepository) DeleteAndRecount(chapter *models.Chapter) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockStory(tx, chapter.StoryID); err != nil {
+			return err
+		}
+		if err := tx.Delete(&models.Chapter{}, "id = ?", chapter.ID).Error; err != nil {
+			return err
+		}
+		return recountStoryChapters(tx, chapter.StoryID)
+	})
+}
+
+func maxChapterNumber(tx *gorm.DB, storyID uuid.UUID) (int, error) {
+	var maxNumber int
+	err := tx.Model(&models.Chapter{}).
+		Where("story_id = ?", storyID).
+		Select("COALESCE(MAX(chapter_number), 0)").
+		Scan(&maxNumber).Error
+	return maxNumber, err
+}
+
+// orderingAfter - Ordering nằm giữa chapter số afterNumber và chapter liền sau nó theo thứ tự đọc.
+// afterNumber = 0: trước chapter đầu tiên. gorm.ErrRecordNotFound nếu không có chapter số afterNumber
+func orderingAfter(tx *gorm.DB, storyID uuid.UUID, afterNumber int) (float64, error) {
+	chapters := func() *gorm.DB {
+		return tx.Model(&models.Chapter{}).Where("story_id = ?", storyID)
+	}
+
+	var keys []float64
+	if afterNumber == 0 {
+		if err := chapters().Order(chapterSortKey + " ASC").Limit(1).Pluck(chapterSortKey, &keys).Error; err != nil {
+			return 0, err
+		}
+		if len(keys) == 0 {
+			return 1, nil
+		}
+		return keys[0] / 2, nil
+	}
+
+	if err := chapters().Where("chapter_number = ?", afterNumber).Limit(1).Pluck(chapterSortKey, &keys).Error; err != nil {
+		return 0, err
+	}
+	if len(keys) == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	prev := keys[0]
+
+	keys = nil
+	if err := chapters().Where(chapterSortKey+" > ?", prev).Order(chapterSortKey + " ASC").Limit(1).Pluck(chapterSortKey, &keys).Error; err != nil {
+		return 0, err
+	}
+	if len(keys) == 0 {
+		return prev + 1, nil
+	}
+	return (prev + keys[0]) / 2, nil
+}
+
+// recountStoryChapters - Tính lại stories.total_chapters từ số chapter chưa bị xóa
+func recountStoryChapters(tx *gorm.DB, storyID uuid.UUID) error {
+	return tx.Exec(`
+		UPDATE stories
+		SET total_chapters = (
+			SELECT COUNT(*) FROM chapters WHERE story_id = ? AND deleted_at IS NULL
+		), updated_at = NOW()
+		WHERE id = ?
+	`, storyID, storyID).Error
+}
+
+// chapterSortKey - Vị trí đọc của chapter: theo Ordering (extra 12.5 nằm giữa 12 và 13),
+// chapter cũ chưa có Ordering thì dùng chapter_number
+const chapterSortKey = "COALESCE(NULLIF(ordering, 0), chapter_number)"
+
+// chapterListOmit - Danh sách chapter không cần nội dung, bỏ 2 cột nặng nhất để đỡ tải DB và cache
+var chapterListOmit = []string{"content", "images"}
+
+// chapterNavColumns - Chỉ lấy các cột cần cho điều hướng prev/next
+var chapterNavColumns = []string{"id", "story_id", "chapter_number", "chapter_label", "chapter_type", "ordering", "title"}
+
+// FindPublishedNeighbours - Chapter đã xuất bản liền trước/liền sau theo thứ tự đọc
+// nil nếu là chapter đầu/cuối
+func (r *chapterRepository) FindPublishedNeighbours(chapter *models.Chapter) (*models.Chapter, *models.Chapter, error) {
+	key := chapter.Ordering
+	if key <= 0 {
+		key = float64(chapter.ChapterNumber)
+	}
+
+	base := func() *gorm.DB {
+		return r.db.Model(&models.Chapter{}).
+			Select(chapterNavColumns).
+			Where("story_id = ? AND is_published = ? AND id <> ?", chapter.StoryID, true, chapter.ID)
+	}
+
+	var prev []models.Chapter
+	if err := base().
+		Where("("+chapterSortKey+" < ? OR ("+chapterSortKey+" = ? AND chapter_number < ?))", key, key, chapter.ChapterNumber).
+		Order(chapterSortKey + " DESC, chapter_number DESC").
+		Limit(1).
+		Find(&prev).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var next []models.Chapter
+	if err := base().
+		Where("("+chapterSortKey+" > ? OR ("+chapterSortKey+" = ? AND chapter_number > ?))", key, key, chapter.ChapterNumber).
+		Order(chapterSortKey + " ASC, chapter_number ASC").
+		Limit(1).
+		Find(&next).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var prevChapter, nextChapter *models.Chapter
+	if len(prev) > 0 {
+		prevChapter = &prev[0]
+	}
+	if len(next) > 0 {
+		nextChapter = &next[0]
+	}
+	return prevChapter, nextChapter, nil
+}
+
+// GetLatestPublished - Chapters mới xuất bản nhất (của truyện đã xuất bản), dùng cho RSS/Atom
+// Chỉ lấy 500 ký tự đầu của nội dung để làm tóm tắt
+func (r *chapterRepository) GetLatestPublished(filter ChapterFeedFilter, limit int) ([]models.Chapter, error) {
+	query := r.db.Model(&models.Chapter{}).
+		Select("chapters.id, chapters.story_id, chapters.chapter_number, chapters.chapter_label, " +
+			"chapters.title, chapters.published_at, chapters.created_at, chapters.updated_at, " +
+			"LEFT(chapters.content, 500) AS content").
+		Joins("JOIN stories ON stories.id = chapters.story_id AND stories.deleted_at IS NULL").
+		Where("chapters.is_published = ? AND chapters.published_at IS NOT NULL AND stories.is_published = ?", true, true)
+
+	if filter.StoryID != nil {
+		query = query.Where("chapters.story_id = ?", *filter.StoryID)
+	}
+	if filter.GenreID != nil {
+		query = query.Where("EXISTS (SELECT 1 FROM story_genres sg WHERE sg.story_id = chapters.story_id AND sg.genre_id = ?)", *filter.GenreID)
+	}
+
+	var chapters []models.Chapter
+	err := query.
+		Preload("Story", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "title", "slug", "author_name", "translator")
+		}).
+		Order("chapters.published_at DESC").
+		Limit(limit).
+		Find(&chapters).Error
+	return chapters, err
+}
+
+// publishedChapters - Chapters đã xuất bản thuộc truyện đã xuất bản (cả 2 chưa bị xóa mềm)
+func (r *chapterRepository) publishedChapters() *gorm.DB {
+	return r.db.Model(&models.Chapter{}).
+		Joins("JOIN stories ON stories.id = chapters.story_id AND stories.deleted_at IS NULL").
+		Where("chapters.is_published = ? AND stories.is_published = ?", true, true)
+}
+
+// CountPublishedChapters - Đếm chapters hiển thị công khai
+func (r *chapterRepository) CountPublishedChapters() (int64, error) {
+	var total int64
+	err := r.publishedChapters().Count(&total).Error
+	return total, err
+}
+
+// GetPublishedChapterSitemap - Story slug + số chapter + updated_at, thứ tự ổn định để phân trang sitemap
+func (r *chapterRepository) GetPublishedChapterSitemap(offset, limit int) ([]ChapterSitemapEntry, error) {
+	var entries []ChapterSitemapEntry
+	err := r.publishedChapters().
+		Select("stories.slug AS story_slug, chapters.chapter_number, chapters.updated_at").
+		Order("chapters.created_at ASC, chapters.id ASC").
+		Offset(offset).Limit(limit).
+		Scan(&entries).Error
+	return entries, err
+}
@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"nekozanedex/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ChapterRevisionRepository interface {
+	SaveChapterWithRevision(chapter *models.Chapter, editorID *uuid.UUID, note *string) (*models.ChapterRevision, error)
+	GetRevisions(chapterID uuid.UUID) ([]models.ChapterRevision, error)
+	FindRevision(chapterID uuid.UUID, revisionNumber int) (*models.ChapterRevision, error)
+}
+
+type chapterRevisionRepository struct {
+	db *gorm.DB
+}
+
+func NewChapterRevisionRepository(db *gorm.DB) ChapterRevisionRepository {
+	return &chapterRevisionRepository{db: db}
+}
+
+// SaveChapterWithRevision - Lưu chapter và ghi 1 revision trong cùng transaction
+// Nếu chapter chưa có revision nào, lưu trạng thái cũ làm revision #1 để không mất bản gốc
+func (r *chapterRevisionRepository) SaveChapterWithRevision(chapter *models.Chapter, editorID *uuid.UUID, note *string) (*models.ChapterRevision, error) {
+	var revision *models.ChapterRevision
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		revision, err = saveChapterWithRevision(tx, chapter, editorID, note)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+// saveChapterWithRevision - Phần thân của SaveChapterWithRevision, chạy trong transaction của người gọi
+func saveChapterWithRevision(tx *gorm.DB, chapter *models.Chapter, editorID *uuid.UUID, note *string) (*models.ChapterRevision, error) {
+	// Khóa row chapter để số revision không bị trùng khi sửa đồng thời
+	var current models.Chapter
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, "id = ?", chapter.ID).Error; err != nil {
+		return nil, err
+	}
+
+	var lastNumber int
+	if err := tx.Model(&models.ChapterRevision{}).
+		Where("chapter_id = ?", chapter.ID).
+		Select("COALESCE(MAX(revision_number), 0)").
+		Scan(&lastNumber).Error; err != nil {
+		return nil, err
+	}
+
+	if lastNumber == 0 {
+		baselineNote := "Bản gốc"
+		baseline := &models.ChapterRevision{
+			ChapterID:      current.ID,
+			RevisionNumber: 1,
+			Title:          current.Title,
+			Content:        current.Content,
+			Images:         current.Images,
+			Note:           &baselineNote,
+			CreatedAt:      current.UpdatedAt,
+		}
+		if err := tx.Create(baseline).Error; err != nil {
+			return nil, err
+		}
+		lastNumber = 1
+	}
+
+	if err := tx.Omit("Story", "Comments").Save(chapter).Error; err != nil {
+		return nil, err
+	}
+
+	revision := &models.ChapterRevision{
+		ChapterID:      chapter.ID,
+		RevisionNumber: lastNumber + 1,
+		Title:          chapter.Title,
+		Content:        chapter.Content,
+		Images:         chapter.Images,
+		EditorID:       editorID,
+		Note:           note,
+	}
+	if err := tx.Create(revision).Error; err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+// GetRevisions - Danh sách revision của chapter (không kèm nội dung, mới nhất trước)
+func (r *chapterRevisionRepository) GetRevisions(chapterID uuid.UUID) ([]models.ChapterRevision, error) {
+	var revisions []models.ChapterRevision
+	err := r.db.
+		Select("id", "chapter_id", "revision_number", "title", "editor_id", "note", "created_at").
+		Preload("Editor", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "username", "tag_name", "avatar_url")
+		}).
+		Where("chapter_id = ?", chapterID).
+		Order("revision_number DESC").
+		Find(&revisions).Error
+	return revisions, err
+}
+
+// FindRevision - Lấy 1 revision đầy đủ nội dung
+func (r *chapterRevisionRepository) FindRevision(chapterID uuid.UUID, revisionNumber int) (*models.ChapterRevision, error) {
+	var revision models.ChapterRevision
+	err := r.db.
+		Preload("Editor", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "username", "tag_name", "avatar_url")
+		}).
+		First(&revision, "chapter_id = ? AND revision_number = ?", chapterID, revisionNumber).Error
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
@@ -0,0 +1,181 @@
+package repositories
+
+import (
+	"time"
+
+	"nekozanedex/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ChatRepository interface {
+	// Messages
+	CreateMessage(message *models.ChatMessage) error
+	FindMessageByID(id uuid.UUID) (*models.ChatMessage, error)
+	GetMessagesByRoom(roomID string, page, limit int) ([]models.ChatMessage, int64, error)
+	CreateMessageWithSlowMode(message *models.ChatMessage, slowMode time.Duration) (time.Duration, error)
+	DeleteMessage(id uuid.UUID) error
+
+	// Moderation
+	GetRoomSetting(roomID string) (*models.ChatRoomSetting, error)
+	SaveRoomSetting(setting *models.ChatRoomSetting) error
+	CreateMute(mute *models.ChatMute) error
+	DeleteMute(id uuid.UUID) error
+	GetActiveMute(userID uuid.UUID, roomID string) (*models.ChatMute, error)
+	GetActiveMutes(page, limit int) ([]models.ChatMute, int64, error)
+}
+
+type chatRepository struct {
+	db *gorm.DB
+}
+
+func NewChatRepository(db *gorm.DB) ChatRepository {
+	return &chatRepository{db: db}
+}
+
+// chatUserColumns - Chỉ lấy thông tin công khai của user trong phòng chat
+func chatUserColumns(db *gorm.DB) *gorm.DB {
+	return db.Select("id", "username", "tag_name", "avatar_url", "role")
+}
+
+// CreateMessage - Tạo tin nhắn
+func (r *chatRepository) CreateMessage(message *models.ChatMessage) error {
+	return r.db.Create(message).Error
+}
+
+// FindMessageByID - Tìm tin nhắn theo ID
+func (r *chatRepository) FindMessageByID(id uuid.UUID) (*models.ChatMessage, error) {
+	var message models.ChatMessage
+	err := r.db.Preload("User", chatUserColumns).First(&message, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// GetMessagesByRoom - Lấy lịch sử chat của phòng (mới nhất trước)
+func (r *chatRepository) GetMessagesByRoom(roomID string, page, limit int) ([]models.ChatMessage, int64, error) {
+	var messages []models.ChatMessage
+	var total int64
+
+	query := r.db.Model(&models.ChatMessage{}).Where("room_id = ?", roomID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	err := query.Preload("User", chatUserColumns).
+		Order("created_at DESC").
+		Offset(offset).Limit(limit).
+		Find(&messages).Error
+
+	return messages, total, err
+}
+
+// CreateMessageWithSlowMode - Tạo tin nhắn nếu user đã chờ đủ slow-mode kể từ tin gần nhất trong phòng.
+// Khóa advisory theo user + phòng trong transaction để các request gửi đồng thời xếp hàng,
+// tránh cả 2 cùng đọc "chưa gửi gì" rồi cùng insert. Trả về thời gian còn phải chờ (> 0 thì không tạo).
+func (r *chatRepository) CreateMessageWithSlowMode(message *models.ChatMessage, slowMode time.Duration) (time.Duration, error) {
+	var wait time.Duration
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		lockKey := "chat_slow_mode:" + message.UserID.String() + ":" + message.RoomID
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", lockKey).Error; err != nil {
+			return err
+		}
+
+		var last models.ChatMessage
+		if err := tx.Select("created_at").
+			Where("user_id = ? AND room_id = ?", message.UserID, message.RoomID).
+			Order("created_at DESC").
+			Limit(1).
+			Find(&last).Error; err != nil {
+			return err
+		}
+		if !last.CreatedAt.IsZero() {
+			if wait = slowMode - time.Since(last.CreatedAt); wait > 0 {
+				return nil
+			}
+		}
+
+		return tx.Create(message).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return wait, nil
+}
+
+// DeleteMessage - Xóa tin nhắn
+func (r *chatRepository) DeleteMessage(id uuid.UUID) error {
+	return r.db.Delete(&models.ChatMessage{}, "id = ?", id).Error
+}
+
+// GetRoomSetting - Lấy cấu hình phòng (nil nếu chưa cấu hình)
+func (r *chatRepository) GetRoomSetting(roomID string) (*models.ChatRoomSetting, error) {
+	var setting models.ChatRoomSetting
+	err := r.db.Where("room_id = ?", roomID).Limit(1).Find(&setting).Error
+	if err != nil {
+		return nil, err
+	}
+	if setting.RoomID == "" {
+		return nil, nil
+	}
+	return &setting, nil
+}
+
+// SaveRoomSetting - Lưu cấu hình phòng
+func (r *chatRepository) SaveRoomSetting(setting *models.ChatRoomSetting) error {
+	return r.db.Save(setting).Error
+}
+
+// CreateMute - Cấm chat user
+func (r *chatRepository) CreateMute(mute *models.ChatMute) error {
+	return r.db.Create(mute).Error
+}
+
+// DeleteMute - Gỡ cấm chat
+func (r *chatRepository) DeleteMute(id uuid.UUID) error {
+	result := r.db.Delete(&models.ChatMute{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetActiveMute - Lấy lệnh cấm còn hiệu lực của user trong phòng (nil nếu không bị cấm)
+func (r *chatRepository) GetActiveMute(userID uuid.UUID, roomID string) (*models.ChatMute, error) {
+	var mutes []models.ChatMute
+	err := r.db.
+		Where("user_id = ? AND (room_id IS NULL OR room_id = ?)", userID, roomID).
+		Where("muted_until IS NULL OR muted_until > ?", time.Now()).
+		Order("muted_until DESC NULLS FIRST").
+		Limit(1).
+		Find(&mutes).Error
+	if err != nil || len(mutes) == 0 {
+		return nil, err
+	}
+	return &mutes[0], nil
+}
+
+// GetActiveMutes - Danh sách lệnh cấm còn hiệu lực (Admin)
+func (r *chatRepository) GetActiveMutes(page, limit int) ([]models.ChatMute, int64, error) {
+	var mutes []models.ChatMute
+	var total int64
+
+	query := r.db.Model(&models.ChatMute{}).Where("muted_until IS NULL OR muted_until > ?", time.Now())
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	err := query.Preload("User", chatUserColumns).
+		Order("created_at DESC").
+		Offset(offset).Limit(limit).
+		Find(&mutes).Error
+
+	return mutes, total, err
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"nekozanedex/internal/models"
+	"nekozanedex/pkg/pagination"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -14,9 +15,12 @@ type CommentRepository interface {
 	DeleteComment(id uuid.UUID) error
 	GetCommentsByStory(storyID uuid.UUID, page, limit int) ([]models.Comment, int64, error)
 	GetCommentsByChapter(chapterID uuid.UUID, page, limit int) ([]models.Comment, int64, error)
+	GetCommentsByStoryCursor(storyID uuid.UUID, cursor *pagination.Cursor, limit int) ([]models.Comment, pagination.Cursors, error)
+	GetCommentsByChapterCursor(chapterID uuid.UUID, cursor *pagination.Cursor, limit int) ([]models.Comment, pagination.Cursors, error)
 	GetCommentReplies(parentID uuid.UUID) ([]models.Comment, error)
 	UpdateLikeCount(commentID uuid.UUID, count int) error
 	TogglePin(commentID uuid.UUID, isPinned bool) error
+	CountCommentsByBucket(r AnalyticsRange) ([]CountBucket, error)
 }
 
 type commentRepository struct {
@@ -71,6 +75,33 @@ func (r *commentRepository) GetCommentsByStory(storyID uuid.UUID, page, limit in
 	return comments, total, err
 }
 
+// GetCommentsByStoryCursor - Như GetCommentsByStory nhưng phân trang keyset theo (is_pinned, created_at, id)
+// Comment mới đến không làm lệch trang đang cuộn
+func (r *commentRepository) GetCommentsByStoryCursor(storyID uuid.UUID, cursor *pagination.Cursor, limit int) ([]models.Comment, pagination.Cursors, error) {
+	query := r.db.Where("story_id = ? AND chapter_id IS NULL AND parent_id IS NULL AND is_approved = ?", storyID, true)
+	return r.getCommentsCursor(query, cursor, limit)
+}
+
+// GetCommentsByChapterCursor - Như GetCommentsByChapter nhưng phân trang keyset
+func (r *commentRepository) GetCommentsByChapterCursor(chapterID uuid.UUID, cursor *pagination.Cursor, limit int) ([]models.Comment, pagination.Cursors, error) {
+	query := r.db.Where("chapter_id = ? AND parent_id IS NULL AND is_approved = ?", chapterID, true)
+	return r.getCommentsCursor(query, cursor, limit)
+}
+
+func (r *commentRepository) getCommentsCursor(query *gorm.DB, cursor *pagination.Cursor, limit int) ([]models.Comment, pagination.Cursors, error) {
+	var comments []models.Comment
+	err := keyset(query.Preload("User").Preload("Replies.User"), cursor, limit, "is_pinned", "created_at", "id").
+		Find(&comments).Error
+	if err != nil {
+		return nil, pagination.Cursors{}, err
+	}
+
+	comments, cursors := pagination.Page(comments, cursor, limit, func(c *models.Comment) pagination.Cursor {
+		return pagination.Cursor{Pinned: c.IsPinned, Time: c.CreatedAt, ID: c.ID}
+	})
+	return comments, cursors, nil
+}
+
 // GetCommentsByChapter - Lấy Comments theo Chapter
 func (r *commentRepository) GetCommentsByChapter(chapterID uuid.UUID, page, limit int) ([]models.Comment, int64, error) {
 	var comments []models.Comment
@@ -110,3 +141,16 @@ func (r *commentRepository) TogglePin(commentID uuid.UUID, isPinned bool) error
 	return r.db.Model(&models.Comment{}).Where("id = ?", commentID).Update("is_pinned", isPinned).Error
 }
 
+// CountCommentsByBucket - Số comment mới theo bucket (không tính comment đã xóa)
+func (r *commentRepository) CountCommentsByBucket(ar AnalyticsRange) ([]CountBucket, error) {
+	query := r.db.Model(&models.Comment{}).
+		Select(analyticsBucketExpr("created_at")+" AS bucket, COUNT(*) AS count", ar.Bucket).
+		Where("created_at >= ? AND created_at < ?", ar.From.Format("2006-01-02"), ar.To.Format("2006-01-02"))
+	if ar.StoryID != nil {
+		query = query.Where("story_id = ?", *ar.StoryID)
+	}
+
+	var buckets []CountBucket
+	err := query.Group("bucket").Order("bucket").Scan(&buckets).Error
+	return buckets, err
+}
@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"nekozanedex/pkg/pagination"
+
+	"gorm.io/gorm"
+)
+
+// keyset - Thay OFFSET bằng điều kiện so sánh tuple (pinned, time, id) với cursor.
+// Danh sách sort giảm dần; cursor backward thì đảo chiều, pagination.Page đảo lại kết quả.
+// pinnedColumn rỗng nếu danh sách không có ghim. Lấy limit+1 dòng để biết còn trang hay không.
+func keyset(query *gorm.DB, cursor *pagination.Cursor, limit int, pinnedColumn, timeColumn, idColumn string) *gorm.DB {
+	direction, op := "DESC", "<"
+	if cursor != nil && cursor.Backward {
+		direction, op = "ASC", ">"
+	}
+
+	if cursor != nil {
+		if pinnedColumn != "" {
+			query = query.Where("("+pinnedColumn+", "+timeColumn+", "+idColumn+") "+op+" (?, ?, ?)", cursor.Pinned, cursor.Time, cursor.ID)
+		} else {
+			query = query.Where("("+timeColumn+", "+idColumn+") "+op+" (?, ?)", cursor.Time, cursor.ID)
+		}
+	}
+	if pinnedColumn != "" {
+		query = query.Order(pinnedColumn + " " + direction)
+	}
+	return query.Order(timeColumn + " " + direction).Order(idColumn + " " + direction).Limit(limit + 1)
+}
@@ -0,0 +1,155 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"nekozanedex/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// mediaReferencesSQL - Mọi URL ảnh đang được dùng. Entity đã soft delete không còn giữ ảnh,
+// revision chỉ giữ ảnh khi chapter còn (restore revision cần ảnh cũ).
+const mediaReferencesSQL = `
+	SELECT cover_image_url AS url FROM stories WHERE deleted_at IS NULL AND cover_image_url IS NOT NULL
+	UNION ALL
+	SELECT cover_image_url FROM volumes WHERE cover_image_url IS NOT NULL
+	UNION ALL
+	SELECT avatar_url FROM users WHERE deleted_at IS NULL AND avatar_url IS NOT NULL
+	UNION ALL
+	SELECT jsonb_array_elements_text(images) FROM chapters
+	WHERE deleted_at IS NULL AND jsonb_typeof(images) = 'array'
+	UNION ALL
+	SELECT jsonb_array_elements_text(r.images) FROM chapter_revisions r
+	JOIN chapters c ON c.id = r.chapter_id AND c.deleted_at IS NULL
+	WHERE jsonb_typeof(r.images) = 'array'`
+
+// mediaContentReferencedCond - Asset m được chèn trong nội dung chapter hoặc nội dung revision của chapter còn sống
+// (restore revision sẽ đưa ảnh trở lại). Chỉ check được bằng strpos nên chỉ chạy trên ít dòng.
+const mediaContentReferencedCond = `(
+	EXISTS (
+		SELECT 1 FROM chapters c
+		WHERE c.deleted_at IS NULL AND c.content <> '' AND strpos(c.content, m.url) > 0
+	)
+	OR EXISTS (
+		SELECT 1 FROM chapter_revisions r
+		JOIN chapters c ON c.id = r.chapter_id AND c.deleted_at IS NULL
+		WHERE r.content <> '' AND strpos(r.content, m.url) > 0
+	))`
+
+// mediaOrphansCTE - CTE "orphans": asset của @driver không còn được tham chiếu.
+// Tập tham chiếu được dựng 1 lần cho cả câu query rồi anti-join (hash) với media_assets,
+// strpos trên nội dung chapter/revision chỉ chạy cho các asset còn lại sau bước đó.
+const mediaOrphansCTE = `
+	WITH refs AS MATERIALIZED (` + mediaReferencesSQL + `),
+	candidates AS MATERIALIZED (
+		SELECT m.* FROM media_assets m
+		WHERE m.driver = @driver AND NOT EXISTS (SELECT 1 FROM refs WHERE refs.url = m.url)
+	),
+	orphans AS (
+		SELECT m.* FROM candidates m WHERE NOT ` + mediaContentReferencedCond + `
+	)`
+
+// MediaUnreferencedStats - Tổng hợp asset mồ côi của 1 driver (không bị giới hạn bởi batch)
+type MediaUnreferencedStats struct {
+	Orphaned int64
+	Due      int64 // Đã mồ côi trước mốc dueBefore
+	DueBytes int64
+}
+
+type MediaAssetRepository interface {
+	Create(asset *models.MediaAsset) error
+	CreateIfMissing(assets []models.MediaAsset) (int64, error)
+	FindUnreferenced(driver string, limit int) ([]models.MediaAsset, error)
+	UnreferencedStats(driver string, dueBefore time.Time) (*MediaUnreferencedStats, error)
+	FindUntrackedReferences(afterURL string, limit int) ([]string, error)
+	ClearReferenced() (int64, error)
+	MarkUnreferenced(ids []uuid.UUID, at time.Time) error
+	Delete(id uuid.UUID) error
+}
+
+type mediaAssetRepository struct {
+	db *gorm.DB
+}
+
+func NewMediaAssetRepository(db *gorm.DB) MediaAssetRepository {
+	return &mediaAssetRepository{db: db}
+}
+
+func (r *mediaAssetRepository) Create(asset *models.MediaAsset) error {
+	return r.db.Create(asset).Error
+}
+
+// CreateIfMissing - Thêm asset, bỏ qua URL đã được track (backfill chạy lại nhiều lần vẫn an toàn)
+func (r *mediaAssetRepository) CreateIfMissing(assets []models.MediaAsset) (int64, error) {
+	if len(assets) == 0 {
+		return 0, nil
+	}
+	result := r.db.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "url"}}, DoNothing: true}).Create(&assets)
+	return result.RowsAffected, result.Error
+}
+
+// FindUnreferenced - Asset của driver không còn được tham chiếu, asset đã bị đánh dấu lâu nhất lên trước.
+// File mới upload chưa kịp gắn vào entity cũng nằm trong đây, grace period tính từ lúc bị đánh dấu.
+func (r *mediaAssetRepository) FindUnreferenced(driver string, limit int) ([]models.MediaAsset, error) {
+	var assets []models.MediaAsset
+	err := r.db.Raw(mediaOrphansCTE+`
+		SELECT * FROM orphans
+		ORDER BY unreferenced_at ASC NULLS LAST, created_at ASC
+		LIMIT @limit`, sql.Named("driver", driver), sql.Named("limit", limit)).
+		Scan(&assets).Error
+	return assets, err
+}
+
+// UnreferencedStats - Đếm toàn bộ asset mồ côi của driver và phần đã mồ côi trước dueBefore (1 lượt qua orphans)
+func (r *mediaAssetRepository) UnreferencedStats(driver string, dueBefore time.Time) (*MediaUnreferencedStats, error) {
+	var stats MediaUnreferencedStats
+	err := r.db.Raw(mediaOrphansCTE+`
+		SELECT COUNT(*) AS orphaned,
+			COUNT(*) FILTER (WHERE unreferenced_at <= @due) AS due,
+			COALESCE(SUM(size) FILTER (WHERE unreferenced_at <= @due), 0) AS due_bytes
+		FROM orphans`, sql.Named("driver", driver), sql.Named("due", dueBefore)).
+		Scan(&stats).Error
+	return &stats, err
+}
+
+// FindUntrackedReferences - URL đang được tham chiếu nhưng chưa có trong media_assets (file upload trước khi có bảng này).
+// Phân trang theo URL tăng dần, afterURL = "" là trang đầu.
+func (r *mediaAssetRepository) FindUntrackedReferences(afterURL string, limit int) ([]string, error) {
+	var urls []string
+	err := r.db.Raw(`
+		SELECT DISTINCT refs.url FROM (`+mediaReferencesSQL+`) refs
+		WHERE refs.url > ? AND NOT EXISTS (SELECT 1 FROM media_assets m WHERE m.url = refs.url)
+		ORDER BY refs.url
+		LIMIT ?`, afterURL, limit).
+		Scan(&urls).Error
+	return urls, err
+}
+
+// ClearReferenced - Bỏ đánh dấu asset đã được dùng lại (vd: restore revision, gắn lại cover)
+// Chỉ xét asset đang bị đánh dấu nên strpos nội dung chạy trên ít dòng
+func (r *mediaAssetRepository) ClearReferenced() (int64, error) {
+	result := r.db.Exec(`
+		WITH refs AS MATERIALIZED (` + mediaReferencesSQL + `)
+		UPDATE media_assets m SET unreferenced_at = NULL
+		WHERE m.unreferenced_at IS NOT NULL
+			AND (EXISTS (SELECT 1 FROM refs WHERE refs.url = m.url) OR ` + mediaContentReferencedCond + `)`)
+	return result.RowsAffected, result.Error
+}
+
+// MarkUnreferenced - Đánh dấu thời điểm đầu tiên thấy asset mồ côi (giữ nguyên nếu đã đánh dấu)
+func (r *mediaAssetRepository) MarkUnreferenced(ids []uuid.UUID, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Model(&models.MediaAsset{}).
+		Where("id IN ? AND unreferenced_at IS NULL", ids).
+		Update("unreferenced_at", at).Error
+}
+
+func (r *mediaAssetRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.MediaAsset{}, "id = ?", id).Error
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"nekozanedex/internal/models"
+	"nekozanedex/pkg/pagination"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -9,8 +10,10 @@ import (
 
 type NotificationRepository interface {
 	CreateNotification(notification *models.Notification) error
+	CreateNotifications(notifications []models.Notification) error
 	FindNotificationByID(id uuid.UUID) (*models.Notification, error)
 	GetNotificationsByUser(userID uuid.UUID, page, limit int) ([]models.Notification, int64, error)
+	GetNotificationsByUserCursor(userID uuid.UUID, cursor *pagination.Cursor, limit int) ([]models.Notification, pagination.Cursors, error)
 	MarkNotificationAsRead(id uuid.UUID) error
 	MarkAllNotificationsAsRead(userID uuid.UUID) error
 	GetUnreadNotificationCount(userID uuid.UUID) int64
@@ -30,6 +33,14 @@ func (r *notificationRepository) CreateNotification(notification *models.Notific
 	return r.db.Create(notification).Error
 }
 
+// CreateNotifications - Tạo nhiều Notification trong 1 lần (multi-row INSERT)
+func (r *notificationRepository) CreateNotifications(notifications []models.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(notifications, 500).Error
+}
+
 // FindNotificationByID - Tìm Notification theo ID
 func (r *notificationRepository) FindNotificationByID(id uuid.UUID) (*models.Notification, error) {
 	var notification models.Notification
@@ -56,6 +67,21 @@ func (r *notificationRepository) GetNotificationsByUser(userID uuid.UUID, page,
 	return notifications, total, err
 }
 
+// GetNotificationsByUserCursor - Như GetNotificationsByUser nhưng phân trang keyset theo (created_at, id)
+func (r *notificationRepository) GetNotificationsByUserCursor(userID uuid.UUID, cursor *pagination.Cursor, limit int) ([]models.Notification, pagination.Cursors, error) {
+	var notifications []models.Notification
+	err := keyset(r.db.Where("user_id = ?", userID), cursor, limit, "", "created_at", "id").
+		Find(&notifications).Error
+	if err != nil {
+		return nil, pagination.Cursors{}, err
+	}
+
+	notifications, cursors := pagination.Page(notifications, cursor, limit, func(n *models.Notification) pagination.Cursor {
+		return pagination.Cursor{Time: n.CreatedAt, ID: n.ID}
+	})
+	return notifications, cursors, nil
+}
+
 // MarkNotificationAsRead - Đánh dấu đã đọc
 func (r *notificationRepository) MarkNotificationAsRead(id uuid.UUID) error {
 	return r.db.Model(&models.Notification{}).Where("id = ?", id).
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"nekozanedex/internal/models"
+	"nekozanedex/pkg/pagination"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -11,6 +12,7 @@ import (
 type ReadingHistoryRepository interface {
 	Upsert(history *models.ReadingHistory) error
 	GetByUser(userID uuid.UUID, page, limit int) ([]models.ReadingHistory, int64, error)
+	GetByUserCursor(userID uuid.UUID, cursor *pagination.Cursor, limit int) ([]models.ReadingHistory, pagination.Cursors, error)
 	GetContinueReading(userID uuid.UUID, limit int) ([]models.ReadingHistory, error)
 	GetByUserAndStory(userID, storyID uuid.UUID) (*models.ReadingHistory, error)
 	DeleteByStory(userID, storyID uuid.UUID) error
@@ -54,6 +56,23 @@ func (r *readingHistoryRepository) GetByUser(userID uuid.UUID, page, limit int)
 	return histories, total, nil
 }
 
+// GetByUserCursor - Như GetByUser nhưng phân trang keyset theo (last_read_at, id)
+func (r *readingHistoryRepository) GetByUserCursor(userID uuid.UUID, cursor *pagination.Cursor, limit int) ([]models.ReadingHistory, pagination.Cursors, error) {
+	var histories []models.ReadingHistory
+	query := r.db.
+		Where("user_id = ?", userID).
+		Preload("Story").
+		Preload("Chapter")
+	if err := keyset(query, cursor, limit, "", "last_read_at", "id").Find(&histories).Error; err != nil {
+		return nil, pagination.Cursors{}, err
+	}
+
+	histories, cursors := pagination.Page(histories, cursor, limit, func(h *models.ReadingHistory) pagination.Cursor {
+		return pagination.Cursor{Time: h.LastReadAt, ID: h.ID}
+	})
+	return histories, cursors, nil
+}
+
 func (r *readingHistoryRepository) GetContinueReading(userID uuid.UUID, limit int) ([]models.ReadingHistory, error) {
 	var histories []models.ReadingHistory
 	err := r.db.
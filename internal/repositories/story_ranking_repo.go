@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"time"
+
+	"nekozanedex/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RankingScore - Điểm trending của 1 truyện trong 1 cửa sổ thời gian
+type RankingScore struct {
+	StoryID uuid.UUID
+	Views   int64
+	Score   float64
+}
+
+type StoryRankingRepository interface {
+	ComputeScores(end time.Time, windowDays int, halfLifeDays float64, limit int) ([]RankingScore, error)
+	ReplaceRankings(period string, rankings []models.StoryRanking) error
+	GetRankings(period string, limit int) ([]models.StoryRanking, error)
+}
+
+type storyRankingRepository struct {
+	db *gorm.DB
+}
+
+func NewStoryRankingRepository(db *gorm.DB) StoryRankingRepository {
+	return &storyRankingRepository{db: db}
+}
+
+// ComputeScores - Tính điểm trending từ story_views trong windowDays ngày kết thúc tại end
+// score = SUM(views * 2^(-tuổi/halfLife)), tuổi tính theo ngày so với end => lượt xem cũ giảm dần trọng số
+// Chỉ tính truyện đã xuất bản, chưa bị xóa mềm; kết quả đã sắp xếp theo điểm giảm dần
+func (r *storyRankingRepository) ComputeScores(end time.Time, windowDays int, halfLifeDays float64, limit int) ([]RankingScore, error) {
+	endDate := end.Format("2006-01-02")
+	var scores []RankingScore
+	err := r.db.Table("story_views AS sv").
+		Select("sv.story_id, SUM(sv.view_count) AS views, "+
+			"SUM(sv.view_count * POWER(2, -(?::date - sv.viewed_at)::float8 / ?)) AS score", endDate, halfLifeDays).
+		Joins("JOIN stories ON stories.id = sv.story_id AND stories.is_published = ? AND stories.deleted_at IS NULL", true).
+		Where("sv.viewed_at > ?::date - ?::int AND sv.viewed_at <= ?::date", endDate, windowDays, endDate).
+		Group("sv.story_id").
+		Order("score DESC, views DESC, sv.story_id").
+		Limit(limit).
+		Scan(&scores).Error
+	return scores, err
+}
+
+// ReplaceRankings - Thay toàn bộ bảng xếp hạng của 1 kỳ trong 1 transaction
+// Người đọc không bao giờ thấy bảng rỗng hoặc trộn lẫn 2 lần tính
+func (r *storyRankingRepository) ReplaceRankings(period string, rankings []models.StoryRanking) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("period = ?", period).Delete(&models.StoryRanking{}).Error; err != nil {
+			return err
+		}
+		if len(rankings) == 0 {
+			return nil
+		}
+		return tx.Omit("Story").CreateInBatches(rankings, 100).Error
+	})
+}
+
+// GetRankings - Lấy bảng xếp hạng đã tính sẵn kèm thông tin truyện
+func (r *storyRankingRepository) GetRankings(period string, limit int) ([]models.StoryRanking, error) {
+	var rankings []models.StoryRanking
+	err := r.db.
+		Preload("Story").
+		Preload("Story.Genres").
+		Joins("JOIN stories ON stories.id = story_rankings.story_id AND stories.is_published = ? AND stories.deleted_at IS NULL", true).
+		Where("story_rankings.period = ?", period).
+		Order("story_rankings.rank ASC").
+		Limit(limit).
+		Find(&rankings).Error
+	return rankings, err
+}
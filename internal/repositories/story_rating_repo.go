@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"nekozanedex/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StoryRatingRepository interface {
+	UpsertRating(rating *models.StoryRating) error
+	DeleteRating(userID, storyID uuid.UUID) error
+	FindRatingByUserAndStory(userID, storyID uuid.UUID) (*models.StoryRating, error)
+	GetRatingDistribution(storyID uuid.UUID) (map[int]int64, error)
+}
+
+type storyRatingRepository struct {
+	db *gorm.DB
+}
+
+func NewStoryRatingRepository(db *gorm.DB) StoryRatingRepository {
+	return &storyRatingRepository{db: db}
+}
+
+// UpsertRating - Tạo hoặc cập nhật đánh giá, đồng thời tính lại rating của truyện
+func (r *storyRatingRepository) UpsertRating(rating *models.StoryRating) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockStory(tx, rating.StoryID); err != nil {
+			return err
+		}
+
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "story_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"score", "updated_at"}),
+		}).Create(rating).Error; err != nil {
+			return err
+		}
+
+		return recomputeStoryRating(tx, rating.StoryID)
+	})
+}
+
+// DeleteRating - Rút lại đánh giá, đồng thời tính lại rating của truyện
+func (r *storyRatingRepository) DeleteRating(userID, storyID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockStory(tx, storyID); err != nil {
+			return err
+		}
+
+		result := tx.Where("user_id = ? AND story_id = ?", userID, storyID).Delete(&models.StoryRating{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return recomputeStoryRating(tx, storyID)
+	})
+}
+
+// FindRatingByUserAndStory - Lấy đánh giá của user cho truyện
+func (r *storyRatingRepository) FindRatingByUserAndStory(userID, storyID uuid.UUID) (*models.StoryRating, error) {
+	var rating models.StoryRating
+	err := r.db.First(&rating, "user_id = ? AND story_id = ?", userID, storyID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rating, nil
+}
+
+// GetRatingDistribution - Đếm số lượt đánh giá theo từng mức sao (luôn đủ 1-5)
+func (r *storyRatingRepository) GetRatingDistribution(storyID uuid.UUID) (map[int]int64, error) {
+	var rows []struct {
+		Score int
+		Count int64
+	}
+	err := r.db.Model(&models.StoryRating{}).
+		Select("score, COUNT(*) AS count").
+		Where("story_id = ?", storyID).
+		Group("score").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	distribution := map[int]int64{1: 0, 2: 0, 3: 0, 4: 0, 5: 0}
+	for _, row := range rows {
+		distribution[row.Score] = row.Count
+	}
+	return distribution, nil
+}
+
+// lockStory - Khóa row của truyện để các lượt đánh giá đồng thời được tính lần lượt
+func lockStory(tx *gorm.DB, storyID uuid.UUID) error {
+	var story models.Story
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		First(&story, "id = ?", storyID).Error
+}
+
+// recomputeStoryRating - Tính lại stories.rating và stories.rating_count từ story_ratings
+func recomputeStoryRating(tx *gorm.DB, storyID uuid.UUID) error {
+	return tx.Exec(`
+		UPDATE stories SET
+			rating = agg.avg_score,
+			rating_count = agg.total
+		FROM (
+			SELECT ROUND(AVG(score)::numeric, 2) AS avg_score, COUNT(*) AS total
+			FROM story_ratings
+			WHERE story_id = ?
+		) AS agg
+		WHERE stories.id = ?`, storyID, storyID).Error
+}
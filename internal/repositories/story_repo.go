@@ -1,26 +1,81 @@
 package repositories
 
 import (
+	"database/sql"
+	"strings"
+	"time"
+	"unicode"
+
+	"nekozanedex/internal/database"
 	"nekozanedex/internal/models"
+	"nekozanedex/pkg/pagination"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// StoryBrowseFilter - Bộ lọc cho trang duyệt truyện (faceted browse)
+type StoryBrowseFilter struct {
+	IncludeGenres []string // Genre slugs - truyện phải có TẤT CẢ
+	ExcludeGenres []string // Genre slugs - truyện không được có BẤT KỲ
+	Status        string
+	Country       string
+	YearFrom      *int
+	YearTo        *int
+	MinChapters   int
+	MinRating     *float64
+	Sort          string // updated, views, rating, title, created
+}
+
+// GenreFacet - Số truyện theo từng thể loại trong tập kết quả
+type GenreFacet struct {
+	ID    uuid.UUID `json:"id"`
+	Name  string    `json:"name"`
+	Slug  string    `json:"slug"`
+	Count int64     `json:"count"`
+}
+
+// StatusFacet - Số truyện theo từng trạng thái
+type StatusFacet struct {
+	Status string `json:"status"`
+	Count  int64  `json:"count"`
+}
+
+// StoryFacets - Facet counts để frontend render filter chips
+type StoryFacets struct {
+	Genres   []GenreFacet  `json:"genres"`
+	Statuses []StatusFacet `json:"statuses"`
+}
+
+// SitemapEntry - 1 URL trong sitemap: slug + thời điểm cập nhật cuối
+type SitemapEntry struct {
+	Slug      string
+	UpdatedAt time.Time
+}
+
 type StoryRepository interface{
 	CreateStory(story *models.Story) error
 	FindStoryByID(id uuid.UUID) (*models.Story,error)
 	FindStoryBySlug(slug string) (*models.Story,error)
+	FindStoryDetailBySlug(slug string) (*models.Story, error)
+	FindPublishedStoryBySlugLite(slug string) (*models.Story, error)
 	UpdateStory(story *models.Story) error
 	UpdateStoryGenres(storyID uuid.UUID, genreIDs []uuid.UUID) error
 	DeleteStory(id uuid.UUID) error
 	GetAllStories(page, limit int, published bool) ([]models.Story, int64, error)
+	GetAllStoriesCursor(cursor *pagination.Cursor, limit int, published bool) ([]models.Story, pagination.Cursors, error)
 	GetStoriesByGenre(genreID uuid.UUID, page, limit int) ([]models.Story, int64, error)
 	GetStoriesLatest(limit int) ([]models.Story, error)
 	GetStoriesHot(limit int) ([]models.Story, error)
+	GetStoriesTopRated(limit, minVotes int) ([]models.Story, error)
 	SearchStories(query string, page, limit int) ([]models.Story, int64, error)
+	BrowseStories(filter StoryBrowseFilter, page, limit int) ([]models.Story, int64, error)
+	GetBrowseFacets(filter StoryBrowseFilter) (*StoryFacets, error)
 	SearchStoriesAdmin(query string, page, limit int) ([]models.Story, int64, error)
-	IncrementViewCountStory(id uuid.UUID) error
+	CountPublishedStories() (int64, error)
+	GetPublishedStorySitemap(offset, limit int) ([]SitemapEntry, error)
+	GetGenreSitemap() ([]SitemapEntry, error)
 }
 
 type storyRepository struct {
@@ -33,7 +88,12 @@ func NewStoryRepository(db *gorm.DB) StoryRepository{
 
 //Create Story - Tạo Story
 func (r *storyRepository) CreateStory(story *models.Story) error{
-	return r.db.Create(story).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(story).Error; err != nil {
+			return err
+		}
+		return refreshSearchVector(tx, story.ID)
+	})
 }
 
 //Find Story By ID - Tìm Story theo ID
@@ -51,7 +111,7 @@ func (r *storyRepository) FindStoryByID(id uuid.UUID) (*models.Story, error){
 func (r *storyRepository) FindStoryBySlug(slug string) (*models.Story, error) {
 	var story models.Story
 	err := r.db.Preload("Genres").Preload("Chapters", func(db *gorm.DB) *gorm.DB {
-		return db.Where("is_published = ?", true).Order("chapter_number ASC")
+		return db.Where("is_published = ?", true).Order(chapterSortKey + " ASC, chapter_number ASC")
 	}).First(&story, "slug = ? AND is_published = ?", slug, true).Error
 	if err != nil {
 		return nil, err
@@ -59,9 +119,42 @@ func (r *storyRepository) FindStoryBySlug(slug string) (*models.Story, error) {
 	return &story, nil
 }
 
+// FindStoryDetailBySlug - Như FindStoryBySlug nhưng chapters chỉ là danh sách (không content/images), dùng cho trang chi tiết
+func (r *storyRepository) FindStoryDetailBySlug(slug string) (*models.Story, error) {
+	var story models.Story
+	err := r.db.Preload("Genres").Preload("Chapters", func(db *gorm.DB) *gorm.DB {
+		return db.Omit(chapterListOmit...).Where("is_published = ?", true).Order(chapterSortKey + " ASC, chapter_number ASC")
+	}).First(&story, "slug = ? AND is_published = ?", slug, true).Error
+	if err != nil {
+		return nil, err
+	}
+	return &story, nil
+}
+
+// FindPublishedStoryBySlugLite - Tìm truyện đã xuất bản theo slug, không preload chapters (feed, sitemap...)
+func (r *storyRepository) FindPublishedStoryBySlugLite(slug string) (*models.Story, error) {
+	var story models.Story
+	err := r.db.First(&story, "slug = ? AND is_published = ?", slug, true).Error
+	if err != nil {
+		return nil, err
+	}
+	return &story, nil
+}
+
 //Update Story - Cập Nhật Story
 func (r *storyRepository) UpdateStory(story *models.Story) error{
-	return r.db.Save(story).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// rating/rating_count chỉ được tính lại bởi StoryRatingRepository
+		if err := tx.Omit("Rating", "RatingCount").Save(story).Error; err != nil {
+			return err
+		}
+		return refreshSearchVector(tx, story.ID)
+	})
+}
+
+// refreshSearchVector - Cập nhật lại search_vector (full-text index) của Story
+func refreshSearchVector(tx *gorm.DB, storyID uuid.UUID) error {
+	return tx.Exec("UPDATE stories SET search_vector = "+database.StorySearchVectorSQL+" WHERE id = ?", storyID).Error
 }
 
 // UpdateStoryGenres - Cập nhật thể loại của Story
@@ -103,6 +196,23 @@ func (r *storyRepository) GetAllStories(page, limit int, published bool) ([]mode
 	return stories, total, err
 }
 
+// GetAllStoriesCursor - Như GetAllStories nhưng phân trang keyset theo (updated_at, id)
+func (r *storyRepository) GetAllStoriesCursor(cursor *pagination.Cursor, limit int, published bool) ([]models.Story, pagination.Cursors, error) {
+	var stories []models.Story
+	query := r.db.Preload("Genres")
+	if published {
+		query = query.Where("is_published = ?", true)
+	}
+	if err := keyset(query, cursor, limit, "", "updated_at", "id").Find(&stories).Error; err != nil {
+		return nil, pagination.Cursors{}, err
+	}
+
+	stories, cursors := pagination.Page(stories, cursor, limit, func(s *models.Story) pagination.Cursor {
+		return pagination.Cursor{Time: s.UpdatedAt, ID: s.ID}
+	})
+	return stories, cursors, nil
+}
+
 
 //Get Stories By Genre - Lấy Story Theo Thể Loại
 func (r *storyRepository) GetStoriesByGenre(genreID uuid.UUID, page, limit int) ([]models.Story, int64, error) {
@@ -133,36 +243,221 @@ func (r *storyRepository) GetStoriesHot(limit int) ([]models.Story, error) {
 	return stories, err
 }
 
-//Search Stories - Tìm kiếm Story
+//Get Stories Top Rated - Lấy Story được đánh giá cao (Bayesian average)
+// weighted = (v*R + m*C) / (v + m), v = rating_count, R = rating, C = điểm trung bình toàn site, m = minVotes
+func (r *storyRepository) GetStoriesTopRated(limit, minVotes int) ([]models.Story, error) {
+	var globalMean float64
+	err := r.db.Model(&models.Story{}).
+		Select("COALESCE(SUM(rating * rating_count) / NULLIF(SUM(rating_count), 0), 0)").
+		Where("is_published = ? AND rating_count > 0", true).
+		Scan(&globalMean).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var stories []models.Story
+	err = r.db.Preload("Genres").
+		Where("is_published = ? AND rating_count >= ? AND rating_count > 0", true, minVotes).
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "(rating_count * rating + ?::numeric * ?::numeric) / (rating_count + ?::int) DESC, rating_count DESC",
+			Vars:               []interface{}{minVotes, globalMean, minVotes},
+			WithoutParentheses: true,
+		}}).
+		Limit(limit).Find(&stories).Error
+	return stories, err
+}
+
+//Search Stories - Tìm kiếm Story (full-text, sắp xếp theo độ liên quan)
 func (r *storyRepository) SearchStories(query string, page, limit int) ([]models.Story, int64, error) {
+	return r.searchStories(query, page, limit, true)
+}
+
+//Browse Stories - Duyệt truyện với bộ lọc nhiều điều kiện
+func (r *storyRepository) BrowseStories(filter StoryBrowseFilter, page, limit int) ([]models.Story, int64, error) {
 	var stories []models.Story
 	var total int64
-	searchQuery := "%" + query + "%"
-	
-	r.db.Model(&models.Story{}).Where("is_published = ? AND (title ILIKE ? OR description ILIKE ?)", 
-		true, searchQuery, searchQuery).Count(&total)
+
+	query := applyBrowseFilter(r.db.Model(&models.Story{}), filter, true)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
 	offset := (page - 1) * limit
-	err := r.db.Preload("Genres").Where("is_published = ? AND (title ILIKE ? OR description ILIKE ?)", 
-		true, searchQuery, searchQuery).Offset(offset).Limit(limit).Find(&stories).Error
+	err := query.Preload("Genres").
+		Order(browseSortOrder(filter.Sort)).
+		Offset(offset).Limit(limit).
+		Find(&stories).Error
 	return stories, total, err
 }
 
-//Increment View Count Story - Tăng Lượt Xem Story
-func (r *storyRepository) IncrementViewCountStory(id uuid.UUID) error {
-	return r.db.Model(&models.Story{}).Where("id = ?", id).
-		UpdateColumn("view_count", gorm.Expr("view_count + 1")).Error
+//Get Browse Facets - Đếm số truyện theo thể loại và trạng thái cho bộ lọc hiện tại
+// Facet trạng thái bỏ qua bộ lọc status để các chip trạng thái khác vẫn có số lượng
+func (r *storyRepository) GetBrowseFacets(filter StoryBrowseFilter) (*StoryFacets, error) {
+	facets := &StoryFacets{
+		Genres:   []GenreFacet{},
+		Statuses: []StatusFacet{},
+	}
+
+	matched := applyBrowseFilter(r.db.Model(&models.Story{}).Select("stories.id"), filter, true)
+	err := r.db.Table("genres").
+		Select("genres.id, genres.name, genres.slug, COUNT(story_genres.story_id) AS count").
+		Joins("JOIN story_genres ON story_genres.genre_id = genres.id").
+		Where("story_genres.story_id IN (?)", matched).
+		Group("genres.id, genres.name, genres.slug").
+		Order("count DESC, genres.name ASC").
+		Scan(&facets.Genres).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = applyBrowseFilter(r.db.Model(&models.Story{}), filter, false).
+		Select("status, COUNT(*) AS count").
+		Group("status").
+		Order("count DESC").
+		Scan(&facets.Statuses).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return facets, nil
+}
+
+// applyBrowseFilter - Áp dụng bộ lọc browse (chỉ truyện đã publish)
+func applyBrowseFilter(query *gorm.DB, filter StoryBrowseFilter, withStatus bool) *gorm.DB {
+	query = query.Where("stories.is_published = ?", true)
+
+	if len(filter.IncludeGenres) > 0 {
+		// So HAVING với số genre thực sự tồn tại (không phải số slug gửi lên) để slug lặp/không tồn tại
+		// không làm rỗng kết quả; nếu không slug nào tồn tại thì bỏ qua bộ lọc
+		query = query.Where(`(stories.id IN (
+			SELECT sg.story_id FROM story_genres sg
+			JOIN genres g ON g.id = sg.genre_id
+			WHERE g.slug IN @slugs
+			GROUP BY sg.story_id
+			HAVING COUNT(DISTINCT g.id) = (SELECT COUNT(*) FROM genres WHERE slug IN @slugs))
+			OR NOT EXISTS (SELECT 1 FROM genres WHERE slug IN @slugs))`, sql.Named("slugs", filter.IncludeGenres))
+	}
+	if len(filter.ExcludeGenres) > 0 {
+		query = query.Where(`stories.id NOT IN (
+			SELECT sg.story_id FROM story_genres sg
+			JOIN genres g ON g.id = sg.genre_id
+			WHERE g.slug IN ?)`, filter.ExcludeGenres)
+	}
+	if withStatus && filter.Status != "" {
+		query = query.Where("stories.status = ?", filter.Status)
+	}
+	if filter.Country != "" {
+		query = query.Where("stories.country = ?", filter.Country)
+	}
+	if filter.YearFrom != nil {
+		query = query.Where("stories.release_year >= ?", *filter.YearFrom)
+	}
+	if filter.YearTo != nil {
+		query = query.Where("stories.release_year <= ?", *filter.YearTo)
+	}
+	if filter.MinChapters > 0 {
+		query = query.Where("stories.total_chapters >= ?", filter.MinChapters)
+	}
+	if filter.MinRating != nil {
+		query = query.Where("stories.rating >= ?", *filter.MinRating)
+	}
+	return query
+}
+
+// browseSortOrder - Map tham số sort sang ORDER BY (mặc định: mới cập nhật)
+func browseSortOrder(sort string) string {
+	switch sort {
+	case "views":
+		return "stories.view_count DESC, stories.updated_at DESC"
+	case "rating":
+		return "stories.rating DESC NULLS LAST, stories.rating_count DESC"
+	case "title":
+		return "stories.title ASC"
+	case "created":
+		return "stories.created_at DESC"
+	default:
+		return "stories.updated_at DESC"
+	}
 }
 
 //SearchStoriesAdmin - Admin search (includes drafts)
 func (r *storyRepository) SearchStoriesAdmin(query string, page, limit int) ([]models.Story, int64, error) {
+	return r.searchStories(query, page, limit, false)
+}
+
+// searchStories - Tìm kiếm qua search_vector, xếp hạng bằng ts_rank_cd
+func (r *storyRepository) searchStories(query string, page, limit int, published bool) ([]models.Story, int64, error) {
 	var stories []models.Story
 	var total int64
-	searchQuery := "%" + query + "%"
-	
-	r.db.Model(&models.Story{}).Where("title ILIKE ? OR description ILIKE ?", 
-		searchQuery, searchQuery).Count(&total)
+
+	tsQuery := buildPrefixTsQuery(query)
+	if tsQuery == "" {
+		return stories, 0, nil
+	}
+
+	base := r.db.Model(&models.Story{}).
+		Where("search_vector @@ to_tsquery('simple', unaccent(?))", tsQuery)
+	if published {
+		base = base.Where("is_published = ?", true)
+	}
+
+	if err := base.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
 	offset := (page - 1) * limit
-	err := r.db.Preload("Genres").Where("title ILIKE ? OR description ILIKE ?", 
-		searchQuery, searchQuery).Offset(offset).Limit(limit).Order("updated_at DESC").Find(&stories).Error
+	err := base.Preload("Genres").
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "ts_rank_cd(search_vector, to_tsquery('simple', unaccent(?))) DESC, view_count DESC",
+			Vars:               []interface{}{tsQuery},
+			WithoutParentheses: true,
+		}}).
+		Offset(offset).Limit(limit).Find(&stories).Error
 	return stories, total, err
-}
\ No newline at end of file
+}
+
+// buildPrefixTsQuery - "tien hie" => "tien:* & hie:*" (mỗi từ khớp tiền tố, tất cả từ đều phải có)
+// Loại bỏ mọi ký tự đặc biệt để không thể chèn toán tử tsquery
+func buildPrefixTsQuery(query string) string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		terms = append(terms, word+":*")
+	}
+	return strings.Join(terms, " & ")
+}
+
+// CountPublishedStories - Đếm truyện đã xuất bản (chưa bị xóa mềm)
+func (r *storyRepository) CountPublishedStories() (int64, error) {
+	var total int64
+	err := r.db.Model(&models.Story{}).Where("is_published = ?", true).Count(&total).Error
+	return total, err
+}
+
+// GetPublishedStorySitemap - Slug + updated_at của truyện đã xuất bản, thứ tự ổn định để phân trang sitemap
+func (r *storyRepository) GetPublishedStorySitemap(offset, limit int) ([]SitemapEntry, error) {
+	var entries []SitemapEntry
+	err := r.db.Model(&models.Story{}).
+		Select("slug, updated_at").
+		Where("is_published = ?", true).
+		Order("created_at ASC, id ASC").
+		Offset(offset).Limit(limit).
+		Scan(&entries).Error
+	return entries, err
+}
+
+// GetGenreSitemap - Slug thể loại, lastmod = lần cập nhật gần nhất của truyện đã xuất bản trong thể loại
+func (r *storyRepository) GetGenreSitemap() ([]SitemapEntry, error) {
+	var entries []SitemapEntry
+	err := r.db.Table("genres").
+		Select("genres.slug, COALESCE(MAX(stories.updated_at), genres.created_at) AS updated_at").
+		Joins("LEFT JOIN story_genres ON story_genres.genre_id = genres.id").
+		Joins("LEFT JOIN stories ON stories.id = story_genres.story_id AND stories.is_published = ? AND stories.deleted_at IS NULL", true).
+		Group("genres.id, genres.slug, genres.created_at").
+		Order("genres.slug ASC").
+		Scan(&entries).Error
+	return entries, err
+}
@@ -0,0 +1,200 @@
+package repositories
+
+import (
+	"nekozanedex/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SimilarityScore - Điểm tương đồng giữa 2 truyện
+type SimilarityScore struct {
+	StoryID        uuid.UUID
+	SimilarStoryID uuid.UUID
+	Score          float64
+}
+
+type StorySimilarityRepository interface {
+	ComputeCoReadScores(perStory, perUser, minShared int) ([]SimilarityScore, error)
+	ComputeGenreScores(perStory int) ([]SimilarityScore, error)
+	ReplaceAll(similarities []models.StorySimilarity) error
+	GetSimilar(storyID uuid.UUID, limit int) ([]models.Story, error)
+	GetRecommendations(userID uuid.UUID, fallbackPeriod string, limit int) ([]models.Story, error)
+}
+
+type storySimilarityRepository struct {
+	db *gorm.DB
+}
+
+func NewStorySimilarityRepository(db *gorm.DB) StorySimilarityRepository {
+	return &storySimilarityRepository{db: db}
+}
+
+// ComputeCoReadScores - Độ tương đồng item-item từ bookmarks + reading_history
+// score = cosine = số user chung / sqrt(số user của A * số user của B)
+// Mỗi user chỉ lấy perUser truyện gần nhất để self-join không bùng nổ với user đọc rất nhiều
+func (r *storySimilarityRepository) ComputeCoReadScores(perStory, perUser, minShared int) ([]SimilarityScore, error) {
+	var scores []SimilarityScore
+	err := r.db.Raw(`
+		WITH raw AS (
+			SELECT user_id, story_id, created_at AS at FROM bookmarks
+			UNION ALL
+			SELECT user_id, story_id, last_read_at AS at FROM reading_history
+		),
+		interactions AS (
+			SELECT user_id, story_id FROM (
+				SELECT user_id, story_id,
+					ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY MAX(at) DESC) AS rn
+				FROM raw
+				GROUP BY user_id, story_id
+			) ranked
+			WHERE rn <= ?
+		),
+		readers AS (
+			SELECT story_id, COUNT(*) AS n FROM interactions GROUP BY story_id
+		),
+		pairs AS (
+			SELECT a.story_id, b.story_id AS similar_story_id, COUNT(*) AS shared
+			FROM interactions a
+			JOIN interactions b ON a.user_id = b.user_id AND a.story_id <> b.story_id
+			GROUP BY a.story_id, b.story_id
+			HAVING COUNT(*) >= ?
+		),
+		scored AS (
+			SELECT p.story_id, p.similar_story_id,
+				p.shared / SQRT(ra.n::float8 * rb.n) AS score,
+				ROW_NUMBER() OVER (PARTITION BY p.story_id ORDER BY p.shared / SQRT(ra.n::float8 * rb.n) DESC, p.shared DESC) AS rn
+			FROM pairs p
+			JOIN readers ra ON ra.story_id = p.story_id
+			JOIN readers rb ON rb.story_id = p.similar_story_id
+			JOIN stories s ON s.id = p.similar_story_id AND s.is_published = true AND s.deleted_at IS NULL
+		)
+		SELECT story_id, similar_story_id, score FROM scored WHERE rn <= ?`,
+		perUser, minShared, perStory).Scan(&scores).Error
+	return scores, err
+}
+
+// ComputeGenreScores - Độ tương đồng theo thể loại (Jaccard), dùng cho truyện mới chưa có ai đọc
+// Cùng điểm thì ưu tiên truyện nhiều lượt xem hơn
+func (r *storySimilarityRepository) ComputeGenreScores(perStory int) ([]SimilarityScore, error) {
+	var scores []SimilarityScore
+	err := r.db.Raw(`
+		WITH genre_counts AS (
+			SELECT story_id, COUNT(*) AS n FROM story_genres GROUP BY story_id
+		),
+		pairs AS (
+			SELECT a.story_id, b.story_id AS similar_story_id, COUNT(*) AS shared
+			FROM story_genres a
+			JOIN story_genres b ON a.genre_id = b.genre_id AND a.story_id <> b.story_id
+			GROUP BY a.story_id, b.story_id
+		),
+		scored AS (
+			SELECT p.story_id, p.similar_story_id,
+				p.shared::float8 / (ga.n + gb.n - p.shared) AS score,
+				ROW_NUMBER() OVER (PARTITION BY p.story_id ORDER BY p.shared::float8 / (ga.n + gb.n - p.shared) DESC, s.view_count DESC) AS rn
+			FROM pairs p
+			JOIN genre_counts ga ON ga.story_id = p.story_id
+			JOIN genre_counts gb ON gb.story_id = p.similar_story_id
+			JOIN stories s ON s.id = p.similar_story_id AND s.is_published = true AND s.deleted_at IS NULL
+		)
+		SELECT story_id, similar_story_id, score FROM scored WHERE rn <= ?`,
+		perStory).Scan(&scores).Error
+	return scores, err
+}
+
+// ReplaceAll - Thay toàn bộ bảng tương đồng trong 1 transaction
+func (r *storySimilarityRepository) ReplaceAll(similarities []models.StorySimilarity) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.StorySimilarity{}).Error; err != nil {
+			return err
+		}
+		if len(similarities) == 0 {
+			return nil
+		}
+		return tx.Omit("SimilarStory").CreateInBatches(similarities, 500).Error
+	})
+}
+
+// GetSimilar - Truyện tương tự đã tính sẵn, theo điểm giảm dần
+func (r *storySimilarityRepository) GetSimilar(storyID uuid.UUID, limit int) ([]models.Story, error) {
+	var similarities []models.StorySimilarity
+	err := r.db.
+		Preload("SimilarStory").
+		Preload("SimilarStory.Genres").
+		Joins("JOIN stories ON stories.id = story_similarities.similar_story_id AND stories.is_published = ? AND stories.deleted_at IS NULL", true).
+		Where("story_similarities.story_id = ?", storyID).
+		Order("story_similarities.score DESC").
+		Limit(limit).
+		Find(&similarities).Error
+	if err != nil {
+		return nil, err
+	}
+
+	stories := make([]models.Story, 0, len(similarities))
+	for _, similarity := range similarities {
+		stories = append(stories, similarity.SimilarStory)
+	}
+	return stories, nil
+}
+
+// GetRecommendations - Cộng điểm tương đồng từ mọi truyện user đã bookmark/đọc
+// Thiếu kết quả (user mới, truyện ít người đọc) thì bù bằng bảng xếp hạng fallbackPeriod
+// Bỏ qua truyện đã có trong lịch sử đọc hoặc bookmark của user
+func (r *storySimilarityRepository) GetRecommendations(userID uuid.UUID, fallbackPeriod string, limit int) ([]models.Story, error) {
+	var ids []uuid.UUID
+	err := r.db.Raw(`
+		WITH seeds AS (
+			SELECT story_id FROM bookmarks WHERE user_id = ?
+			UNION
+			SELECT story_id FROM reading_history WHERE user_id = ?
+		),
+		candidates AS (
+			SELECT similar_story_id AS story_id, 1 AS tier, SUM(score) AS score, COUNT(*) AS hits
+			FROM story_similarities
+			WHERE story_id IN (SELECT story_id FROM seeds)
+			GROUP BY similar_story_id
+			UNION ALL
+			SELECT story_id, 2 AS tier, -rank AS score, 0 AS hits
+			FROM story_rankings
+			WHERE period = ?
+		)
+		SELECT c.story_id
+		FROM candidates c
+		JOIN stories s ON s.id = c.story_id AND s.is_published = true AND s.deleted_at IS NULL
+		WHERE c.story_id NOT IN (SELECT story_id FROM seeds)
+		GROUP BY c.story_id
+		ORDER BY MIN(c.tier), MAX(c.score) DESC, MAX(c.hits) DESC
+		LIMIT ?`,
+		userID, userID, fallbackPeriod, limit).Scan(&ids).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return []models.Story{}, nil
+	}
+
+	var stories []models.Story
+	if err := r.db.Preload("Genres").Where("id IN ?", ids).Find(&stories).Error; err != nil {
+		return nil, err
+	}
+
+	// Giữ thứ tự theo điểm
+	position := make(map[uuid.UUID]int, len(ids))
+	for i, id := range ids {
+		position[id] = i
+	}
+	ordered := make([]models.Story, len(ids))
+	found := make([]bool, len(ids))
+	for _, story := range stories {
+		i := position[story.ID]
+		ordered[i] = story
+		found[i] = true
+	}
+	result := ordered[:0]
+	for i := range ordered {
+		if found[i] {
+			result = append(result, ordered[i])
+		}
+	}
+	return result, nil
+}
@@ -1,6 +1,8 @@
 package repositories
 
 import (
+	"sort"
+	"strings"
 	"time"
 
 	"nekozanedex/internal/models"
@@ -9,10 +11,62 @@ import (
 	"gorm.io/gorm"
 )
 
+// AnalyticsRange - Khoảng ngày [From, To) + kích thước bucket cho time-series của admin
+type AnalyticsRange struct {
+	From    time.Time
+	To      time.Time  // Không bao gồm
+	Bucket  string     // day, week, month (đã validate ở service)
+	StoryID *uuid.UUID // nil = toàn site
+}
+
+// ViewBucket - Lượt xem trong 1 bucket
+type ViewBucket struct {
+	Bucket         string `json:"bucket"` // YYYY-MM-DD, ngày đầu bucket
+	Views          int64  `json:"views"`
+	UniqueReaders  int64  `json:"unique_readers"`
+	LoggedInViews  int64  `json:"logged_in_views"`
+	AnonymousViews int64  `json:"anonymous_views"`
+}
+
+// ViewTotals - Tổng lượt xem của cả khoảng (unique readers không cộng dồn từ bucket được)
+type ViewTotals struct {
+	Views          int64 `json:"views"`
+	UniqueReaders  int64 `json:"unique_readers"`
+	LoggedInViews  int64 `json:"logged_in_views"`
+	AnonymousViews int64 `json:"anonymous_views"`
+}
+
+// ChapterViewStat - Lượt xem của 1 chapter trong khoảng
+type ChapterViewStat struct {
+	ChapterID     uuid.UUID  `json:"chapter_id"`
+	StoryID       uuid.UUID  `json:"story_id"`
+	StoryTitle    string     `json:"story_title"`
+	ChapterNumber int        `json:"chapter_number"`
+	ChapterTitle  string     `json:"chapter_title"`
+	PublishedAt   *time.Time `json:"published_at"`
+	Views         int64      `json:"views"`
+	UniqueReaders int64      `json:"unique_readers"`
+}
+
+// CountBucket - Số lượng theo bucket (comments, bookmarks...)
+type CountBucket struct {
+	Bucket string `json:"bucket"` // YYYY-MM-DD, ngày đầu bucket
+	Count  int64  `json:"count"`
+}
+
+// analyticsBucketExpr - Bucket dạng chuỗi ngày để khớp với danh sách bucket sinh ở service
+func analyticsBucketExpr(column string) string {
+	return "to_char(date_trunc(?, " + column + "), 'YYYY-MM-DD')"
+}
+
+// viewReaderExpr - 1 người đọc = user_id nếu đăng nhập, ngược lại là IP
+const viewReaderExpr = "COALESCE(sv.user_id::text, 'ip:' || sv.ip_address)"
+
 type StoryViewRepository interface {
-	HasViewedRecently(storyID uuid.UUID, userID *uuid.UUID, ipAddress string, duration time.Duration) (bool, error)
-	RecordView(view *models.StoryView) error
-	GetViewStats(storyID uuid.UUID) (int64, error)
+	FlushViews(views []models.StoryView, window time.Duration) (int, error)
+	GetViewSeries(r AnalyticsRange) ([]ViewBucket, error)
+	GetViewTotals(r AnalyticsRange) (*ViewTotals, error)
+	GetTopChapters(r AnalyticsRange, limit int) ([]ChapterViewStat, error)
 }
 
 type storyViewRepository struct {
@@ -23,36 +77,180 @@ func NewStoryViewRepository(db *gorm.DB) StoryViewRepository {
 	return &storyViewRepository{db: db}
 }
 
-// HasViewedRecently checks if user/IP has viewed this story within duration
-// Priority: UserID > IPAddress
-func (r *storyViewRepository) HasViewedRecently(storyID uuid.UUID, userID *uuid.UUID, ipAddress string, duration time.Duration) (bool, error) {
-	var count int64
-	cutoff := time.Now().Add(-duration)
+// flushChunkSize - Số dòng mỗi câu VALUES (giữ số placeholder dưới giới hạn 65535 của Postgres)
+const flushChunkSize = 500
+
+// FlushViews - Ghi 1 lô view đã gom trong RAM bằng 1 transaction
+// Bỏ các view đã có trong cửa sổ dedupe (đúng cả khi restart hoặc chạy nhiều instance),
+// INSERT story_views rồi cộng dồn view_count của stories/chapters bằng 1 UPDATE mỗi bảng
+// Trả về số view thực sự được tính
+func (r *storyViewRepository) FlushViews(views []models.StoryView, window time.Duration) (int, error) {
+	if len(views) == 0 {
+		return 0, nil
+	}
+	cutoff := time.Now().Add(-window)
+
+	counted := 0
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		fresh := make([]models.StoryView, 0, len(views))
+		for start := 0; start < len(views); start += flushChunkSize {
+			chunk := views[start:min(start+flushChunkSize, len(views))]
+			seen, err := alreadyViewed(tx, chunk, cutoff)
+			if err != nil {
+				return err
+			}
+			for i := range chunk {
+				if !seen[i] {
+					fresh = append(fresh, chunk[i])
+				}
+			}
+		}
+		if len(fresh) == 0 {
+			return nil
+		}
+
+		if err := tx.Omit("Story", "Chapter", "User").CreateInBatches(fresh, flushChunkSize).Error; err != nil {
+			return err
+		}
+
+		storyCounts := make(map[uuid.UUID]int)
+		chapterCounts := make(map[uuid.UUID]int)
+		for _, view := range fresh {
+			if view.ChapterID != nil {
+				chapterCounts[*view.ChapterID] += view.ViewCount
+			} else {
+				storyCounts[view.StoryID] += view.ViewCount
+			}
+		}
+		if err := incrementViewCounts(tx, "stories", storyCounts); err != nil {
+			return err
+		}
+		if err := incrementViewCounts(tx, "chapters", chapterCounts); err != nil {
+			return err
+		}
+
+		counted = len(fresh)
+		return nil
+	})
+	return counted, err
+}
+
+// alreadyViewed - Đánh dấu các view trong chunk đã có dòng story_views sau cutoff (view chapter theo chapter_id, view truyện theo story_id; user_id trước, ẩn danh theo IP)
+func alreadyViewed(tx *gorm.DB, chunk []models.StoryView, cutoff time.Time) (map[int]bool, error) {
+	values := make([]string, 0, len(chunk))
+	args := make([]interface{}, 0, len(chunk)*5+1)
+	for i, view := range chunk {
+		values = append(values, "(?::int, ?::uuid, ?::uuid, ?::uuid, ?::text)")
+		var chapterID, userID interface{}
+		if view.ChapterID != nil {
+			chapterID = *view.ChapterID
+		}
+		if view.UserID != nil {
+			userID = *view.UserID
+		}
+		args = append(args, i, view.StoryID, chapterID, userID, view.IPAddress)
+	}
+	args = append(args, cutoff)
 
-	query := r.db.Model(&models.StoryView{}).Where("story_id = ? AND viewed_at > ?", storyID, cutoff)
+	var indexes []int
+	err := tx.Raw(`
+		SELECT v.idx FROM (VALUES `+strings.Join(values, ", ")+`) AS v(idx, story_id, chapter_id, user_id, ip_address)
+		WHERE EXISTS (
+			SELECT 1 FROM story_views sv
+			WHERE sv.viewed_at > ?
+				AND CASE WHEN v.chapter_id IS NULL
+					THEN sv.story_id = v.story_id AND sv.chapter_id IS NULL
+					ELSE sv.chapter_id = v.chapter_id END
+				AND CASE WHEN v.user_id IS NULL
+					THEN sv.user_id IS NULL AND sv.ip_address = v.ip_address
+					ELSE sv.user_id = v.user_id END
+		)`, args...).Scan(&indexes).Error
+	if err != nil {
+		return nil, err
+	}
 
-	// If user is logged in, check by user_id
-	if userID != nil {
-		query = query.Where("user_id = ?", *userID)
-	} else {
-		// Anonymous user - check by IP
-		query = query.Where("user_id IS NULL AND ip_address = ?", ipAddress)
+	seen := make(map[int]bool, len(indexes))
+	for _, i := range indexes {
+		seen[i] = true
 	}
+	return seen, nil
+}
+
+// incrementViewCounts - Cộng view_count cho nhiều dòng bằng UPDATE ... FROM (VALUES ...)
+// Sắp xếp theo id để các instance cùng flush không deadlock
+func incrementViewCounts(tx *gorm.DB, table string, counts map[uuid.UUID]int) error {
+	ids := make([]uuid.UUID, 0, len(counts))
+	for id := range counts {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
 
-	if err := query.Count(&count).Error; err != nil {
-		return false, err
+	for start := 0; start < len(ids); start += flushChunkSize {
+		chunk := ids[start:min(start+flushChunkSize, len(ids))]
+		values := make([]string, 0, len(chunk))
+		args := make([]interface{}, 0, len(chunk)*2)
+		for _, id := range chunk {
+			values = append(values, "(?::uuid, ?::int)")
+			args = append(args, id, counts[id])
+		}
+		err := tx.Exec("UPDATE "+table+" SET view_count = "+table+".view_count + v.n "+
+			"FROM (VALUES "+strings.Join(values, ", ")+") AS v(id, n) WHERE "+table+".id = v.id", args...).Error
+		if err != nil {
+			return err
+		}
 	}
+	return nil
+}
+
+// viewsInRange - story_views trong khoảng ngày, lọc theo truyện nếu có
+func (r *storyViewRepository) viewsInRange(ar AnalyticsRange) *gorm.DB {
+	query := r.db.Table("story_views AS sv").
+		Where("sv.viewed_at >= ? AND sv.viewed_at < ?", ar.From.Format("2006-01-02"), ar.To.Format("2006-01-02"))
+	if ar.StoryID != nil {
+		query = query.Where("sv.story_id = ?", *ar.StoryID)
+	}
+	return query
+}
 
-	return count > 0, nil
+// GetViewSeries - Lượt xem, người đọc duy nhất, đăng nhập/ẩn danh theo từng bucket
+func (r *storyViewRepository) GetViewSeries(ar AnalyticsRange) ([]ViewBucket, error) {
+	var buckets []ViewBucket
+	err := r.viewsInRange(ar).
+		Select(analyticsBucketExpr("sv.viewed_at")+" AS bucket, "+
+			"COALESCE(SUM(sv.view_count), 0) AS views, "+
+			"COUNT(DISTINCT "+viewReaderExpr+") AS unique_readers, "+
+			"COALESCE(SUM(sv.view_count) FILTER (WHERE sv.user_id IS NOT NULL), 0) AS logged_in_views, "+
+			"COALESCE(SUM(sv.view_count) FILTER (WHERE sv.user_id IS NULL), 0) AS anonymous_views", ar.Bucket).
+		Group("bucket").
+		Order("bucket").
+		Scan(&buckets).Error
+	return buckets, err
 }
 
-func (r *storyViewRepository) RecordView(view *models.StoryView) error {
-	view.ViewedAt = time.Now()
-	return r.db.Create(view).Error
+// GetViewTotals - Tổng của cả khoảng
+func (r *storyViewRepository) GetViewTotals(ar AnalyticsRange) (*ViewTotals, error) {
+	var totals ViewTotals
+	err := r.viewsInRange(ar).
+		Select("COALESCE(SUM(sv.view_count), 0) AS views, " +
+			"COUNT(DISTINCT " + viewReaderExpr + ") AS unique_readers, " +
+			"COALESCE(SUM(sv.view_count) FILTER (WHERE sv.user_id IS NOT NULL), 0) AS logged_in_views, " +
+			"COALESCE(SUM(sv.view_count) FILTER (WHERE sv.user_id IS NULL), 0) AS anonymous_views").
+		Scan(&totals).Error
+	return &totals, err
 }
 
-func (r *storyViewRepository) GetViewStats(storyID uuid.UUID) (int64, error) {
-	var count int64
-	err := r.db.Model(&models.StoryView{}).Where("story_id = ?", storyID).Count(&count).Error
-	return count, err
+// GetTopChapters - Chapters nhiều lượt xem nhất trong khoảng (chỉ tính view có chapter_id)
+func (r *storyViewRepository) GetTopChapters(ar AnalyticsRange, limit int) ([]ChapterViewStat, error) {
+	var stats []ChapterViewStat
+	err := r.viewsInRange(ar).
+		Select("sv.chapter_id, chapters.story_id, stories.title AS story_title, " +
+			"chapters.chapter_number, chapters.title AS chapter_title, chapters.published_at, " +
+			"SUM(sv.view_count) AS views, COUNT(DISTINCT " + viewReaderExpr + ") AS unique_readers").
+		Joins("JOIN chapters ON chapters.id = sv.chapter_id AND chapters.deleted_at IS NULL").
+		Joins("JOIN stories ON stories.id = chapters.story_id").
+		Group("sv.chapter_id, chapters.story_id, stories.title, chapters.chapter_number, chapters.title, chapters.published_at").
+		Order("views DESC, chapters.chapter_number ASC").
+		Limit(limit).
+		Scan(&stats).Error
+	return stats, err
 }
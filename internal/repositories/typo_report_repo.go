@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"nekozanedex/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrTypoReportResolved - Báo lỗi đã được admin khác xử lý (không còn pending)
+var ErrTypoReportResolved = errors.New("typo report is no longer pending")
+
+// TypoFix - Sửa nội dung chapter khi resolve báo lỗi: nhận content hiện tại (đã khóa row), trả content mới
+type TypoFix struct {
+	ChapterID uuid.UUID
+	EditorID  *uuid.UUID
+	Note      *string
+	Apply     func(content string) (string, error)
+}
+
+type TypoReportRepository interface {
+	CreateReport(report *models.TypoReport) error
+	FindReportByID(id uuid.UUID) (*models.TypoReport, error)
+	GetReports(page, limit int, status string) ([]models.TypoReport, int64, error)
+	ResolveReport(id uuid.UUID, status string, fix *TypoFix) (*models.Chapter, error)
+}
+
+type typoReportRepository struct {
+	db *gorm.DB
+}
+
+func NewTypoReportRepository(db *gorm.DB) TypoReportRepository {
+	return &typoReportRepository{db: db}
+}
+
+// CreateReport - Tạo báo lỗi chính tả
+func (r *typoReportRepository) CreateReport(report *models.TypoReport) error {
+	return r.db.Create(report).Error
+}
+
+// FindReportByID - Tìm báo lỗi theo ID
+func (r *typoReportRepository) FindReportByID(id uuid.UUID) (*models.TypoReport, error) {
+	var report models.TypoReport
+	err := r.db.Preload("User").Preload("Chapter").Preload("Chapter.Story").First(&report, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// GetReports - Lấy danh sách báo lỗi (lọc theo status nếu có)
+func (r *typoReportRepository) GetReports(page, limit int, status string) ([]models.TypoReport, int64, error) {
+	var reports []models.TypoReport
+	var total int64
+	offset := (page - 1) * limit
+
+	query := r.db.Model(&models.TypoReport{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Không preload Content của chapter để tránh payload lớn
+	err := query.Preload("User").
+		Preload("Chapter", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "story_id", "chapter_number", "title")
+		}).
+		Preload("Chapter.Story", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "title", "slug")
+		}).
+		Offset(offset).Limit(limit).
+		Order("created_at DESC").
+		Find(&reports).Error
+
+	return reports, total, err
+}
+
+// ResolveReport - Chuyển báo lỗi pending sang status và (nếu có fix) sửa chapter kèm revision trong cùng transaction.
+// UPDATE có điều kiện status = pending nên 2 admin xử lý cùng lúc thì chỉ 1 người sửa được chapter,
+// người còn lại nhận ErrTypoReportResolved. Trả về chapter đã sửa (nil nếu không có fix).
+func (r *typoReportRepository) ResolveReport(id uuid.UUID, status string, fix *TypoFix) (*models.Chapter, error) {
+	var patched *models.Chapter
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.TypoReport{}).
+			Where("id = ? AND status = ?", id, "pending").
+			Update("status", status)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTypoReportResolved
+		}
+		if fix == nil {
+			return nil
+		}
+
+		var chapter models.Chapter
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Story").First(&chapter, "id = ?", fix.ChapterID).Error; err != nil {
+			return err
+		}
+		content, err := fix.Apply(chapter.Content)
+		if err != nil {
+			return err
+		}
+		chapter.Content = content
+		chapter.UpdatedAt = time.Now()
+		if _, err := saveChapterWithRevision(tx, &chapter, fix.EditorID, fix.Note); err != nil {
+			return err
+		}
+		patched = &chapter
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return patched, nil
+}
@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"nekozanedex/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type VolumeRepository interface {
+	CreateVolume(volume *models.Volume) error
+	FindVolumeByID(id uuid.UUID) (*models.Volume, error)
+	UpdateVolume(volume *models.Volume) error
+	DeleteVolume(id uuid.UUID) error
+	GetVolumesByStory(storyID uuid.UUID) ([]models.Volume, error)
+	ExistsVolumeNumber(storyID uuid.UUID, volumeNumber int, excludeID uuid.UUID) (bool, error)
+	MoveChapters(storyID uuid.UUID, chapterIDs []uuid.UUID, volumeID *uuid.UUID) (int64, error)
+}
+
+type volumeRepository struct {
+	db *gorm.DB
+}
+
+func NewVolumeRepository(db *gorm.DB) VolumeRepository {
+	return &volumeRepository{db: db}
+}
+
+// CreateVolume - Tạo tập mới
+func (r *volumeRepository) CreateVolume(volume *models.Volume) error {
+	return r.db.Create(volume).Error
+}
+
+// FindVolumeByID - Tìm tập theo ID
+func (r *volumeRepository) FindVolumeByID(id uuid.UUID) (*models.Volume, error) {
+	var volume models.Volume
+	if err := r.db.First(&volume, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &volume, nil
+}
+
+// UpdateVolume - Cập nhật tập
+func (r *volumeRepository) UpdateVolume(volume *models.Volume) error {
+	return r.db.Omit("Chapters").Save(volume).Error
+}
+
+// DeleteVolume - Xóa tập, các chapter trong tập chuyển về "chưa thuộc tập nào"
+func (r *volumeRepository) DeleteVolume(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Unscoped để gỡ cả chapter đã soft delete (tránh vướng khóa ngoại)
+		if err := tx.Unscoped().Model(&models.Chapter{}).
+			Where("volume_id = ?", id).
+			UpdateColumn("volume_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Volume{}, "id = ?", id).Error
+	})
+}
+
+// GetVolumesByStory - Lấy các tập của truyện theo thứ tự
+func (r *volumeRepository) GetVolumesByStory(storyID uuid.UUID) ([]models.Volume, error) {
+	var volumes []models.Volume
+	err := r.db.Where("story_id = ?", storyID).Order("volume_number ASC").Find(&volumes).Error
+	return volumes, err
+}
+
+// ExistsVolumeNumber - Kiểm tra số tập đã tồn tại trong truyện chưa
+func (r *volumeRepository) ExistsVolumeNumber(storyID uuid.UUID, volumeNumber int, excludeID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Volume{}).
+		Where("story_id = ? AND volume_number = ? AND id <> ?", storyID, volumeNumber, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// MoveChapters - Chuyển chapters sang tập khác (volumeID = nil để bỏ khỏi tập)
+// Chỉ tác động lên chapter thuộc đúng truyện
+func (r *volumeRepository) MoveChapters(storyID uuid.UUID, chapterIDs []uuid.UUID, volumeID *uuid.UUID) (int64, error) {
+	result := r.db.Model(&models.Chapter{}).
+		Where("story_id = ? AND id IN ?", storyID, chapterIDs).
+		UpdateColumn("volume_id", volumeID)
+	return result.RowsAffected, result.Error
+}
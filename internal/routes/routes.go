@@ -24,6 +24,18 @@ type Handlers struct {
 	ReadingHistory *handlers.ReadingHistoryHandler
 	UserSettings   *handlers.UserSettingsHandler
 	Centrifugo     *handlers.CentrifugoHandler
+	Rating         *handlers.RatingHandler
+	TypoReport     *handlers.TypoReportHandler
+	Chat           *handlers.ChatHandler
+	Revision       *handlers.ChapterRevisionHandler
+	Volume         *handlers.VolumeHandler
+	Export         *handlers.ExportHandler
+	Feed           *handlers.FeedHandler
+	Sitemap        *handlers.SitemapHandler
+	Ranking        *handlers.RankingHandler
+	Recommendation *handlers.RecommendationHandler
+	Analytics      *handlers.AnalyticsHandler
+	MediaGC        *handlers.MediaGCHandler
 }
 
 func SetupRoutes(r *gin.Engine, cfg *config.Config, h *Handlers) {
@@ -48,6 +60,38 @@ func SetupRoutes(r *gin.Engine, cfg *config.Config, h *Handlers) {
 	// Swagger API Documentation
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// SEO: robots.txt + sitemap index + sitemap con đã phân trang
+	if h.Sitemap != nil {
+		seo := r.Group("")
+		seo.Use(middleware.GeneralRateLimiter())
+		{
+			seo.GET("/robots.txt", h.Sitemap.Robots)
+			seo.GET("/sitemap.xml", h.Sitemap.SitemapIndex)
+			seo.GET("/sitemaps/:file", h.Sitemap.Sitemap)
+		}
+	}
+
+	// RSS/Atom feeds (public, cache bằng ETag/Last-Modified)
+	if h.Feed != nil {
+		feeds := r.Group("/feeds")
+		feeds.Use(middleware.GeneralRateLimiter())
+		{
+			feeds.GET("/latest.xml", h.Feed.LatestFeed)
+			feeds.GET("/latest.atom", h.Feed.LatestFeed)
+			feeds.GET("/genres/:file", h.Feed.GenreFeed)
+			feeds.GET("/stories/:file", h.Feed.StoryFeed)
+		}
+	}
+
+	// File upload lưu local (STORAGE_DRIVER=local). Key luôn mới nên cache vĩnh viễn được
+	if cfg.Storage.Driver == "local" {
+		uploads := r.Group("/uploads")
+		uploads.Use(func(c *gin.Context) {
+			c.Header("Cache-Control", "public, max-age=31536000, immutable")
+		})
+		uploads.Static("/", cfg.Storage.LocalDir)
+	}
+
 	// API routes với General Rate Limiting (100 req/min)
 	api := r.Group("/api")
 	api.Use(middleware.GeneralRateLimiter())
@@ -78,19 +122,30 @@ func SetupRoutes(r *gin.Engine, cfg *config.Config, h *Handlers) {
 		stories := api.Group("/stories")
 		{
 			stories.GET("", h.Story.GetStories)
-			stories.GET("/latest", h.Story.GetLatestStories)
-			stories.GET("/hot", h.Story.GetHotStories)
+			stories.GET("/latest", middleware.ConditionalGET(middleware.CachePublicPage), h.Story.GetLatestStories)
+			stories.GET("/hot", middleware.ConditionalGET(middleware.CachePublicPage), h.Story.GetHotStories)
+			stories.GET("/top-rated", h.Story.GetTopRatedStories)
 			stories.GET("/random", h.Story.GetRandomStory)
 			stories.GET("/search", h.Story.SearchStories)
-			stories.GET("/:slug", h.Story.GetStoryBySlug)
-			stories.GET("/:slug/chapters", h.Chapter.GetChaptersByStory)
-			stories.GET("/:slug/chapters/:number", h.Chapter.GetChapterByNumber)
+			stories.GET("/browse", h.Story.BrowseStories)
+			if h.Ranking != nil {
+				stories.GET("/ranking", h.Ranking.GetRanking)
+			}
+			stories.GET("/:slug", middleware.ConditionalGET(middleware.CachePublicPage), h.Story.GetStoryBySlug)
+			stories.GET("/:slug/chapters", middleware.ConditionalGET(middleware.CachePublicPage), h.Chapter.GetChaptersByStory)
+			if h.Recommendation != nil {
+				stories.GET("/:slug/similar", h.Recommendation.GetSimilarStories)
+			}
+			stories.GET("/:slug/chapters/:number", middleware.OptionalAuthMiddleware(cfg), middleware.ConditionalGET(middleware.CacheChapterContent), h.Chapter.GetChapterByNumber)
+			if h.Export != nil {
+				stories.GET("/:slug/export.epub", middleware.StrictRateLimiter(), h.Export.ExportEpub)
+			}
 		}
 
 		// ============ GENRE ROUTES ============
 		genres := api.Group("/genres")
 		{
-			genres.GET("", h.Story.GetAllGenres)
+			genres.GET("", middleware.ConditionalGET(middleware.CachePublicPage), h.Story.GetAllGenres)
 			genres.GET("/:genre/stories", h.Story.GetStoriesByGenre)
 		}
 
@@ -117,6 +172,25 @@ func SetupRoutes(r *gin.Engine, cfg *config.Config, h *Handlers) {
 		// Story comments (authenticated)
 		api.POST("/stories/:storyId/comments", middleware.AuthMiddleware(cfg), h.Comment.CreateComment)
 
+		// ============ TYPO REPORT ROUTES (Guest + Authenticated) ============
+		if h.TypoReport != nil {
+			api.POST("/typo-reports", middleware.StrictRateLimiter(), middleware.OptionalAuthMiddleware(cfg), h.TypoReport.SubmitTypoReport)
+		}
+
+		// ============ CHAT ROUTES ============
+		if h.Chat != nil {
+			chat := api.Group("/chat/rooms")
+			{
+				chat.GET("/:roomId/messages", h.Chat.GetMessages)
+				chat.GET("/:roomId/settings", h.Chat.GetRoomSetting)
+				chat.POST("/:roomId/messages",
+					middleware.AuthMiddleware(cfg),
+					middleware.RoleMiddleware("reader", "admin"),
+					h.Chat.SendMessage,
+				)
+			}
+		}
+
 		// ============ BOOKMARK ROUTES (Reader + Admin) ============
 		bookmarks := api.Group("/bookmarks")
 		bookmarks.Use(middleware.AuthMiddleware(cfg))
@@ -128,6 +202,23 @@ func SetupRoutes(r *gin.Engine, cfg *config.Config, h *Handlers) {
 			bookmarks.GET("/:storyId/check", h.Bookmark.CheckBookmark)
 		}
 
+		// ============ RECOMMENDATION ROUTES (Reader + Admin) ============
+		if h.Recommendation != nil {
+			api.GET("/recommendations", middleware.AuthMiddleware(cfg), middleware.RoleMiddleware("reader", "admin"), h.Recommendation.GetRecommendations)
+		}
+
+		// ============ RATING ROUTES (Reader + Admin) ============
+		if h.Rating != nil {
+			ratings := api.Group("/ratings")
+			ratings.Use(middleware.AuthMiddleware(cfg))
+			ratings.Use(middleware.RoleMiddleware("reader", "admin"))
+			{
+				ratings.GET("/:storyId", h.Rating.GetMyRating)
+				ratings.PUT("/:storyId", h.Rating.RateStory)
+				ratings.DELETE("/:storyId", h.Rating.RemoveRating)
+			}
+		}
+
 		// ============ NOTIFICATION ROUTES (Reader + Admin) ============
 		notifications := api.Group("/notifications")
 		notifications.Use(middleware.AuthMiddleware(cfg))
@@ -170,39 +261,71 @@ func SetupRoutes(r *gin.Engine, cfg *config.Config, h *Handlers) {
 		admin.Use(middleware.AuthMiddleware(cfg))
 		admin.Use(middleware.CSRFMiddleware(csrfCfg))
 		admin.Use(middleware.RoleMiddleware("admin")) // Chỉ Admin
+		// ETag chỉ cho các GET trả dữ liệu lớn, ít đổi mà trang admin hay tải lại (chi tiết truyện/chapter, revision);
+		// danh sách phân trang, báo cáo, analytics thay đổi liên tục nên buffer + hash chỉ tốn thêm
+		adminETag := middleware.ConditionalGET(middleware.CachePrivate)
 		{
 			// Admin Stories
 			adminStories := admin.Group("/stories")
 			{
 				adminStories.GET("", h.Story.GetAllStoriesAdmin)
-				adminStories.GET("/:id", h.Story.GetStoryByID)
+				adminStories.GET("/:id", adminETag, h.Story.GetStoryByID)
 				adminStories.POST("", h.Story.CreateStory)
 				adminStories.PUT("/:id", h.Story.UpdateStory)
 				adminStories.DELETE("/:id", h.Story.DeleteStory)
 
 				// Admin Chapters (nested under stories)
-				adminStories.GET("/:id/chapters", h.Chapter.GetChaptersByStoryAdmin)
+				adminStories.GET("/:id/chapters", adminETag, h.Chapter.GetChaptersByStoryAdmin)
 				adminStories.POST("/:id/chapters", h.Chapter.CreateChapter)
 				adminStories.POST("/:id/chapters/bulk", h.Chapter.BulkImportChapters)
+				adminStories.PUT("/:id/chapters/reorder", h.Chapter.ReorderChapters)
+
+				// Admin Volumes (nested under stories)
+				if h.Volume != nil {
+					adminStories.GET("/:id/volumes", adminETag, h.Volume.GetVolumesByStory)
+					adminStories.POST("/:id/volumes", h.Volume.CreateVolume)
+					adminStories.PUT("/:id/volumes/move", h.Volume.MoveChapters)
+				}
+			}
+
+			// Admin Volumes
+			if h.Volume != nil {
+				adminVolumes := admin.Group("/volumes")
+				{
+					adminVolumes.PUT("/:id", h.Volume.UpdateVolume)
+					adminVolumes.DELETE("/:id", h.Volume.DeleteVolume)
+				}
 			}
 
 			// Admin Chapters
 			adminChapters := admin.Group("/chapters")
 			{
-				adminChapters.GET("/:id", h.Chapter.GetChapterByID)
+				adminChapters.GET("/:id", adminETag, h.Chapter.GetChapterByID)
 				adminChapters.PUT("/:id", h.Chapter.UpdateChapter)
 				adminChapters.DELETE("/:id", h.Chapter.DeleteChapter)
 				adminChapters.POST("/:id/publish", h.Chapter.PublishChapter)
 				adminChapters.POST("/:id/schedule", h.Chapter.ScheduleChapter)
+
+				// Revision history
+				if h.Revision != nil {
+					adminChapters.GET("/:id/revisions", adminETag, h.Revision.GetRevisions)
+					adminChapters.GET("/:id/revisions/diff", adminETag, h.Revision.DiffRevisions)
+					adminChapters.GET("/:id/revisions/:number", adminETag, h.Revision.GetRevision)
+					adminChapters.POST("/:id/revisions/:number/restore", h.Revision.RestoreRevision)
+				}
 			}
 
-			// Admin Media (Cloudinary uploads for stories/chapters)
+			// Admin Media (uploads for stories/chapters, storage theo STORAGE_DRIVER)
 			if h.Upload != nil {
 				adminMedia := admin.Group("/media")
 				{
 					adminMedia.POST("", h.Upload.UploadSingleImage)
 					adminMedia.POST("/chapter", h.Upload.UploadChapterImages)
+					adminMedia.POST("/chapter/archive", h.Upload.UploadChapterArchive)
 					adminMedia.DELETE("", h.Upload.DeleteImage)
+					if h.MediaGC != nil {
+						adminMedia.GET("/gc/report", h.MediaGC.GetReport) // Dry-run, không xóa gì
+					}
 				}
 			}
 
@@ -234,6 +357,38 @@ func SetupRoutes(r *gin.Engine, cfg *config.Config, h *Handlers) {
 				adminReports.GET("", h.Comment.GetReports)
 				adminReports.PUT("/:reportId", h.Comment.ResolveReport)
 			}
+
+			// Admin Chat moderation
+			if h.Chat != nil {
+				adminChat := admin.Group("/chat")
+				{
+					adminChat.DELETE("/messages/:id", h.Chat.DeleteMessage)
+					adminChat.PUT("/rooms/:roomId/slow-mode", h.Chat.SetSlowMode)
+					adminChat.GET("/mutes", h.Chat.GetMutes)
+					adminChat.POST("/mutes", h.Chat.MuteUser)
+					adminChat.DELETE("/mutes/:id", h.Chat.UnmuteUser)
+				}
+			}
+
+			// Admin Typo Reports
+			if h.TypoReport != nil {
+				adminTypoReports := admin.Group("/typo-reports")
+				{
+					adminTypoReports.GET("", h.TypoReport.GetTypoReports)
+					adminTypoReports.PUT("/:id", h.TypoReport.ResolveTypoReport)
+				}
+			}
+
+			// Admin Analytics (time-series cho dashboard)
+			if h.Analytics != nil {
+				adminAnalytics := admin.Group("/analytics")
+				{
+					adminAnalytics.GET("/views", h.Analytics.GetViewAnalytics)
+					adminAnalytics.GET("/chapters/top", h.Analytics.GetTopChapters)
+					adminAnalytics.GET("/comments", h.Analytics.GetCommentAnalytics)
+					adminAnalytics.GET("/bookmarks", h.Analytics.GetBookmarkAnalytics)
+				}
+			}
 		}
 
 		//realtime token endpoint
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"nekozanedex/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+const (
+	maxAnalyticsBuckets = 400 // Giới hạn số điểm trên 1 biểu đồ (~13 tháng theo ngày)
+	analyticsDateLayout = "2006-01-02"
+)
+
+// Analytics buckets
+const (
+	BucketDay   = "day"
+	BucketWeek  = "week"
+	BucketMonth = "month"
+)
+
+// AnalyticsQuery - Khoảng ngày (bao gồm cả 2 đầu) + bucket, StoryID nil = toàn site
+type AnalyticsQuery struct {
+	From    time.Time
+	To      time.Time
+	Bucket  string
+	StoryID *uuid.UUID
+}
+
+// ViewAnalytics - Time-series lượt xem + tổng của cả khoảng
+type ViewAnalytics struct {
+	From           string                    `json:"from"`
+	To             string                    `json:"to"`
+	Bucket         string                    `json:"bucket"`
+	Totals         repositories.ViewTotals   `json:"totals"`
+	AnonymousShare float64                   `json:"anonymous_share"` // 0..1, tỷ lệ lượt xem ẩn danh
+	Series         []repositories.ViewBucket `json:"series"`
+}
+
+// CountAnalytics - Time-series đếm số lượng (comments, bookmarks)
+type CountAnalytics struct {
+	From   string                     `json:"from"`
+	To     string                     `json:"to"`
+	Bucket string                     `json:"bucket"`
+	Total  int64                      `json:"total"`
+	Series []repositories.CountBucket `json:"series"`
+}
+
+type AnalyticsService interface {
+	GetViewAnalytics(q AnalyticsQuery) (*ViewAnalytics, error)
+	GetTopChapters(q AnalyticsQuery, limit int) ([]repositories.ChapterViewStat, error)
+	GetCommentAnalytics(q AnalyticsQuery) (*CountAnalytics, error)
+	GetBookmarkAnalytics(q AnalyticsQuery) (*CountAnalytics, error)
+}
+
+type analyticsService struct {
+	storyViewRepo repositories.StoryViewRepository
+	commentRepo   repositories.CommentRepository
+	bookmarkRepo  repositories.BookmarkRepository
+}
+
+func NewAnalyticsService(
+	storyViewRepo repositories.StoryViewRepository,
+	commentRepo repositories.CommentRepository,
+	bookmarkRepo repositories.BookmarkRepository,
+) AnalyticsService {
+	return &analyticsService{
+		storyViewRepo: storyViewRepo,
+		commentRepo:   commentRepo,
+		bookmarkRepo:  bookmarkRepo,
+	}
+}
+
+// GetViewAnalytics - Lượt xem, người đọc duy nhất, tỷ lệ ẩn danh/đăng nhập theo bucket
+func (s *analyticsService) GetViewAnalytics(q AnalyticsQuery) (*ViewAnalytics, error) {
+	ar, buckets, err := analyticsRange(q)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.storyViewRepo.GetViewSeries(ar)
+	if err != nil {
+		return nil, err
+	}
+	totals, err := s.storyViewRepo.GetViewTotals(ar)
+	if err != nil {
+		return nil, err
+	}
+
+	// Điền bucket trống bằng 0 để biểu đồ không bị đứt đoạn
+	byBucket := make(map[string]repositories.ViewBucket, len(rows))
+	for _, row := range rows {
+		byBucket[row.Bucket] = row
+	}
+	series := make([]repositories.ViewBucket, 0, len(buckets))
+	for _, bucket := range buckets {
+		row, ok := byBucket[bucket]
+		if !ok {
+			row = repositories.ViewBucket{Bucket: bucket}
+		}
+		series = append(series, row)
+	}
+
+	result := &ViewAnalytics{
+		From:   q.From.Format(analyticsDateLayout),
+		To:     q.To.Format(analyticsDateLayout),
+		Bucket: q.Bucket,
+		Totals: *totals,
+		Series: series,
+	}
+	if totals.Views > 0 {
+		result.AnonymousShare = float64(totals.AnonymousViews) / float64(totals.Views)
+	}
+	return result, nil
+}
+
+// GetTopChapters - Chapters nhiều lượt xem nhất trong khoảng
+func (s *analyticsService) GetTopChapters(q AnalyticsQuery, limit int) ([]repositories.ChapterViewStat, error) {
+	ar, _, err := analyticsRange(q)
+	if err != nil {
+		return nil, err
+	}
+	return s.storyViewRepo.GetTopChapters(ar, limit)
+}
+
+// GetCommentAnalytics - Comment mới theo bucket
+func (s *analyticsService) GetCommentAnalytics(q AnalyticsQuery) (*CountAnalytics, error) {
+	return countAnalytics(q, s.commentRepo.CountCommentsByBucket)
+}
+
+// GetBookmarkAnalytics - Bookmark mới theo bucket
+func (s *analyticsService) GetBookmarkAnalytics(q AnalyticsQuery) (*CountAnalytics, error) {
+	return countAnalytics(q, s.bookmarkRepo.CountBookmarksByBucket)
+}
+
+func countAnalytics(q AnalyticsQuery, load func(repositories.AnalyticsRange) ([]repositories.CountBucket, error)) (*CountAnalytics, error) {
+	ar, buckets, err := analyticsRange(q)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := load(ar)
+	if err != nil {
+		return nil, err
+	}
+
+	byBucket := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		byBucket[row.Bucket] = row.Count
+	}
+
+	result := &CountAnalytics{
+		From:   q.From.Format(analyticsDateLayout),
+		To:     q.To.Format(analyticsDateLayout),
+		Bucket: q.Bucket,
+		Series: make([]repositories.CountBucket, 0, len(buckets)),
+	}
+	for _, bucket := range buckets {
+		count := byBucket[bucket]
+		result.Total += count
+		result.Series = append(result.Series, repositories.CountBucket{Bucket: bucket, Count: count})
+	}
+	return result, nil
+}
+
+// analyticsRange - Validate query, trả về khoảng [From, To+1 ngày) và danh sách bucket (cùng quy tắc date_trunc của Postgres)
+func analyticsRange(q AnalyticsQuery) (repositories.AnalyticsRange, []string, error) {
+	from := truncateDay(q.From)
+	to := truncateDay(q.To)
+	if to.Before(from) {
+		return repositories.AnalyticsRange{}, nil, errors.New("ngày bắt đầu phải trước ngày kết thúc")
+	}
+
+	var start time.Time
+	var step func(time.Time) time.Time
+	switch q.Bucket {
+	case BucketDay:
+		start = from
+		step = func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+	case BucketWeek:
+		// date_trunc('week') bắt đầu từ thứ 2
+		start = from.AddDate(0, 0, -((int(from.Weekday()) + 6) % 7))
+		step = func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }
+	case BucketMonth:
+		start = time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, from.Location())
+		step = func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+	default:
+		return repositories.AnalyticsRange{}, nil, errors.New("bucket không hợp lệ (day, week, month)")
+	}
+
+	end := to.AddDate(0, 0, 1)
+	var buckets []string
+	for t := start; t.Before(end); t = step(t) {
+		if len(buckets) == maxAnalyticsBuckets {
+			return repositories.AnalyticsRange{}, nil, errors.New("khoảng thời gian quá dài cho bucket này")
+		}
+		buckets = append(buckets, t.Format(analyticsDateLayout))
+	}
+
+	return repositories.AnalyticsRange{
+		From:    from,
+		To:      end,
+		Bucket:  q.Bucket,
+		StoryID: q.StoryID,
+	}, buckets, nil
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"nekozanedex/internal/models"
+	"nekozanedex/internal/repositories"
+	"nekozanedex/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+type ChapterRevisionService interface {
+	GetRevisions(chapterID uuid.UUID) ([]models.ChapterRevision, error)
+	GetRevision(chapterID uuid.UUID, revisionNumber int) (*models.ChapterRevision, error)
+	DiffRevisions(chapterID uuid.UUID, fromNumber, toNumber int) (*RevisionDiff, error)
+	RestoreRevision(chapterID uuid.UUID, revisionNumber int, editorID uuid.UUID) (*models.ChapterRevision, error)
+}
+
+// RevisionDiff - Kết quả so sánh 2 revision
+type RevisionDiff struct {
+	From         *models.ChapterRevision `json:"from"`
+	To           *models.ChapterRevision `json:"to"`
+	TitleChanged bool                    `json:"title_changed"`
+	Content      []utils.DiffLine        `json:"content"`
+	Images       []utils.DiffLine        `json:"images"`
+	Added        int                     `json:"added"`
+	Removed      int                     `json:"removed"`
+}
+
+type chapterRevisionService struct {
+	revisionRepo repositories.ChapterRevisionRepository
+	chapterRepo  repositories.ChapterRepository
+	publicCache  *PublicCache
+}
+
+func NewChapterRevisionService(
+	revisionRepo repositories.ChapterRevisionRepository,
+	chapterRepo repositories.ChapterRepository,
+	publicCache *PublicCache,
+) ChapterRevisionService {
+	return &chapterRevisionService{
+		revisionRepo: revisionRepo,
+		chapterRepo:  chapterRepo,
+		publicCache:  publicCache,
+	}
+}
+
+// GetRevisions - Lịch sử chỉnh sửa của chapter (Admin)
+func (s *chapterRevisionService) GetRevisions(chapterID uuid.UUID) ([]models.ChapterRevision, error) {
+	if _, err := s.chapterRepo.FindByID(chapterID); err != nil {
+		return nil, errors.New("chapter không tồn tại")
+	}
+	return s.revisionRepo.GetRevisions(chapterID)
+}
+
+// GetRevision - Lấy nội dung 1 revision (Admin)
+func (s *chapterRevisionService) GetRevision(chapterID uuid.UUID, revisionNumber int) (*models.ChapterRevision, error) {
+	revision, err := s.revisionRepo.FindRevision(chapterID, revisionNumber)
+	if err != nil {
+		return nil, errors.New("revision không tồn tại")
+	}
+	return revision, nil
+}
+
+// DiffRevisions - So sánh nội dung 2 revision theo từng dòng (Admin)
+func (s *chapterRevisionService) DiffRevisions(chapterID uuid.UUID, fromNumber, toNumber int) (*RevisionDiff, error) {
+	from, err := s.revisionRepo.FindRevision(chapterID, fromNumber)
+	if err != nil {
+		return nil, errors.New("revision không tồn tại")
+	}
+	to, err := s.revisionRepo.FindRevision(chapterID, toNumber)
+	if err != nil {
+		return nil, errors.New("revision không tồn tại")
+	}
+
+	diff := &RevisionDiff{
+		TitleChanged: from.Title != to.Title,
+		Content:      utils.DiffLines(from.Content, to.Content),
+		Images:       utils.DiffLines(joinImages(from.Images), joinImages(to.Images)),
+	}
+	for _, line := range diff.Content {
+		switch line.Op {
+		case utils.DiffInsert:
+			diff.Added++
+		case utils.DiffDelete:
+			diff.Removed++
+		}
+	}
+
+	// Không trả lại toàn bộ nội dung, diff đã chứa đủ thông tin
+	from.Content, from.Images = "", nil
+	to.Content, to.Images = "", nil
+	diff.From, diff.To = from, to
+
+	return diff, nil
+}
+
+// RestoreRevision - Khôi phục nội dung 1 revision cũ, lưu thành revision mới (Admin)
+func (s *chapterRevisionService) RestoreRevision(chapterID uuid.UUID, revisionNumber int, editorID uuid.UUID) (*models.ChapterRevision, error) {
+	chapter, err := s.chapterRepo.FindByID(chapterID)
+	if err != nil {
+		return nil, errors.New("chapter không tồn tại")
+	}
+	revision, err := s.revisionRepo.FindRevision(chapterID, revisionNumber)
+	if err != nil {
+		return nil, errors.New("revision không tồn tại")
+	}
+
+	chapter.Title = revision.Title
+	chapter.Content = revision.Content
+	chapter.Images = revision.Images
+	chapter.PageCount = countImages(revision.Images)
+	chapter.UpdatedAt = time.Now()
+
+	note := fmt.Sprintf("Khôi phục từ revision #%d", revision.RevisionNumber)
+	restored, err := s.revisionRepo.SaveChapterWithRevision(chapter, &editorID, &note)
+	if err != nil {
+		return nil, err
+	}
+	s.publicCache.invalidateStory(chapter.Story.Slug)
+	return restored, nil
+}
+
+// joinImages - Danh sách ảnh thành text (mỗi URL 1 dòng) để diff
+func joinImages(imagesJSON []byte) string {
+	chapter := models.Chapter{Images: imagesJSON}
+	return strings.Join(chapter.GetImagesSlice(), "\n")
+}
@@ -3,47 +3,124 @@ package services
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"nekozanedex/internal/models"
 	"nekozanedex/internal/repositories"
+	"nekozanedex/internal/utils"
+	"nekozanedex/pkg/importer"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type ChapterService interface {
 	// Public methods
-	GetChapterByNumber(storySlug string, chapterNumber int) (*models.Chapter, error)
+	GetChapterByNumber(storySlug string, chapterNumber int, userID *uuid.UUID) (*ChapterReaderView, error)
+	RecordChapterView(chapter *models.Chapter, userID *uuid.UUID, ipAddress, userAgent string) error // Fair view counting
 	GetChaptersByStory(storySlug string) ([]models.Chapter, error)
 	GetChaptersByStoryPaginated(storySlug string, page, limit int) ([]models.Chapter, int64, error)
+	GetChaptersGroupedByVolume(storySlug string) ([]models.Volume, []models.Chapter, error) // volumes (kèm chapters) + chapters chưa thuộc tập
 
 	// Admin methods
 	CreateChapter(storyID uuid.UUID, chapter *models.Chapter) error
-	UpdateChapter(id uuid.UUID, chapter *models.Chapter) error
+	InsertChapter(storyID uuid.UUID, chapter *models.Chapter, afterNumber int) error // Chèn giữa 2 chapter (vd: extra 12.5)
+	ReorderChapters(storyID uuid.UUID, orderedIDs []uuid.UUID) error
+	UpdateChapter(id uuid.UUID, chapter *models.Chapter, editorID uuid.UUID) error
+	SetChapterImages(id uuid.UUID, images []string, editorID uuid.UUID) error
 	DeleteChapter(id uuid.UUID) error
 	GetChapterByID(id uuid.UUID) (*models.Chapter, error)
 	GetChaptersByStoryAdmin(storyID uuid.UUID) ([]models.Chapter, error) // All chapters including drafts
 	PublishChapter(id uuid.UUID) error
 	ScheduleChapter(id uuid.UUID, scheduledAt time.Time) error
 	BulkImportChapters(storyID uuid.UUID, chapters []models.Chapter) error
+	PreviewImportFile(storyID uuid.UUID, filename string, data []byte, pattern string) (*ChapterImportPreview, error)
+	ImportFile(storyID uuid.UUID, filename string, data []byte, pattern string) (*ChapterImportPreview, error)
 	
 	// Scheduler methods
 	PublishScheduledChapters() (int, error) // Returns count of published chapters
 }
 
+// ChapterNavItem - Thông tin gọn của chapter kề bên để điều hướng
+type ChapterNavItem struct {
+	ID            uuid.UUID `json:"id"`
+	ChapterNumber int       `json:"chapter_number"`
+	ChapterLabel  *string   `json:"chapter_label"`
+	ChapterType   string    `json:"chapter_type"`
+	Title         string    `json:"title"`
+}
+
+// ChapterStoryInfo - Thông tin truyện hiển thị trên trang đọc
+type ChapterStoryInfo struct {
+	ID            uuid.UUID `json:"id"`
+	Title         string    `json:"title"`
+	Slug          string    `json:"slug"`
+	CoverImageURL *string   `json:"cover_image_url"`
+}
+
+// ChapterReaderView - Chapter kèm ngữ cảnh cho trang đọc
+// Nhúng Chapter để giữ nguyên các field cũ của response
+type ChapterReaderView struct {
+	*models.Chapter
+	Story          ChapterStoryInfo `json:"story"`
+	PrevChapter    *ChapterNavItem  `json:"prev_chapter"`
+	NextChapter    *ChapterNavItem  `json:"next_chapter"`
+	ScrollPosition *int             `json:"scroll_position"` // Vị trí đã lưu, chỉ có khi đăng nhập và đang đọc dở chapter này
+}
+
+// ChapterImportPreview - Kết quả tách file import (dry-run)
+type ChapterImportPreview struct {
+	Format      string              `json:"format"`
+	StartNumber int                 `json:"start_number"` // Số chapter sẽ được gán cho chapter đầu tiên
+	Total       int                 `json:"total"`
+	Chapters    []ChapterImportItem `json:"chapters"`
+	Warnings    []string            `json:"warnings"`
+}
+
+// ChapterImportItem - 1 chapter tách được, kèm số sẽ gán khi import
+type ChapterImportItem struct {
+	Index          int    `json:"index"`
+	DetectedNumber int    `json:"detected_number,omitempty"` // Số đọc được từ tiêu đề
+	AssignedNumber int    `json:"assigned_number"`
+	Title          string `json:"title"`
+	Characters     int    `json:"characters"`
+	Excerpt        string `json:"excerpt"`
+}
+
 type chapterService struct {
-	chapterRepo repositories.ChapterRepository
-	storyRepo   repositories.StoryRepository
+	chapterRepo    repositories.ChapterRepository
+	storyRepo      repositories.StoryRepository
+	revisionRepo   repositories.ChapterRevisionRepository
+	volumeRepo     repositories.VolumeRepository
+	historyRepo    repositories.ReadingHistoryRepository
+	viewAggregator ViewAggregator
+
+	notificationService NotificationService
+	publicCache         *PublicCache
 }
 
 func NewChapterService(
 	chapterRepo repositories.ChapterRepository,
 	storyRepo repositories.StoryRepository,
+	revisionRepo repositories.ChapterRevisionRepository,
+	volumeRepo repositories.VolumeRepository,
+	historyRepo repositories.ReadingHistoryRepository,
+	viewAggregator ViewAggregator,
+	notificationService NotificationService,
+	publicCache *PublicCache,
 ) ChapterService {
 	return &chapterService{
-		chapterRepo: chapterRepo,
-		storyRepo:   storyRepo,
+		chapterRepo:         chapterRepo,
+		storyRepo:           storyRepo,
+		revisionRepo:        revisionRepo,
+		volumeRepo:          volumeRepo,
+		historyRepo:         historyRepo,
+		viewAggregator:      viewAggregator,
+		notificationService: notificationService,
+		publicCache:         publicCache,
 	}
 }
 
@@ -60,9 +137,12 @@ func (s *chapterService) CreateChapter(storyID uuid.UUID, chapter *models.Chapte
 		return errors.New("truyện không tồn tại")
 	}
 
-	// Set chapter info
+	if err := s.validateVolume(storyID, chapter.VolumeID); err != nil {
+		return err
+	}
+
+	// Set chapter info - Số chapter do repo cấp trong transaction (max + 1)
 	chapter.StoryID = storyID
-	chapter.ChapterNumber = story.TotalChapters + 1
 	
 	// Calculate page count from images
 	chapter.PageCount = countImages(chapter.Images)
@@ -70,19 +150,104 @@ func (s *chapterService) CreateChapter(storyID uuid.UUID, chapter *models.Chapte
 	chapter.ViewCount = 0
 	chapter.CreatedAt = time.Now()
 	chapter.UpdatedAt = time.Now()
+	if chapter.IsPublished && chapter.PublishedAt == nil {
+		chapter.PublishedAt = &chapter.CreatedAt
+	}
+
+	// Tạo chapter + tính lại TotalChapters từ số row thật
+	if err := s.chapterRepo.AppendChapters(storyID, []*models.Chapter{chapter}); err != nil {
+		return err
+	}
+	s.publicCache.invalidateStory(story.Slug)
+
+	if chapter.IsPublished {
+		s.notifyNewChapter(story, chapter)
+	}
+	return nil
+}
+
+// InsertChapter - Chèn chapter (extra 12.5...) vào thứ tự đọc ngay sau chapter số afterNumber (Admin)
+// Số các chapter khác giữ nguyên, chapter mới nhận số tiếp theo; Ordering mặc định nằm giữa 2 chapter kề bên
+func (s *chapterService) InsertChapter(storyID uuid.UUID, chapter *models.Chapter, afterNumber int) error {
+	if strings.TrimSpace(chapter.Title) == "" {
+		return errors.New("tiêu đề chapter không được để trống")
+	}
+	if afterNumber < 0 {
+		return errors.New("chapter chèn sau không tồn tại")
+	}
+
+	story, err := s.storyRepo.FindStoryByID(storyID)
+	if err != nil {
+		return errors.New("truyện không tồn tại")
+	}
+	if err := s.validateVolume(storyID, chapter.VolumeID); err != nil {
+		return err
+	}
+
+	chapter.StoryID = storyID
+	chapter.PageCount = countImages(chapter.Images)
+	chapter.ViewCount = 0
+	chapter.CreatedAt = time.Now()
+	chapter.UpdatedAt = time.Now()
+	if chapter.IsPublished && chapter.PublishedAt == nil {
+		chapter.PublishedAt = &chapter.CreatedAt
+	}
 
-	if err := s.chapterRepo.Create(chapter); err != nil {
+	if err := s.chapterRepo.InsertChapterAfter(chapter, afterNumber); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("chapter chèn sau không tồn tại")
+		}
 		return err
 	}
+	s.publicCache.invalidateStory(story.Slug)
 
-	// Update story total chapters
-	story.TotalChapters++
-	story.UpdatedAt = time.Now()
-	return s.storyRepo.UpdateStory(story)
+	if chapter.IsPublished {
+		s.notifyNewChapter(story, chapter)
+	}
+	return nil
+}
+
+// ReorderChapters - Đánh số lại toàn bộ chapters theo danh sách ID đã sắp xếp (Admin)
+// Danh sách phải chứa đủ và đúng các chapter của truyện
+func (s *chapterService) ReorderChapters(storyID uuid.UUID, orderedIDs []uuid.UUID) error {
+	story, err := s.storyRepo.FindStoryByID(storyID)
+	if err != nil {
+		return errors.New("truyện không tồn tại")
+	}
+
+	chapters, err := s.chapterRepo.GetByStory(storyID, false)
+	if err != nil {
+		return err
+	}
+	if len(orderedIDs) != len(chapters) {
+		return errors.New("danh sách phải gồm đủ tất cả chapters của truyện")
+	}
+
+	existing := make(map[uuid.UUID]bool, len(chapters))
+	for _, chapter := range chapters {
+		existing[chapter.ID] = true
+	}
+	seen := make(map[uuid.UUID]bool, len(orderedIDs))
+	for _, id := range orderedIDs {
+		if !existing[id] {
+			return errors.New("chapter không thuộc truyện này")
+		}
+		if seen[id] {
+			return errors.New("chapter bị lặp trong danh sách")
+		}
+		seen[id] = true
+	}
+
+	if err := s.chapterRepo.RenumberChapters(storyID, orderedIDs); err != nil {
+		return err
+	}
+	s.publicCache.invalidateStory(story.Slug)
+	return nil
 }
 
 // UpdateChapter - Cập nhật chapter (Admin)
-func (s *chapterService) UpdateChapter(id uuid.UUID, updatedChapter *models.Chapter) error {
+// Mỗi lần cập nhật đều lưu 1 revision kèm người sửa
+func (s *chapterService) UpdateChapter(id uuid.UUID, updatedChapter *models.Chapter, editorID uuid.UUID) error {
 	existingChapter, err := s.chapterRepo.FindByID(id)
 	if err != nil {
 		return errors.New("chapter không tồn tại")
@@ -104,27 +269,45 @@ func (s *chapterService) UpdateChapter(id uuid.UUID, updatedChapter *models.Chap
 	}
 	existingChapter.UpdatedAt = time.Now()
 
-	return s.chapterRepo.Update(existingChapter)
+	if _, err := s.revisionRepo.SaveChapterWithRevision(existingChapter, &editorID, nil); err != nil {
+		return err
+	}
+	s.publicCache.invalidateStory(existingChapter.Story.Slug)
+	return nil
 }
 
-// DeleteChapter - Xóa chapter (Admin)
-func (s *chapterService) DeleteChapter(id uuid.UUID) error {
+// SetChapterImages - Thay toàn bộ danh sách trang ảnh của chapter (Admin, có lưu revision)
+func (s *chapterService) SetChapterImages(id uuid.UUID, images []string, editorID uuid.UUID) error {
 	chapter, err := s.chapterRepo.FindByID(id)
 	if err != nil {
 		return errors.New("chapter không tồn tại")
 	}
 
-	if err := s.chapterRepo.Delete(id); err != nil {
+	if err := chapter.SetImagesFromSlice(images); err != nil {
 		return err
 	}
+	chapter.UpdatedAt = time.Now()
 
-	// Update story total chapters
-	story, _ := s.storyRepo.FindStoryByID(chapter.StoryID)
-	if story != nil {
-		story.TotalChapters--
-		story.UpdatedAt = time.Now()
-		_ = s.storyRepo.UpdateStory(story)
+	note := fmt.Sprintf("Cập nhật %d trang ảnh", len(images))
+	if _, err := s.revisionRepo.SaveChapterWithRevision(chapter, &editorID, &note); err != nil {
+		return err
 	}
+	s.publicCache.invalidateStory(chapter.Story.Slug)
+	return nil
+}
+
+// DeleteChapter - Xóa chapter (Admin)
+func (s *chapterService) DeleteChapter(id uuid.UUID) error {
+	chapter, err := s.chapterRepo.FindByID(id)
+	if err != nil {
+		return errors.New("chapter không tồn tại")
+	}
+
+	// Xóa + tính lại TotalChapters từ số row thật
+	if err := s.chapterRepo.DeleteAndRecount(chapter); err != nil {
+		return err
+	}
+	s.publicCache.invalidateStory(chapter.Story.Slug)
 
 	return nil
 }
@@ -138,9 +321,11 @@ func (s *chapterService) GetChapterByID(id uuid.UUID) (*models.Chapter, error) {
 	return chapter, nil
 }
 
-// GetChapterByNumber - Lấy chapter theo số (Public)
-func (s *chapterService) GetChapterByNumber(storySlug string, chapterNumber int) (*models.Chapter, error) {
-	story, err := s.storyRepo.FindStoryBySlug(storySlug)
+// GetChapterByNumber - Lấy chapter theo số kèm ngữ cảnh trang đọc (Public)
+// userID != nil thì trả thêm vị trí cuộn đã lưu
+// Note: Does NOT increment view count - call RecordChapterView separately
+func (s *chapterService) GetChapterByNumber(storySlug string, chapterNumber int, userID *uuid.UUID) (*ChapterReaderView, error) {
+	story, err := s.storyRepo.FindPublishedStoryBySlugLite(storySlug)
 	if err != nil {
 		return nil, errors.New("truyện không tồn tại")
 	}
@@ -150,29 +335,71 @@ func (s *chapterService) GetChapterByNumber(storySlug string, chapterNumber int)
 		return nil, errors.New("chapter không tồn tại")
 	}
 
-	// Increment view count
-	_ = s.chapterRepo.IncrementViewCount(chapter.ID)
+	view := &ChapterReaderView{
+		Chapter: chapter,
+		Story: ChapterStoryInfo{
+			ID:            story.ID,
+			Title:         story.Title,
+			Slug:          story.Slug,
+			CoverImageURL: story.CoverImageURL,
+		},
+	}
 
-	return chapter, nil
+	prev, next, err := s.chapterRepo.FindPublishedNeighbours(chapter)
+	if err != nil {
+		return nil, err
+	}
+	view.PrevChapter = toChapterNavItem(prev)
+	view.NextChapter = toChapterNavItem(next)
+
+	if userID != nil {
+		history, err := s.historyRepo.GetByUserAndStory(*userID, story.ID)
+		if err == nil && history.ChapterID == chapter.ID {
+			position := history.ScrollPosition
+			view.ScrollPosition = &position
+		}
+	}
+
+	return view, nil
+}
+
+// RecordChapterView - Fair view counting cho chapter (1 view per user/IP per 24h, bỏ qua bot)
+// Ghi story_views kèm chapter_id (gom theo lô bởi ViewAggregator) để analytics thống kê được theo chapter
+func (s *chapterService) RecordChapterView(chapter *models.Chapter, userID *uuid.UUID, ipAddress, userAgent string) error {
+	if utils.IsBotUserAgent(userAgent) {
+		return nil
+	}
+
+	s.viewAggregator.Record(chapter.StoryID, &chapter.ID, userID, ipAddress)
+	return nil
 }
 
 // GetChaptersByStory - Lấy danh sách chapters của truyện (Public - only published)
 func (s *chapterService) GetChaptersByStory(storySlug string) ([]models.Chapter, error) {
-	story, err := s.storyRepo.FindStoryBySlug(storySlug)
-	if err != nil {
-		return nil, errors.New("truyện không tồn tại")
-	}
+	return cached(s.publicCache, chapterListCacheKey(storySlug, "all"), func() ([]models.Chapter, error) {
+		story, err := s.storyRepo.FindPublishedStoryBySlugLite(storySlug)
+		if err != nil {
+			return nil, errors.New("truyện không tồn tại")
+		}
+
+		return s.chapterRepo.GetByStory(story.ID, true)
+	})
+}
 
-	return s.chapterRepo.GetByStory(story.ID, true)
+// chapterPage - 1 trang chapters kèm tổng số, để cache chung 1 key
+type chapterPage struct {
+	Chapters []models.Chapter `json:"chapters"`
+	Total    int64            `json:"total"`
+}
+
+// chapterVolumes - Chapters nhóm theo tập, để cache chung 1 key
+type chapterVolumes struct {
+	Volumes   []models.Volume  `json:"volumes"`
+	Ungrouped []models.Chapter `json:"ungrouped"`
 }
 
 // GetChaptersByStoryPaginated - Lấy chapters với phân trang (Public)
 func (s *chapterService) GetChaptersByStoryPaginated(storySlug string, page, limit int) ([]models.Chapter, int64, error) {
-	story, err := s.storyRepo.FindStoryBySlug(storySlug)
-	if err != nil {
-		return nil, 0, errors.New("truyện không tồn tại")
-	}
-
 	if page < 1 {
 		page = 1
 	}
@@ -180,8 +407,75 @@ func (s *chapterService) GetChaptersByStoryPaginated(storySlug string, page, lim
 		limit = 100
 	}
 
-	offset := (page - 1) * limit
-	return s.chapterRepo.GetByStoryPaginated(story.ID, true, offset, limit)
+	key := chapterListCacheKey(storySlug, fmt.Sprintf("page:%d:%d", page, limit))
+	result, err := cached(s.publicCache, key, func() (*chapterPage, error) {
+		story, err := s.storyRepo.FindPublishedStoryBySlugLite(storySlug)
+		if err != nil {
+			return nil, errors.New("truyện không tồn tại")
+		}
+
+		offset := (page - 1) * limit
+		chapters, total, err := s.chapterRepo.GetByStoryPaginated(story.ID, true, offset, limit)
+		if err != nil {
+			return nil, err
+		}
+		return &chapterPage{Chapters: chapters, Total: total}, nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return result.Chapters, result.Total, nil
+}
+
+// GetChaptersGroupedByVolume - Lấy chapters nhóm theo tập (Public - only published)
+func (s *chapterService) GetChaptersGroupedByVolume(storySlug string) ([]models.Volume, []models.Chapter, error) {
+	result, err := cached(s.publicCache, chapterListCacheKey(storySlug, "volumes"), func() (*chapterVolumes, error) {
+		volumes, ungrouped, err := s.groupChaptersByVolume(storySlug)
+		if err != nil {
+			return nil, err
+		}
+		return &chapterVolumes{Volumes: volumes, Ungrouped: ungrouped}, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return result.Volumes, result.Ungrouped, nil
+}
+
+// groupChaptersByVolume - Đọc DB cho GetChaptersGroupedByVolume
+func (s *chapterService) groupChaptersByVolume(storySlug string) ([]models.Volume, []models.Chapter, error) {
+	story, err := s.storyRepo.FindPublishedStoryBySlugLite(storySlug)
+	if err != nil {
+		return nil, nil, errors.New("truyện không tồn tại")
+	}
+
+	volumes, err := s.volumeRepo.GetVolumesByStory(story.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	chapters, err := s.chapterRepo.GetByStory(story.ID, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	volumeIndex := make(map[uuid.UUID]int, len(volumes))
+	for i := range volumes {
+		volumes[i].Chapters = []models.Chapter{}
+		volumeIndex[volumes[i].ID] = i
+	}
+
+	ungrouped := []models.Chapter{}
+	for _, chapter := range chapters {
+		if chapter.VolumeID != nil {
+			if i, ok := volumeIndex[*chapter.VolumeID]; ok {
+				volumes[i].Chapters = append(volumes[i].Chapters, chapter)
+				continue
+			}
+		}
+		ungrouped = append(ungrouped, chapter)
+	}
+
+	return volumes, ungrouped, nil
 }
 
 // GetChaptersByStoryAdmin - Lấy tất cả chapters (Admin - including drafts)
@@ -201,12 +495,23 @@ func (s *chapterService) PublishChapter(id uuid.UUID) error {
 		return errors.New("chapter không tồn tại")
 	}
 
+	wasPublished := chapter.IsPublished
+
 	now := time.Now()
 	chapter.IsPublished = true
 	chapter.PublishedAt = &now
 	chapter.UpdatedAt = now
 
-	return s.chapterRepo.Update(chapter)
+	if err := s.chapterRepo.Update(chapter); err != nil {
+		return err
+	}
+	s.publicCache.invalidateStory(chapter.Story.Slug)
+
+	// Chỉ thông báo lần đầu xuất bản, publish lại không gửi trùng
+	if !wasPublished {
+		s.notifyNewChapter(&chapter.Story, chapter)
+	}
+	return nil
 }
 
 // ScheduleChapter - Hẹn giờ xuất bản (Admin)
@@ -223,7 +528,11 @@ func (s *chapterService) ScheduleChapter(id uuid.UUID, scheduledAt time.Time) er
 	chapter.ScheduledAt = &scheduledAt
 	chapter.UpdatedAt = time.Now()
 
-	return s.chapterRepo.Update(chapter)
+	if err := s.chapterRepo.Update(chapter); err != nil {
+		return err
+	}
+	s.publicCache.invalidateStory(chapter.Story.Slug)
+	return nil
 }
 
 // BulkImportChapters - Import nhiều chapters cùng lúc (Admin)
@@ -233,24 +542,97 @@ func (s *chapterService) BulkImportChapters(storyID uuid.UUID, chapters []models
 		return errors.New("truyện không tồn tại")
 	}
 
-	startNumber := story.TotalChapters + 1
-
+	batch := make([]*models.Chapter, len(chapters))
 	for i := range chapters {
-		chapters[i].StoryID = storyID
-		chapters[i].ChapterNumber = startNumber + i
 		chapters[i].PageCount = countImages(chapters[i].Images)
 		chapters[i].CreatedAt = time.Now()
 		chapters[i].UpdatedAt = time.Now()
+		batch[i] = &chapters[i]
+	}
 
-		if err := s.chapterRepo.Create(&chapters[i]); err != nil {
-			return err
+	// Cả lô trong 1 transaction: số chapter liên tiếp, TotalChapters tính lại từ số row thật
+	if err := s.chapterRepo.AppendChapters(storyID, batch); err != nil {
+		return err
+	}
+	s.publicCache.invalidateStory(story.Slug)
+	return nil
+}
+
+// PreviewImportFile - Tách file EPUB/DOCX/ZIP/text thành chapters, chưa lưu gì (Admin)
+func (s *chapterService) PreviewImportFile(storyID uuid.UUID, filename string, data []byte, pattern string) (*ChapterImportPreview, error) {
+	preview, _, err := s.parseImportFile(storyID, filename, data, pattern)
+	return preview, err
+}
+
+// ImportFile - Tách file và import qua BulkImportChapters (Admin)
+func (s *chapterService) ImportFile(storyID uuid.UUID, filename string, data []byte, pattern string) (*ChapterImportPreview, error) {
+	preview, chapters, err := s.parseImportFile(storyID, filename, data, pattern)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.BulkImportChapters(storyID, chapters); err != nil {
+		return nil, err
+	}
+	return preview, nil
+}
+
+// parseImportFile - Dùng chung cho dry-run và import thật để 2 bước cho ra cùng 1 kết quả
+func (s *chapterService) parseImportFile(storyID uuid.UUID, filename string, data []byte, pattern string) (*ChapterImportPreview, []models.Chapter, error) {
+	if _, err := s.storyRepo.FindStoryByID(storyID); err != nil {
+		return nil, nil, errors.New("truyện không tồn tại")
+	}
+
+	re, err := importer.CompilePattern(pattern)
+	if err != nil {
+		return nil, nil, err
+	}
+	format, parsed, err := importer.Parse(filename, data, re)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	maxNumber, err := s.chapterRepo.GetMaxChapterNumber(storyID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	preview := &ChapterImportPreview{
+		Format:      format,
+		StartNumber: maxNumber + 1,
+		Total:       len(parsed),
+		Chapters:    make([]ChapterImportItem, 0, len(parsed)),
+		Warnings:    []string{},
+	}
+	chapters := make([]models.Chapter, 0, len(parsed))
+
+	lastDetected := 0
+	for i, p := range parsed {
+		title := truncateRunes(strings.TrimSpace(p.Title), 255)
+		item := ChapterImportItem{
+			Index:          i + 1,
+			DetectedNumber: p.Number,
+			AssignedNumber: maxNumber + 1 + i,
+			Title:          title,
+			Characters:     utf8.RuneCountInString(p.Content),
+			Excerpt:        truncateRunes(strings.Join(strings.Fields(p.Content), " "), 200),
+		}
+		preview.Chapters = append(preview.Chapters, item)
+
+		if p.Number > 0 {
+			if lastDetected > 0 && p.Number != lastDetected+1 {
+				preview.Warnings = append(preview.Warnings,
+					fmt.Sprintf("#%d \"%s\": số chương %d không liền sau %d", item.Index, title, p.Number, lastDetected))
+			}
+			lastDetected = p.Number
 		}
+		if strings.TrimSpace(p.Content) == "" {
+			preview.Warnings = append(preview.Warnings, fmt.Sprintf("#%d \"%s\": nội dung trống", item.Index, title))
+		}
+
+		chapters = append(chapters, models.Chapter{Title: title, Content: p.Content})
 	}
 
-	// Update story total
-	story.TotalChapters += len(chapters)
-	story.UpdatedAt = time.Now()
-	return s.storyRepo.UpdateStory(story)
+	return preview, chapters, nil
 }
 
 // PublishScheduledChapters - Auto-publish chapters that have reached their scheduled time
@@ -262,6 +644,7 @@ func (s *chapterService) PublishScheduledChapters() (int, error) {
 
 	count := 0
 	now := time.Now()
+	stories := make(map[uuid.UUID]*models.Story)
 	for _, chapter := range chapters {
 		chapter.IsPublished = true
 		chapter.PublishedAt = &now
@@ -272,11 +655,76 @@ func (s *chapterService) PublishScheduledChapters() (int, error) {
 			continue // Log error but continue with other chapters
 		}
 		count++
+
+		story, ok := stories[chapter.StoryID]
+		if !ok {
+			story, _ = s.storyRepo.FindStoryByID(chapter.StoryID)
+			stories[chapter.StoryID] = story
+		}
+		if story != nil {
+			s.publicCache.invalidateStory(story.Slug)
+			s.notifyNewChapter(story, &chapter)
+		}
 	}
 
 	return count, nil
 }
 
+// notifyNewChapter - Báo chapter mới cho người đã bookmark (fan-out chạy nền)
+// Truyện chưa xuất bản thì không báo vì người đọc chưa truy cập được
+func (s *chapterService) notifyNewChapter(story *models.Story, chapter *models.Chapter) {
+	if s.notificationService == nil || !story.IsPublished {
+		return
+	}
+	s.notificationService.NotifyBookmarkers(NewChapterEvent{
+		StoryID:       story.ID,
+		StoryTitle:    story.Title,
+		StorySlug:     story.Slug,
+		ChapterNumber: chapter.ChapterNumber,
+		ChapterLabel:  chapter.ChapterLabel,
+	})
+}
+
+// validateVolume - Kiểm tra tập (nếu có) thuộc đúng truyện
+func (s *chapterService) validateVolume(storyID uuid.UUID, volumeID *uuid.UUID) error {
+	if volumeID == nil {
+		return nil
+	}
+	volume, err := s.volumeRepo.FindVolumeByID(*volumeID)
+	if err != nil || volume.StoryID != storyID {
+		return errors.New("tập không tồn tại")
+	}
+	return nil
+}
+
+// orderingOf - Vị trí sort của chapter, chapter cũ chưa có Ordering thì dùng ChapterNumber
+func orderingOf(chapter *models.Chapter) float64 {
+	if chapter.Ordering > 0 {
+		return chapter.Ordering
+	}
+	return float64(chapter.ChapterNumber)
+}
+
+func toChapterNavItem(chapter *models.Chapter) *ChapterNavItem {
+	if chapter == nil {
+		return nil
+	}
+	return &ChapterNavItem{
+		ID:            chapter.ID,
+		ChapterNumber: chapter.ChapterNumber,
+		ChapterLabel:  chapter.ChapterLabel,
+		ChapterType:   chapter.ChapterType,
+		Title:         chapter.Title,
+	}
+}
+
+func truncateRunes(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max])
+}
+
 // Helper function to count images from JSON
 func countImages(imagesJSON []byte) int {
 	if imagesJSON == nil {
@@ -0,0 +1,278 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"nekozanedex/internal/centrifugo"
+	"nekozanedex/internal/models"
+	"nekozanedex/internal/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	chatRoomGeneral         = "general"
+	chatStoryRoomPrefix     = "story-"
+	chatMaxMessageLength    = 500
+	defaultChatSlowModeSecs = 3 // Áp dụng khi phòng chưa cấu hình slow-mode
+	maxChatSlowModeSecs     = 3600
+)
+
+type ChatService interface {
+	GetMessages(roomID string, page, limit int) ([]models.ChatMessage, int64, error)
+	SendMessage(userID uuid.UUID, roomID, content string, isAdmin bool) (*models.ChatMessage, error)
+	DeleteMessage(id uuid.UUID) error
+
+	// Moderation (Admin)
+	GetRoomSetting(roomID string) (*models.ChatRoomSetting, error)
+	SetSlowMode(roomID string, seconds int) (*models.ChatRoomSetting, error)
+	MuteUser(adminID, userID uuid.UUID, roomID *string, duration time.Duration, reason *string) (*models.ChatMute, error)
+	UnmuteUser(muteID uuid.UUID) error
+	GetActiveMutes(page, limit int) ([]models.ChatMute, int64, error)
+}
+
+type chatService struct {
+	chatRepo         repositories.ChatRepository
+	storyRepo        repositories.StoryRepository
+	centrifugoClient *centrifugo.Client
+}
+
+func NewChatService(
+	chatRepo repositories.ChatRepository,
+	storyRepo repositories.StoryRepository,
+	centrifugoClient *centrifugo.Client,
+) ChatService {
+	return &chatService{
+		chatRepo:         chatRepo,
+		storyRepo:        storyRepo,
+		centrifugoClient: centrifugoClient,
+	}
+}
+
+// chatChannel - Tên channel Centrifugo của phòng chat
+func chatChannel(roomID string) string {
+	return "chat:" + roomID
+}
+
+// GetMessages - Lấy lịch sử chat của phòng
+func (s *chatService) GetMessages(roomID string, page, limit int) ([]models.ChatMessage, int64, error) {
+	if err := s.validateRoom(roomID); err != nil {
+		return nil, 0, err
+	}
+	return s.chatRepo.GetMessagesByRoom(roomID, page, limit)
+}
+
+// SendMessage - Lưu tin nhắn rồi publish lên channel chat:<room>
+func (s *chatService) SendMessage(userID uuid.UUID, roomID, content string, isAdmin bool) (*models.ChatMessage, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, errors.New("nội dung tin nhắn không được để trống")
+	}
+	if utf8.RuneCountInString(content) > chatMaxMessageLength {
+		return nil, fmt.Errorf("tin nhắn quá dài (tối đa %d ký tự)", chatMaxMessageLength)
+	}
+
+	if err := s.validateRoom(roomID); err != nil {
+		return nil, err
+	}
+
+	// Admin không bị giới hạn bởi mute/slow-mode
+	if !isAdmin {
+		mute, err := s.chatRepo.GetActiveMute(userID, roomID)
+		if err != nil {
+			return nil, err
+		}
+		if mute != nil {
+			if mute.MutedUntil != nil {
+				return nil, fmt.Errorf("bạn bị cấm chat đến %s", mute.MutedUntil.Format("15:04 02/01/2006"))
+			}
+			return nil, errors.New("bạn bị cấm chat")
+		}
+	}
+
+	message := &models.ChatMessage{
+		UserID:  userID,
+		RoomID:  roomID,
+		Content: content,
+	}
+	if err := s.createMessage(message, isAdmin); err != nil {
+		return nil, err
+	}
+
+	// Fetch lại message với User preloaded
+	if created, err := s.chatRepo.FindMessageByID(message.ID); err == nil {
+		message = created
+	}
+
+	s.publish(roomID, map[string]interface{}{
+		"type":    "new_message",
+		"message": message,
+	})
+
+	return message, nil
+}
+
+// DeleteMessage - Xóa tin nhắn (Admin)
+func (s *chatService) DeleteMessage(id uuid.UUID) error {
+	message, err := s.chatRepo.FindMessageByID(id)
+	if err != nil {
+		return errors.New("tin nhắn không tồn tại")
+	}
+
+	if err := s.chatRepo.DeleteMessage(id); err != nil {
+		return err
+	}
+
+	s.publish(message.RoomID, map[string]interface{}{
+		"type": "delete_message",
+		"id":   id.String(),
+	})
+
+	return nil
+}
+
+// GetRoomSetting - Lấy cấu hình phòng (trả về mặc định nếu chưa cấu hình)
+func (s *chatService) GetRoomSetting(roomID string) (*models.ChatRoomSetting, error) {
+	if err := s.validateRoom(roomID); err != nil {
+		return nil, err
+	}
+
+	setting, err := s.chatRepo.GetRoomSetting(roomID)
+	if err != nil {
+		return nil, err
+	}
+	if setting == nil {
+		setting = &models.ChatRoomSetting{RoomID: roomID, SlowModeSeconds: defaultChatSlowModeSecs}
+	}
+	return setting, nil
+}
+
+// SetSlowMode - Đặt slow-mode cho phòng (0 = tắt)
+func (s *chatService) SetSlowMode(roomID string, seconds int) (*models.ChatRoomSetting, error) {
+	if seconds < 0 || seconds > maxChatSlowModeSecs {
+		return nil, fmt.Errorf("slow-mode phải từ 0 đến %d giây", maxChatSlowModeSecs)
+	}
+	if err := s.validateRoom(roomID); err != nil {
+		return nil, err
+	}
+
+	setting := &models.ChatRoomSetting{
+		RoomID:          roomID,
+		SlowModeSeconds: seconds,
+		UpdatedAt:       time.Now(),
+	}
+	if err := s.chatRepo.SaveRoomSetting(setting); err != nil {
+		return nil, err
+	}
+
+	s.publish(roomID, map[string]interface{}{
+		"type":              "slow_mode",
+		"slow_mode_seconds": seconds,
+	})
+
+	return setting, nil
+}
+
+// MuteUser - Cấm chat user (duration = 0 là vĩnh viễn, roomID = nil là tất cả phòng)
+func (s *chatService) MuteUser(adminID, userID uuid.UUID, roomID *string, duration time.Duration, reason *string) (*models.ChatMute, error) {
+	if roomID != nil {
+		if err := s.validateRoom(*roomID); err != nil {
+			return nil, err
+		}
+	}
+
+	mute := &models.ChatMute{
+		UserID:    userID,
+		RoomID:    roomID,
+		Reason:    reason,
+		CreatedBy: adminID,
+	}
+	if duration > 0 {
+		until := time.Now().Add(duration)
+		mute.MutedUntil = &until
+	}
+
+	if err := s.chatRepo.CreateMute(mute); err != nil {
+		return nil, err
+	}
+	return mute, nil
+}
+
+// UnmuteUser - Gỡ cấm chat
+func (s *chatService) UnmuteUser(muteID uuid.UUID) error {
+	if err := s.chatRepo.DeleteMute(muteID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("lệnh cấm không tồn tại")
+		}
+		return err
+	}
+	return nil
+}
+
+// GetActiveMutes - Danh sách lệnh cấm còn hiệu lực
+func (s *chatService) GetActiveMutes(page, limit int) ([]models.ChatMute, int64, error) {
+	return s.chatRepo.GetActiveMutes(page, limit)
+}
+
+// createMessage - Lưu tin nhắn; user thường bị kiểm tra slow-mode nguyên tử cùng lúc insert
+func (s *chatService) createMessage(message *models.ChatMessage, isAdmin bool) error {
+	if isAdmin {
+		return s.chatRepo.CreateMessage(message)
+	}
+
+	setting, err := s.GetRoomSetting(message.RoomID)
+	if err != nil {
+		return err
+	}
+	if setting.SlowModeSeconds <= 0 {
+		return s.chatRepo.CreateMessage(message)
+	}
+
+	wait, err := s.chatRepo.CreateMessageWithSlowMode(message, time.Duration(setting.SlowModeSeconds)*time.Second)
+	if err != nil {
+		return err
+	}
+	if wait > 0 {
+		return fmt.Errorf("phòng đang bật slow-mode, vui lòng chờ %d giây", int(wait.Seconds())+1)
+	}
+	return nil
+}
+
+// validateRoom - Phòng hợp lệ: "general" hoặc "story-<story_id>" của truyện đã publish
+func (s *chatService) validateRoom(roomID string) error {
+	if roomID == chatRoomGeneral {
+		return nil
+	}
+
+	if !strings.HasPrefix(roomID, chatStoryRoomPrefix) {
+		return errors.New("phòng chat không tồn tại")
+	}
+	storyID, err := uuid.Parse(strings.TrimPrefix(roomID, chatStoryRoomPrefix))
+	if err != nil {
+		return errors.New("phòng chat không tồn tại")
+	}
+	story, err := s.storyRepo.FindStoryByID(storyID)
+	if err != nil || !story.IsPublished {
+		return errors.New("phòng chat không tồn tại")
+	}
+	return nil
+}
+
+// publish - Push event lên channel của phòng (không block request)
+func (s *chatService) publish(roomID string, data map[string]interface{}) {
+	if s.centrifugoClient == nil {
+		return
+	}
+	go func() {
+		channel := chatChannel(roomID)
+		if err := s.centrifugoClient.Publish(channel, data); err != nil {
+			log.Printf("[Centrifugo] Failed to publish chat event to %s: %v", channel, err)
+		}
+	}()
+}
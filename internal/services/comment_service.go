@@ -6,6 +6,7 @@ import (
 
 	"nekozanedex/internal/models"
 	"nekozanedex/internal/repositories"
+	"nekozanedex/pkg/pagination"
 
 	"github.com/google/uuid"
 )
@@ -17,6 +18,8 @@ type CommentService interface {
 	DeleteComment(userID, commentID uuid.UUID, isAdmin bool) error
 	GetCommentsByStory(storyID uuid.UUID, page, limit int) ([]models.Comment, int64, error)
 	GetCommentsByChapter(chapterID uuid.UUID, page, limit int) ([]models.Comment, int64, error)
+	GetCommentsByStoryCursor(storyID uuid.UUID, cursor *pagination.Cursor, limit int) ([]models.Comment, pagination.Cursors, error)
+	GetCommentsByChapterCursor(chapterID uuid.UUID, cursor *pagination.Cursor, limit int) ([]models.Comment, pagination.Cursors, error)
 	UpdateLikeCount(commentID uuid.UUID, count int) error
 	TogglePin(commentID uuid.UUID, isPinned bool) error
 	FindCommentByID(id uuid.UUID) (*models.Comment, error)
@@ -191,6 +194,16 @@ func (s *commentService) GetCommentsByChapter(chapterID uuid.UUID, page, limit i
 	return s.commentRepo.GetCommentsByChapter(chapterID, page, limit)
 }
 
+// GetCommentsByStoryCursor - Lấy comments của truyện theo cursor (infinite scroll)
+func (s *commentService) GetCommentsByStoryCursor(storyID uuid.UUID, cursor *pagination.Cursor, limit int) ([]models.Comment, pagination.Cursors, error) {
+	return s.commentRepo.GetCommentsByStoryCursor(storyID, cursor, limit)
+}
+
+// GetCommentsByChapterCursor - Lấy comments của chapter theo cursor (infinite scroll)
+func (s *commentService) GetCommentsByChapterCursor(chapterID uuid.UUID, cursor *pagination.Cursor, limit int) ([]models.Comment, pagination.Cursors, error) {
+	return s.commentRepo.GetCommentsByChapterCursor(chapterID, cursor, limit)
+}
+
 // UpdateLikeCount - Update cached like count for a comment
 func (s *commentService) UpdateLikeCount(commentID uuid.UUID, count int) error {
 	return s.commentRepo.UpdateLikeCount(commentID, count)
@@ -0,0 +1,280 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"nekozanedex/internal/models"
+	"nekozanedex/internal/repositories"
+	"nekozanedex/pkg/epub"
+)
+
+const (
+	maxEpubImages     = 1500             // Tổng số ảnh tối đa trong 1 file EPUB (manga)
+	maxEpubAssetBytes = 15 << 20         // Giới hạn dung lượng 1 ảnh tải về
+	epubFetchWorkers  = 6                // Số ảnh tải song song
+	epubFetchTimeout  = 20 * time.Second // Timeout tải 1 ảnh
+)
+
+// epubMediaTypes - Định dạng ảnh EPUB hỗ trợ sẵn (core media types)
+var epubMediaTypes = map[string]bool{
+	"image/jpeg":    true,
+	"image/png":     true,
+	"image/gif":     true,
+	"image/webp":    true,
+	"image/svg+xml": true,
+}
+
+type ExportService interface {
+	PrepareStoryEpub(storySlug string, from, to int) (*EpubExport, error)
+	WriteEpub(export *EpubExport, w io.Writer) error
+}
+
+// EpubExport - Dữ liệu đã kiểm tra, sẵn sàng ghi ra EPUB
+type EpubExport struct {
+	Story    *models.Story
+	Chapters []models.Chapter
+	FileName string
+}
+
+type exportService struct {
+	storyRepo  repositories.StoryRepository
+	httpClient *http.Client
+}
+
+func NewExportService(storyRepo repositories.StoryRepository) ExportService {
+	return &exportService{
+		storyRepo:  storyRepo,
+		httpClient: &http.Client{Timeout: epubFetchTimeout},
+	}
+}
+
+// PrepareStoryEpub - Lấy truyện và các chapter đã xuất bản trong khoảng [from, to]
+// from/to = 0 nghĩa là không giới hạn. Kiểm tra xong mới bắt đầu ghi response
+func (s *exportService) PrepareStoryEpub(storySlug string, from, to int) (*EpubExport, error) {
+	if from < 0 || to < 0 || (to > 0 && from > to) {
+		return nil, errors.New("khoảng chapter không hợp lệ")
+	}
+
+	// FindStoryBySlug đã preload Genres và các chapter đã xuất bản
+	story, err := s.storyRepo.FindStoryBySlug(storySlug)
+	if err != nil {
+		return nil, errors.New("truyện không tồn tại")
+	}
+
+	chapters := make([]models.Chapter, 0, len(story.Chapters))
+	totalImages := 0
+	for _, chapter := range story.Chapters {
+		if from > 0 && chapter.ChapterNumber < from {
+			continue
+		}
+		if to > 0 && chapter.ChapterNumber > to {
+			continue
+		}
+		chapters = append(chapters, chapter)
+		totalImages += countImages(chapter.Images)
+	}
+	if len(chapters) == 0 {
+		return nil, errors.New("không có chapter nào trong khoảng đã chọn")
+	}
+	if totalImages > maxEpubImages {
+		return nil, fmt.Errorf("quá nhiều ảnh (%d), hãy chọn khoảng chapter nhỏ hơn", totalImages)
+	}
+
+	// Thứ tự đọc: extra/omake nằm đúng vị trí theo Ordering
+	sort.SliceStable(chapters, func(i, j int) bool {
+		return orderingOf(&chapters[i]) < orderingOf(&chapters[j])
+	})
+	story.Chapters = nil
+
+	fileName := story.Slug
+	if from > 0 || to > 0 {
+		fileName = fmt.Sprintf("%s-ch%d-%d", story.Slug, chapters[0].ChapterNumber, chapters[len(chapters)-1].ChapterNumber)
+	}
+
+	return &EpubExport{
+		Story:    story,
+		Chapters: chapters,
+		FileName: fileName + ".epub",
+	}, nil
+}
+
+// WriteEpub - Ghi EPUB 3 ra w (stream, không giữ toàn bộ file trong RAM)
+// Ảnh không tải được sẽ được thay bằng ghi chú thay vì làm hỏng cả file
+func (s *exportService) WriteEpub(export *EpubExport, w io.Writer) error {
+	story := export.Story
+
+	meta := epub.Metadata{
+		Identifier: "urn:uuid:" + story.ID.String(),
+		Title:      story.Title,
+		Language:   "vi",
+		Modified:   story.UpdatedAt,
+	}
+	if story.AuthorName != nil && *story.AuthorName != "" {
+		meta.Authors = []string{*story.AuthorName}
+	}
+	if story.Translator != nil && *story.Translator != "" {
+		meta.Translators = []string{*story.Translator}
+	}
+	if story.Description != nil {
+		meta.Description = plainText(*story.Description)
+	}
+	for _, genre := range story.Genres {
+		meta.Subjects = append(meta.Subjects, genre.Name)
+	}
+
+	book, err := epub.NewWriter(w, meta)
+	if err != nil {
+		return err
+	}
+
+	if story.CoverImageURL != nil && *story.CoverImageURL != "" {
+		if data, mediaType, err := s.fetchAsset(*story.CoverImageURL); err == nil {
+			if err := book.SetCover(data, mediaType); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, chapter := range export.Chapters {
+		var body strings.Builder
+
+		images := chapter.GetImagesSlice()
+		if len(images) > 0 {
+			assets := s.fetchAssets(images)
+			for i, asset := range assets {
+				if asset.err != nil {
+					body.WriteString(epub.TextToXHTML(fmt.Sprintf("[Không tải được trang %d]", i+1)))
+					continue
+				}
+				href, err := book.AddImage(asset.data, asset.mediaType)
+				if err != nil {
+					return err
+				}
+				body.WriteString(epub.ImagePage(href, fmt.Sprintf("Trang %d", i+1)))
+			}
+		}
+
+		if strings.TrimSpace(chapter.Content) != "" {
+			body.WriteString(epub.TextToXHTML(plainText(chapter.Content)))
+		}
+
+		if err := book.AddChapter(epubChapterTitle(&chapter), body.String()); err != nil {
+			return err
+		}
+	}
+
+	return book.Close()
+}
+
+// epubChapterTitle - Tiêu đề trong mục lục: "Chương <label hoặc số>: <title>"
+func epubChapterTitle(chapter *models.Chapter) string {
+	label := fmt.Sprintf("%d", chapter.ChapterNumber)
+	if chapter.ChapterLabel != nil && strings.TrimSpace(*chapter.ChapterLabel) != "" {
+		label = strings.TrimSpace(*chapter.ChapterLabel)
+	}
+	if strings.TrimSpace(chapter.Title) == "" {
+		return "Chương " + label
+	}
+	return "Chương " + label + ": " + chapter.Title
+}
+
+type fetchedAsset struct {
+	data      []byte
+	mediaType string
+	err       error
+}
+
+// fetchAssets - Tải nhiều ảnh song song, giữ nguyên thứ tự
+func (s *exportService) fetchAssets(urls []string) []fetchedAsset {
+	results := make([]fetchedAsset, len(urls))
+	sem := make(chan struct{}, epubFetchWorkers)
+	var wg sync.WaitGroup
+
+	for i, url := range urls {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, url string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			data, mediaType, err := s.fetchAsset(url)
+			results[i] = fetchedAsset{data: data, mediaType: mediaType, err: err}
+		}(i, url)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// fetchAsset - Đọc ảnh từ URL (http/https) hoặc đường dẫn file local
+func (s *exportService) fetchAsset(location string) ([]byte, string, error) {
+	var data []byte
+
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		resp, err := s.httpClient.Get(location)
+		if err != nil {
+			return nil, "", err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, "", fmt.Errorf("tải ảnh thất bại: %s", resp.Status)
+		}
+		data, err = io.ReadAll(io.LimitReader(resp.Body, maxEpubAssetBytes+1))
+		if err != nil {
+			return nil, "", err
+		}
+	} else {
+		// Đường dẫn local tương đối với thư mục chạy server, không cho thoát ra ngoài
+		path := filepath.Clean(strings.TrimPrefix(location, "/"))
+		if path == "." || strings.HasPrefix(path, "..") || filepath.IsAbs(path) {
+			return nil, "", errors.New("đường dẫn ảnh không hợp lệ")
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, "", err
+		}
+		defer file.Close()
+		data, err = io.ReadAll(io.LimitReader(file, maxEpubAssetBytes+1))
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	if len(data) > maxEpubAssetBytes {
+		return nil, "", errors.New("ảnh quá lớn")
+	}
+
+	mediaType := http.DetectContentType(data)
+	if strings.HasSuffix(strings.ToLower(location), ".svg") && strings.HasPrefix(mediaType, "text/") {
+		mediaType = "image/svg+xml"
+	}
+	if !epubMediaTypes[mediaType] {
+		return nil, "", fmt.Errorf("định dạng ảnh không hỗ trợ: %s", mediaType)
+	}
+	return data, mediaType, nil
+}
+
+var (
+	htmlBreakPattern = regexp.MustCompile(`(?i)<br\s*/?>|</p\s*>|</div\s*>`)
+	htmlTagPattern   = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+)
+
+// plainText - Nội dung có thể chứa HTML đơn giản từ editor, chuyển về text thuần theo dòng
+func plainText(content string) string {
+	if !strings.Contains(content, "<") {
+		return content
+	}
+	content = htmlBreakPattern.ReplaceAllString(content, "\n")
+	content = htmlTagPattern.ReplaceAllString(content, "")
+	return html.UnescapeString(content)
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"nekozanedex/internal/models"
+	"nekozanedex/internal/repositories"
+	"nekozanedex/pkg/cache"
+	"nekozanedex/pkg/feed"
+)
+
+const (
+	feedItemLimit = 50              // Số chapter trong 1 feed
+	feedCacheTTL  = 5 * time.Minute // Feed được dựng lại tối đa 1 lần / 5 phút
+	siteName      = "Nekozanedex"
+
+	cacheKeyFeedPrefix = "feed:" // feed:<kind>:<slug>:<format>
+)
+
+// Feed kinds
+const (
+	FeedLatest = "latest"
+	FeedGenre  = "genre"
+	FeedStory  = "story"
+)
+
+// Feed formats
+const (
+	FeedFormatRSS  = "rss"
+	FeedFormatAtom = "atom"
+)
+
+type FeedService interface {
+	GetFeed(kind, slug, format string) (*FeedDocument, error)
+}
+
+// FeedDocument - Feed đã dựng sẵn kèm validator cho conditional GET
+type FeedDocument struct {
+	Body         []byte
+	ContentType  string
+	ETag         string
+	LastModified time.Time
+}
+
+type feedService struct {
+	chapterRepo repositories.ChapterRepository
+	storyRepo   repositories.StoryRepository
+	genreRepo   repositories.GenreRepository
+	siteURL     string
+	cache       cache.Cache
+}
+
+func NewFeedService(
+	chapterRepo repositories.ChapterRepository,
+	storyRepo repositories.StoryRepository,
+	genreRepo repositories.GenreRepository,
+	siteURL string,
+	store cache.Cache,
+) FeedService {
+	return &feedService{
+		chapterRepo: chapterRepo,
+		storyRepo:   storyRepo,
+		genreRepo:   genreRepo,
+		siteURL:     siteURL,
+		cache:       store,
+	}
+}
+
+// GetFeed - Lấy feed (RSS/Atom) toàn site, theo thể loại hoặc theo truyện
+// Kết quả được cache (memory/Redis) để feed reader không gọi DB liên tục
+func (s *feedService) GetFeed(kind, slug, format string) (*FeedDocument, error) {
+	if format != FeedFormatRSS && format != FeedFormatAtom {
+		return nil, errors.New("định dạng feed không hợp lệ")
+	}
+
+	key := cacheKeyFeedPrefix + kind + ":" + slug + ":" + format
+	var doc FeedDocument
+	if cache.GetJSON(s.cache, key, &doc) {
+		return &doc, nil
+	}
+
+	built, err := s.buildFeed(kind, slug, format)
+	if err != nil {
+		return nil, err
+	}
+	cache.SetJSON(s.cache, key, built, feedCacheTTL)
+	return built, nil
+}
+
+func (s *feedService) buildFeed(kind, slug, format string) (*FeedDocument, error) {
+	ext := ".xml"
+	if format == FeedFormatAtom {
+		ext = ".atom"
+	}
+
+	var (
+		filter repositories.ChapterFeedFilter
+		f      = &feed.Feed{Language: "vi"}
+	)
+
+	switch kind {
+	case FeedLatest:
+		f.Title = siteName + " - Chương mới cập nhật"
+		f.Description = "Các chương truyện mới nhất trên " + siteName
+		f.Link = s.siteURL + "/"
+		f.SelfLink = s.siteURL + "/feeds/latest" + ext
+	case FeedGenre:
+		genre, err := s.genreRepo.FindGenreBySlug(slug)
+		if err != nil {
+			return nil, errors.New("thể loại không tồn tại")
+		}
+		filter.GenreID = &genre.ID
+		f.Title = siteName + " - " + genre.Name
+		f.Description = "Chương mới của các truyện thể loại " + genre.Name
+		f.Link = s.siteURL + "/genres/" + genre.Slug
+		f.SelfLink = s.siteURL + "/feeds/genres/" + genre.Slug + ext
+	case FeedStory:
+		story, err := s.storyRepo.FindPublishedStoryBySlugLite(slug)
+		if err != nil {
+			return nil, errors.New("truyện không tồn tại")
+		}
+		filter.StoryID = &story.ID
+		f.Title = story.Title + " - " + siteName
+		if story.Description != nil {
+			f.Description = *story.Description
+		}
+		f.Link = s.storyURL(story.Slug)
+		f.SelfLink = s.siteURL + "/feeds/stories/" + story.Slug + ext
+	default:
+		return nil, errors.New("feed không tồn tại")
+	}
+	// ID cố định, không phụ thuộc định dạng
+	f.ID = strings.TrimSuffix(f.SelfLink, ext)
+	if f.Description == "" {
+		f.Description = f.Title
+	}
+
+	chapters, err := s.chapterRepo.GetLatestPublished(filter, feedItemLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	f.Updated = time.Unix(0, 0)
+	for _, chapter := range chapters {
+		item := s.feedItem(&chapter)
+		if item.Updated.After(f.Updated) {
+			f.Updated = item.Updated
+		}
+		f.Items = append(f.Items, item)
+	}
+
+	var (
+		body        []byte
+		contentType string
+	)
+	if format == FeedFormatAtom {
+		body, err = feed.Atom(f)
+		contentType = "application/atom+xml; charset=utf-8"
+	} else {
+		body, err = feed.RSS(f)
+		contentType = "application/rss+xml; charset=utf-8"
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha1.Sum(body)
+	return &FeedDocument{
+		Body:         body,
+		ContentType:  contentType,
+		ETag:         `"` + hex.EncodeToString(sum[:10]) + `"`,
+		LastModified: f.Updated.UTC().Truncate(time.Second),
+	}, nil
+}
+
+func (s *feedService) feedItem(chapter *models.Chapter) feed.Item {
+	published := chapter.CreatedAt
+	if chapter.PublishedAt != nil {
+		published = *chapter.PublishedAt
+	}
+	updated := chapter.UpdatedAt
+	if updated.Before(published) {
+		updated = published
+	}
+
+	label := fmt.Sprintf("%d", chapter.ChapterNumber)
+	if chapter.ChapterLabel != nil && *chapter.ChapterLabel != "" {
+		label = *chapter.ChapterLabel
+	}
+	title := fmt.Sprintf("%s - Chương %s", chapter.Story.Title, label)
+	if chapter.Title != "" {
+		title += ": " + chapter.Title
+	}
+
+	author := siteName
+	if chapter.Story.Translator != nil && *chapter.Story.Translator != "" {
+		author = *chapter.Story.Translator
+	} else if chapter.Story.AuthorName != nil && *chapter.Story.AuthorName != "" {
+		author = *chapter.Story.AuthorName
+	}
+
+	summary := strings.Join(strings.Fields(plainText(chapter.Content)), " ")
+	if short := truncateRunes(summary, 300); short != summary {
+		summary = short + "…"
+	}
+
+	return feed.Item{
+		GUID:      "urn:uuid:" + chapter.ID.String(),
+		Title:     title,
+		Link:      fmt.Sprintf("%s/chapters/%d", s.storyURL(chapter.Story.Slug), chapter.ChapterNumber),
+		Summary:   summary,
+		Author:    author,
+		Published: published,
+		Updated:   updated,
+	}
+}
+
+func (s *feedService) storyURL(slug string) string {
+	return s.siteURL + "/stories/" + slug
+}
@@ -17,16 +17,17 @@ type GenreService interface {
 }
 
 type genreService struct {
-	genreRepo repositories.GenreRepository
+	genreRepo   repositories.GenreRepository
+	publicCache *PublicCache
 }
 
-func NewGenreService(genreRepo repositories.GenreRepository) GenreService {
-	return &genreService{genreRepo: genreRepo}
+func NewGenreService(genreRepo repositories.GenreRepository, publicCache *PublicCache) GenreService {
+	return &genreService{genreRepo: genreRepo, publicCache: publicCache}
 }
 
 // GetAllGenres - Lấy tất cả thể loại
 func (s *genreService) GetAllGenres() ([]models.Genre, error) {
-	return s.genreRepo.GetAllGenres()
+	return cached(s.publicCache, cacheKeyGenres, s.genreRepo.GetAllGenres)
 }
 
 // GetGenreByID - Lấy thể loại theo ID
@@ -45,6 +46,7 @@ func (s *genreService) CreateGenre(name string, description *string) (*models.Ge
 	if err := s.genreRepo.CreateGenre(genre); err != nil {
 		return nil, err
 	}
+	s.publicCache.invalidateGenres()
 
 	return genre, nil
 }
@@ -65,11 +67,16 @@ func (s *genreService) UpdateGenre(id uuid.UUID, name string, description *strin
 	if err := s.genreRepo.UpdateGenre(genre); err != nil {
 		return nil, err
 	}
+	s.publicCache.invalidateGenres()
 
 	return genre, nil
 }
 
 // DeleteGenre - Xóa thể loại
 func (s *genreService) DeleteGenre(id uuid.UUID) error {
-	return s.genreRepo.DeleteGenre(id)
+	if err := s.genreRepo.DeleteGenre(id); err != nil {
+		return err
+	}
+	s.publicCache.invalidateGenres()
+	return nil
 }
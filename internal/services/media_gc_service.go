@@ -0,0 +1,181 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"nekozanedex/internal/models"
+	"nekozanedex/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+const mediaGCBatchSize = 500 // Số asset mồ côi xử lý mỗi lần sweep
+
+// Hành động của sweep với từng asset mồ côi
+const (
+	MediaGCActionDelete  = "delete"  // Đã qua grace period, sẽ bị xóa
+	MediaGCActionPending = "pending" // Còn trong grace period
+)
+
+type MediaGCService interface {
+	Sweep(dryRun bool) (*MediaGCReport, error)
+	Backfill() (int64, error)
+}
+
+// MediaGCItem - 1 asset mồ côi trong báo cáo dry-run
+type MediaGCItem struct {
+	models.MediaAsset
+	Action      string    `json:"action"`
+	DeleteAfter time.Time `json:"delete_after"`
+}
+
+// MediaGCReport - Kết quả 1 lần sweep. Dry-run chỉ báo cáo, không đánh dấu hay xóa gì.
+// Dry-run đếm trên toàn bộ asset mồ côi nhưng Items chỉ liệt kê tối đa mediaGCBatchSize asset.
+// Sweep thật chỉ xử lý 1 batch, phần còn lại để lần sau.
+type MediaGCReport struct {
+	DryRun       bool          `json:"dry_run"`
+	Driver       string        `json:"driver"`
+	GraceSeconds int64         `json:"grace_seconds"`
+	Orphaned     int           `json:"orphaned"`
+	Deleted      int           `json:"deleted"` // Dry-run: số asset sẽ bị xóa
+	Pending      int           `json:"pending"`
+	Failed       int           `json:"failed"`
+	FreedBytes   int64         `json:"freed_bytes"` // Dry-run: dung lượng sẽ được giải phóng
+	Truncated    bool          `json:"truncated"`   // Còn asset mồ côi ngoài Items / ngoài batch vừa xử lý
+	Items        []MediaGCItem `json:"items,omitempty"`
+}
+
+type mediaGCService struct {
+	mediaAssetRepo repositories.MediaAssetRepository
+	uploadService  UploadService
+	gracePeriod    time.Duration
+}
+
+// NewMediaGCService - Dọn file upload không còn được Story/Volume cover, Chapter.Images, User.AvatarURL tham chiếu.
+// File phải mồ côi liên tục qua gracePeriod mới bị xóa. Chỉ xóa file của storage driver hiện tại.
+func NewMediaGCService(mediaAssetRepo repositories.MediaAssetRepository, uploadService UploadService, gracePeriod time.Duration) MediaGCService {
+	return &mediaGCService{
+		mediaAssetRepo: mediaAssetRepo,
+		uploadService:  uploadService,
+		gracePeriod:    gracePeriod,
+	}
+}
+
+// Sweep - Bỏ đánh dấu asset đã được dùng lại, đánh dấu asset mới mồ côi, xóa asset mồ côi quá grace period.
+// Grace period chỉ tính từ lúc asset bị đánh dấu, nên file mới upload chưa kịp gắn vào entity cũng được giữ đủ grace.
+func (s *mediaGCService) Sweep(dryRun bool) (*MediaGCReport, error) {
+	now := time.Now()
+	driver := s.uploadService.StorageDriver()
+	report := &MediaGCReport{
+		DryRun:       dryRun,
+		Driver:       driver,
+		GraceSeconds: int64(s.gracePeriod / time.Second),
+	}
+
+	if !dryRun {
+		if _, err := s.mediaAssetRepo.ClearReferenced(); err != nil {
+			return nil, err
+		}
+	}
+
+	assets, err := s.mediaAssetRepo.FindUnreferenced(driver, mediaGCBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	report.Orphaned = len(assets)
+	report.Truncated = len(assets) == mediaGCBatchSize
+
+	var newlyOrphaned []uuid.UUID
+	for _, asset := range assets {
+		deleteAfter := now.Add(s.gracePeriod)
+		if asset.UnreferencedAt != nil {
+			deleteAfter = asset.UnreferencedAt.Add(s.gracePeriod)
+		} else {
+			newlyOrphaned = append(newlyOrphaned, asset.ID)
+		}
+
+		action := MediaGCActionPending
+		if !deleteAfter.After(now) {
+			action = MediaGCActionDelete
+		}
+		if dryRun {
+			report.Items = append(report.Items, MediaGCItem{MediaAsset: asset, Action: action, DeleteAfter: deleteAfter})
+			continue
+		}
+
+		if action == MediaGCActionPending {
+			report.Pending++
+			continue
+		}
+		if err := s.deleteAsset(asset); err != nil {
+			log.Printf("❌ Failed to delete orphaned media %s: %v", asset.URL, err)
+			report.Failed++
+			continue
+		}
+		report.Deleted++
+		report.FreedBytes += asset.Size
+	}
+
+	if dryRun {
+		// Bộ đếm lấy từ toàn bộ asset mồ côi, không chỉ batch trong Items
+		stats, err := s.mediaAssetRepo.UnreferencedStats(driver, now.Add(-s.gracePeriod))
+		if err != nil {
+			return nil, err
+		}
+		report.Orphaned = int(stats.Orphaned)
+		report.Deleted = int(stats.Due)
+		report.Pending = int(stats.Orphaned - stats.Due)
+		report.FreedBytes = stats.DueBytes
+		report.Truncated = stats.Orphaned > int64(len(report.Items))
+		return report, nil
+	}
+
+	if err := s.mediaAssetRepo.MarkUnreferenced(newlyOrphaned, now); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// Backfill - Track các file upload từ trước khi có media_assets: URL đang được cover/ảnh chapter/avatar tham chiếu
+// và thuộc storage hiện tại. Khi không còn được tham chiếu, chúng được GC như asset bình thường.
+// Ngoài phạm vi: file đã mồ côi từ trước (không còn gì trỏ tới nên không tìm được nếu không liệt kê cả bucket)
+// và ảnh chỉ nằm trong nội dung chapter - các file này GC không bao giờ xóa.
+func (s *mediaGCService) Backfill() (int64, error) {
+	driver := s.uploadService.StorageDriver()
+	var tracked int64
+	after := ""
+	for {
+		urls, err := s.mediaAssetRepo.FindUntrackedReferences(after, mediaGCBatchSize)
+		if err != nil {
+			return tracked, err
+		}
+
+		assets := make([]models.MediaAsset, 0, len(urls))
+		for _, url := range urls {
+			key := s.uploadService.PublicIDFromURL(url)
+			if key == "" {
+				continue // Ảnh ngoài hoặc của storage driver khác
+			}
+			assets = append(assets, models.MediaAsset{URL: url, StorageKey: key, Driver: driver})
+		}
+		created, err := s.mediaAssetRepo.CreateIfMissing(assets)
+		tracked += created
+		if err != nil {
+			return tracked, err
+		}
+
+		if len(urls) < mediaGCBatchSize {
+			return tracked, nil
+		}
+		after = urls[len(urls)-1]
+	}
+}
+
+// deleteAsset - Xóa file trên storage trước, xóa dòng media_assets sau để lỗi storage thì lần sweep sau thử lại
+func (s *mediaGCService) deleteAsset(asset models.MediaAsset) error {
+	if err := s.uploadService.DeleteImage(asset.StorageKey); err != nil {
+		return err
+	}
+	return s.mediaAssetRepo.Delete(asset.ID)
+}
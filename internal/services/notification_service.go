@@ -2,40 +2,77 @@ package services
 
 import (
 	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"nekozanedex/internal/centrifugo"
 	"nekozanedex/internal/models"
 	"nekozanedex/internal/repositories"
+	"nekozanedex/pkg/pagination"
 
 	"github.com/google/uuid"
 )
 
+const (
+	fanOutBatchSize = 1000 // Số người nhận mỗi lô (1 INSERT + 1 Centrifugo batch)
+	fanOutQueueSize = 256  // Số chapter chờ fan-out
+)
+
+// NewChapterEvent - Chapter vừa xuất bản, cần báo cho những người đã bookmark truyện
+type NewChapterEvent struct {
+	StoryID       uuid.UUID
+	StoryTitle    string
+	StorySlug     string
+	ChapterNumber int
+	ChapterLabel  *string
+}
+
 type NotificationService interface {
 	CreateNotification(userID uuid.UUID, notifType, title string, content, link *string) error
 	GetUserNotifications(userID uuid.UUID, page, limit int) ([]models.Notification, int64, error)
+	GetUserNotificationsCursor(userID uuid.UUID, cursor *pagination.Cursor, limit int) ([]models.Notification, pagination.Cursors, error)
 	MarkAsRead(notificationID uuid.UUID) error
 	MarkAllAsRead(userID uuid.UUID) error
 	GetUnreadCount(userID uuid.UUID) int64
 
 	// Notification helpers
 	NotifyNewChapter(userID uuid.UUID, storyTitle string, chapterNumber int, storySlug string) error
+	NotifyBookmarkers(event NewChapterEvent) // Fan-out chạy nền theo lô, không bao giờ block
 	NotifyCommentReply(userID uuid.UUID, commenterName, storySlug string) error
 	NotifyMention(userID uuid.UUID, mentionerName, storySlug string) error
+
+	Close() // Chạy nốt fan-out còn trong hàng đợi rồi dừng, gọi khi shutdown
 }
 
 type notificationService struct {
 	notificationRepo repositories.NotificationRepository
+	bookmarkRepo     repositories.BookmarkRepository
 	centrifugoClient *centrifugo.Client
+	fanOutQueue      chan NewChapterEvent
+
+	queueMu   sync.RWMutex // Giữ RLock khi gửi vào fanOutQueue, Lock khi đóng
+	closed    bool
+	stopped   chan struct{}
+	closeOnce sync.Once
 }
 
 func NewNotificationService(
 	notificationRepo repositories.NotificationRepository,
+	bookmarkRepo repositories.BookmarkRepository,
 	centrifugoClient *centrifugo.Client,
 ) NotificationService {
-	return &notificationService{
+	s := &notificationService{
 		notificationRepo: notificationRepo,
+		bookmarkRepo:     bookmarkRepo,
 		centrifugoClient: centrifugoClient,
+		fanOutQueue:      make(chan NewChapterEvent, fanOutQueueSize),
+		stopped:          make(chan struct{}),
 	}
+	// 1 worker xử lý tuần tự để không dồn nhiều INSERT lớn vào DB cùng lúc
+	go s.runFanOut()
+	return s
 }
 
 // CreateNotification - Tạo notification và push realtime
@@ -77,6 +114,11 @@ func (s *notificationService) GetUserNotifications(userID uuid.UUID, page, limit
 	return s.notificationRepo.GetNotificationsByUser(userID, page, limit)
 }
 
+// GetUserNotificationsCursor - Lấy notifications của user theo cursor (infinite scroll)
+func (s *notificationService) GetUserNotificationsCursor(userID uuid.UUID, cursor *pagination.Cursor, limit int) ([]models.Notification, pagination.Cursors, error) {
+	return s.notificationRepo.GetNotificationsByUserCursor(userID, cursor, limit)
+}
+
 // MarkAsRead - Đánh dấu đã đọc
 func (s *notificationService) MarkAsRead(notificationID uuid.UUID) error {
 	return s.notificationRepo.MarkNotificationAsRead(notificationID)
@@ -94,11 +136,122 @@ func (s *notificationService) GetUnreadCount(userID uuid.UUID) int64 {
 
 // NotifyNewChapter - Thông báo chapter mới
 func (s *notificationService) NotifyNewChapter(userID uuid.UUID, storyTitle string, chapterNumber int, storySlug string) error {
+	title, content, link := newChapterNotification(storyTitle, storySlug, chapterNumber, nil)
+	return s.CreateNotification(userID, "new_chapter", title, &content, &link)
+}
+
+// NotifyBookmarkers - Đưa chapter mới vào hàng đợi fan-out tới người đã bookmark truyện
+// Không block request xuất bản: hàng đợi đầy thì bỏ event và ghi log
+func (s *notificationService) NotifyBookmarkers(event NewChapterEvent) {
+	s.queueMu.RLock()
+	defer s.queueMu.RUnlock()
+	if s.closed {
+		log.Printf("⚠️ Notification service closed, skipped new chapter notification for story %s", event.StoryID)
+		return
+	}
+
+	select {
+	case s.fanOutQueue <- event:
+	default:
+		log.Printf("⚠️ Fan-out queue full, skipped new chapter notification for story %s", event.StoryID)
+	}
+}
+
+// Close - Không nhận event mới, chờ worker fan-out hết các chapter còn trong hàng đợi
+func (s *notificationService) Close() {
+	s.closeOnce.Do(func() {
+		s.queueMu.Lock()
+		s.closed = true
+		close(s.fanOutQueue)
+		s.queueMu.Unlock()
+		<-s.stopped
+	})
+}
+
+func (s *notificationService) runFanOut() {
+	defer close(s.stopped)
+	for event := range s.fanOutQueue {
+		s.fanOutNewChapter(event)
+	}
+}
+
+// fanOutNewChapter - Tạo notification cho từng lô người bookmark rồi push realtime cả lô
+func (s *notificationService) fanOutNewChapter(event NewChapterEvent) {
+	title, content, link := newChapterNotification(event.StoryTitle, event.StorySlug, event.ChapterNumber, event.ChapterLabel)
+
+	after := uuid.Nil
+	total := 0
+	for {
+		userIDs, err := s.bookmarkRepo.GetBookmarkerIDs(event.StoryID, after, fanOutBatchSize)
+		if err != nil {
+			log.Printf("❌ Failed to load bookmarkers of story %s: %v", event.StoryID, err)
+			return
+		}
+		if len(userIDs) == 0 {
+			break
+		}
+
+		now := time.Now()
+		notifications := make([]models.Notification, 0, len(userIDs))
+		for _, userID := range userIDs {
+			notifications = append(notifications, models.Notification{
+				ID:        uuid.New(),
+				UserID:    userID,
+				Type:      "new_chapter",
+				Title:     title,
+				Message:   &content,
+				Link:      &link,
+				CreatedAt: now,
+			})
+		}
+		if err := s.notificationRepo.CreateNotifications(notifications); err != nil {
+			log.Printf("❌ Failed to create new chapter notifications for story %s: %v", event.StoryID, err)
+			return
+		}
+		s.pushNotifications(notifications)
+
+		total += len(userIDs)
+		after = userIDs[len(userIDs)-1]
+		if len(userIDs) < fanOutBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		log.Printf("🔔 Notified %d bookmarker(s) about %s", total, content)
+	}
+}
+
+// pushNotifications - Push realtime cả lô notification qua Centrifugo batch API
+func (s *notificationService) pushNotifications(notifications []models.Notification) {
+	if s.centrifugoClient == nil {
+		return
+	}
+	messages := make([]centrifugo.PublishMessage, 0, len(notifications))
+	for i := range notifications {
+		messages = append(messages, centrifugo.PublishMessage{
+			Channel: "user:" + notifications[i].UserID.String(),
+			Data: map[string]interface{}{
+				"type":         "new_notification",
+				"notification": &notifications[i],
+			},
+		})
+	}
+	if err := s.centrifugoClient.PublishBatch(messages); err != nil {
+		log.Printf("[Centrifugo] Failed to push %d new chapter notification(s): %v", len(messages), err)
+	}
+}
+
+// newChapterNotification - Tiêu đề, nội dung, link của thông báo chapter mới
+func newChapterNotification(storyTitle, storySlug string, chapterNumber int, chapterLabel *string) (string, string, string) {
+	label := strconv.Itoa(chapterNumber)
+	if chapterLabel != nil && strings.TrimSpace(*chapterLabel) != "" {
+		label = strings.TrimSpace(*chapterLabel)
+	}
 	title := "📖 Chapter mới!"
-	content := storyTitle + " vừa cập nhật chapter " + string(rune(chapterNumber))
+	content := storyTitle + " vừa cập nhật chapter " + label
 	link := "/client/stories/" + storySlug
-
-	return s.CreateNotification(userID, "new_chapter", title, &content, &link)
+	return title, content, link
 }
 
 // NotifyCommentReply - Thông báo có reply comment
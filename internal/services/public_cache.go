@@ -0,0 +1,104 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"nekozanedex/internal/repositories"
+	"nekozanedex/pkg/cache"
+
+	"github.com/google/uuid"
+)
+
+// Cache keys cho các read public. Mọi key của 1 truyện đều chứa slug để xóa chính xác khi admin sửa.
+const (
+	cacheKeyGenres        = "genres:all"
+	cacheKeyStoryPrefix   = "story:"    // story:<slug> - chi tiết truyện (kèm chapters + genres)
+	cacheKeyChapterPrefix = "chapters:" // chapters:<slug>:<variant> - danh sách chapter
+	cacheKeyListPrefix    = "stories:"  // stories:latest:<limit>, stories:hot:<limit>
+)
+
+// PublicCache - Bọc cache.Cache cho các read public nóng (truyện, danh sách chapter, thể loại).
+// nil-safe: service nhận nil thì luôn đọc thẳng DB.
+type PublicCache struct {
+	store     cache.Cache
+	ttl       time.Duration
+	storyRepo repositories.StoryRepository // Tra slug khi mutation chỉ có story ID
+}
+
+func NewPublicCache(store cache.Cache, ttl time.Duration, storyRepo repositories.StoryRepository) *PublicCache {
+	return &PublicCache{store: store, ttl: ttl, storyRepo: storyRepo}
+}
+
+// cached - Đọc key từ cache, miss thì gọi load và lưu kết quả (lỗi không được cache)
+func cached[T any](pc *PublicCache, key string, load func() (T, error)) (T, error) {
+	if pc == nil {
+		return load()
+	}
+
+	var value T
+	if cache.GetJSON(pc.store, key, &value) {
+		return value, nil
+	}
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+	cache.SetJSON(pc.store, key, value, pc.ttl)
+	return value, nil
+}
+
+func storyCacheKey(slug string) string {
+	return cacheKeyStoryPrefix + slug
+}
+
+func chapterListCacheKey(slug, variant string) string {
+	return cacheKeyChapterPrefix + slug + ":" + variant
+}
+
+func latestStoriesCacheKey(limit int) string {
+	return fmt.Sprintf("%slatest:%d", cacheKeyListPrefix, limit)
+}
+
+func hotStoriesCacheKey(limit int) string {
+	return fmt.Sprintf("%shot:%d", cacheKeyListPrefix, limit)
+}
+
+// invalidateStory - Xóa chi tiết + danh sách chapter của truyện và các list truyện (latest/hot)
+func (pc *PublicCache) invalidateStory(slugs ...string) {
+	if pc == nil {
+		return
+	}
+	for _, slug := range slugs {
+		if slug == "" {
+			continue
+		}
+		pc.store.Delete(storyCacheKey(slug))
+		pc.store.DeletePrefix(cacheKeyChapterPrefix + slug + ":")
+	}
+	pc.store.DeletePrefix(cacheKeyListPrefix)
+}
+
+// invalidateStoryByID - Như invalidateStory nhưng tra slug từ story ID
+func (pc *PublicCache) invalidateStoryByID(storyID uuid.UUID) {
+	if pc == nil {
+		return
+	}
+	story, err := pc.storyRepo.FindStoryByID(storyID)
+	if err != nil {
+		log.Printf("[Cache] Không tìm thấy truyện %s để xóa cache: %v", storyID, err)
+		return
+	}
+	pc.invalidateStory(story.Slug)
+}
+
+// invalidateGenres - Xóa danh sách thể loại và mọi dữ liệu truyện có nhúng genres
+func (pc *PublicCache) invalidateGenres() {
+	if pc == nil {
+		return
+	}
+	pc.store.Delete(cacheKeyGenres)
+	pc.store.DeletePrefix(cacheKeyStoryPrefix)
+	pc.store.DeletePrefix(cacheKeyListPrefix)
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"nekozanedex/internal/models"
+	"nekozanedex/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+const rankingSize = 100 // Số truyện được lưu cho mỗi kỳ
+
+// Ranking periods
+const (
+	RankingDay   = "day"
+	RankingWeek  = "week"
+	RankingMonth = "month"
+)
+
+// rankingPeriod - Cửa sổ tính điểm và chu kỳ bán rã của từng kỳ
+// Cửa sổ "day" lấy 2 ngày để bảng không trống trơn lúc vừa qua nửa đêm
+type rankingPeriod struct {
+	lengthDays   int     // Độ dài 1 kỳ, kỳ trước = lùi lại đúng ngần này ngày
+	windowDays   int     // Số ngày story_views được tính
+	halfLifeDays float64 // Sau ngần này ngày, 1 lượt xem chỉ còn nửa trọng số
+}
+
+var rankingPeriods = map[string]rankingPeriod{
+	RankingDay:   {lengthDays: 1, windowDays: 2, halfLifeDays: 0.5},
+	RankingWeek:  {lengthDays: 7, windowDays: 7, halfLifeDays: 3},
+	RankingMonth: {lengthDays: 30, windowDays: 30, halfLifeDays: 10},
+}
+
+type RankingService interface {
+	RefreshRankings() error
+	GetRanking(period string, limit int) ([]RankingEntry, error)
+}
+
+// RankingEntry - 1 dòng bảng xếp hạng trả về cho client
+type RankingEntry struct {
+	Rank         int          `json:"rank"`
+	PreviousRank *int         `json:"previous_rank"`
+	RankChange   *int         `json:"rank_change"` // > 0 = tăng hạng, NULL = mới lọt bảng
+	Score        float64      `json:"score"`
+	Views        int64        `json:"views"`
+	Story        models.Story `json:"story"`
+}
+
+type rankingService struct {
+	rankingRepo repositories.StoryRankingRepository
+}
+
+func NewRankingService(rankingRepo repositories.StoryRankingRepository) RankingService {
+	return &rankingService{rankingRepo: rankingRepo}
+}
+
+// RefreshRankings - Tính lại bảng xếp hạng của tất cả các kỳ (chạy định kỳ)
+func (s *rankingService) RefreshRankings() error {
+	now := time.Now()
+	for _, period := range []string{RankingDay, RankingWeek, RankingMonth} {
+		if err := s.refreshPeriod(period, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refreshPeriod - So sánh kỳ hiện tại với cùng cửa sổ lùi lại 1 kỳ để ra thay đổi thứ hạng
+func (s *rankingService) refreshPeriod(period string, now time.Time) error {
+	cfg := rankingPeriods[period]
+
+	current, err := s.rankingRepo.ComputeScores(now, cfg.windowDays, cfg.halfLifeDays, rankingSize)
+	if err != nil {
+		return err
+	}
+	previous, err := s.rankingRepo.ComputeScores(now.AddDate(0, 0, -cfg.lengthDays), cfg.windowDays, cfg.halfLifeDays, rankingSize)
+	if err != nil {
+		return err
+	}
+
+	previousRanks := make(map[uuid.UUID]int, len(previous))
+	for i, score := range previous {
+		previousRanks[score.StoryID] = i + 1
+	}
+
+	rankings := make([]models.StoryRanking, 0, len(current))
+	for i, score := range current {
+		ranking := models.StoryRanking{
+			Period:     period,
+			StoryID:    score.StoryID,
+			Rank:       i + 1,
+			Score:      score.Score,
+			Views:      score.Views,
+			ComputedAt: now,
+		}
+		if rank, ok := previousRanks[score.StoryID]; ok {
+			ranking.PreviousRank = &rank
+		}
+		rankings = append(rankings, ranking)
+	}
+
+	return s.rankingRepo.ReplaceRankings(period, rankings)
+}
+
+// GetRanking - Lấy bảng xếp hạng đã tính sẵn của 1 kỳ
+func (s *rankingService) GetRanking(period string, limit int) ([]RankingEntry, error) {
+	if _, ok := rankingPeriods[period]; !ok {
+		return nil, errors.New("kỳ xếp hạng không hợp lệ (day, week, month)")
+	}
+
+	rankings, err := s.rankingRepo.GetRankings(period, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]RankingEntry, 0, len(rankings))
+	for _, ranking := range rankings {
+		entry := RankingEntry{
+			Rank:         ranking.Rank,
+			PreviousRank: ranking.PreviousRank,
+			Score:        ranking.Score,
+			Views:        ranking.Views,
+			Story:        ranking.Story,
+		}
+		if ranking.PreviousRank != nil {
+			change := *ranking.PreviousRank - ranking.Rank
+			entry.RankChange = &change
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"nekozanedex/internal/models"
+	"nekozanedex/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+const (
+	similarPerStory    = 30  // Số truyện tương tự lưu cho mỗi truyện
+	similarPerUser     = 200 // Số truyện gần nhất của mỗi user được tính co-occurrence
+	similarMinShared   = 2   // Cần ít nhất ngần này người đọc chung, tránh nhiễu từ 1 user
+	genreFallbackScale = 0.1 // Điểm thể loại bị hạ xuống dưới điểm đọc chung
+)
+
+// Similarity sources
+const (
+	SimilaritySourceCoRead = "co_read"
+	SimilaritySourceGenre  = "genre"
+)
+
+type RecommendationService interface {
+	RefreshSimilarities() error
+	GetSimilarStories(slug string, limit int) ([]models.Story, error)
+	GetRecommendations(userID uuid.UUID, limit int) ([]models.Story, error)
+}
+
+type recommendationService struct {
+	similarityRepo repositories.StorySimilarityRepository
+	storyRepo      repositories.StoryRepository
+}
+
+func NewRecommendationService(
+	similarityRepo repositories.StorySimilarityRepository,
+	storyRepo repositories.StoryRepository,
+) RecommendationService {
+	return &recommendationService{
+		similarityRepo: similarityRepo,
+		storyRepo:      storyRepo,
+	}
+}
+
+// RefreshSimilarities - Tính lại bảng truyện tương tự (chạy định kỳ)
+// Ưu tiên kết quả đọc chung; truyện chưa đủ similarPerStory kết quả được bù bằng độ trùng thể loại
+func (s *recommendationService) RefreshSimilarities() error {
+	coRead, err := s.similarityRepo.ComputeCoReadScores(similarPerStory, similarPerUser, similarMinShared)
+	if err != nil {
+		return err
+	}
+	byGenre, err := s.similarityRepo.ComputeGenreScores(similarPerStory)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	counts := make(map[uuid.UUID]int)
+	seen := make(map[[2]uuid.UUID]bool, len(coRead))
+	similarities := make([]models.StorySimilarity, 0, len(coRead)+len(byGenre))
+
+	for _, score := range coRead {
+		seen[[2]uuid.UUID{score.StoryID, score.SimilarStoryID}] = true
+		counts[score.StoryID]++
+		similarities = append(similarities, models.StorySimilarity{
+			StoryID:        score.StoryID,
+			SimilarStoryID: score.SimilarStoryID,
+			Score:          score.Score,
+			Source:         SimilaritySourceCoRead,
+			ComputedAt:     now,
+		})
+	}
+	for _, score := range byGenre {
+		pair := [2]uuid.UUID{score.StoryID, score.SimilarStoryID}
+		if seen[pair] || counts[score.StoryID] >= similarPerStory {
+			continue
+		}
+		seen[pair] = true
+		counts[score.StoryID]++
+		similarities = append(similarities, models.StorySimilarity{
+			StoryID:        score.StoryID,
+			SimilarStoryID: score.SimilarStoryID,
+			Score:          score.Score * genreFallbackScale,
+			Source:         SimilaritySourceGenre,
+			ComputedAt:     now,
+		})
+	}
+
+	return s.similarityRepo.ReplaceAll(similarities)
+}
+
+// GetSimilarStories - "Người đọc truyện này cũng đọc" (Public)
+func (s *recommendationService) GetSimilarStories(slug string, limit int) ([]models.Story, error) {
+	story, err := s.storyRepo.FindPublishedStoryBySlugLite(slug)
+	if err != nil {
+		return nil, errors.New("truyện không tồn tại")
+	}
+	return s.similarityRepo.GetSimilar(story.ID, limit)
+}
+
+// GetRecommendations - Gợi ý cá nhân từ bookmarks + lịch sử đọc của user
+// User chưa đọc gì (hoặc chưa đủ dữ liệu) thì bù bằng bảng xếp hạng tuần
+func (s *recommendationService) GetRecommendations(userID uuid.UUID, limit int) ([]models.Story, error) {
+	return s.similarityRepo.GetRecommendations(userID, RankingWeek, limit)
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"nekozanedex/internal/config"
+	"nekozanedex/internal/repositories"
+	"nekozanedex/pkg/cache"
+	"nekozanedex/pkg/sitemap"
+)
+
+const (
+	sitemapPageSize = 10000            // Số URL trong 1 sitemap con (chuẩn cho phép tối đa 50.000)
+	sitemapCacheTTL = 30 * time.Minute // Sitemap được dựng lại tối đa 1 lần / 30 phút
+
+	cacheKeySitemapPrefix = "sitemap:" // sitemap:index, sitemap:<kind>:<page>
+)
+
+// Sitemap kinds
+const (
+	SitemapStories  = "stories"
+	SitemapChapters = "chapters"
+	SitemapGenres   = "genres"
+)
+
+type SitemapService interface {
+	GetIndex() (*SitemapDocument, error)
+	GetSitemap(kind string, page int) (*SitemapDocument, error)
+	GetRobots() string
+}
+
+// SitemapDocument - Sitemap đã dựng sẵn kèm validator cho conditional GET
+type SitemapDocument struct {
+	Body         []byte
+	ETag         string
+	LastModified time.Time
+}
+
+type sitemapService struct {
+	storyRepo   repositories.StoryRepository
+	chapterRepo repositories.ChapterRepository
+	siteURL     string
+	seo         config.SEOConfig
+	cache       cache.Cache
+}
+
+func NewSitemapService(
+	storyRepo repositories.StoryRepository,
+	chapterRepo repositories.ChapterRepository,
+	siteURL string,
+	seo config.SEOConfig,
+	store cache.Cache,
+) SitemapService {
+	return &sitemapService{
+		storyRepo:   storyRepo,
+		chapterRepo: chapterRepo,
+		siteURL:     siteURL,
+		seo:         seo,
+		cache:       store,
+	}
+}
+
+// GetIndex - Sitemap index trỏ tới các sitemap con (stories/chapters/genres, đã phân trang)
+func (s *sitemapService) GetIndex() (*SitemapDocument, error) {
+	return s.cached("index", func() (*SitemapDocument, error) {
+		storyTotal, err := s.storyRepo.CountPublishedStories()
+		if err != nil {
+			return nil, err
+		}
+		chapterTotal, err := s.chapterRepo.CountPublishedChapters()
+		if err != nil {
+			return nil, err
+		}
+
+		var sitemaps []sitemap.URL
+		for page := 1; page <= sitemapPages(storyTotal); page++ {
+			sitemaps = append(sitemaps, sitemap.URL{Loc: s.sitemapURL(SitemapStories, page)})
+		}
+		for page := 1; page <= sitemapPages(chapterTotal); page++ {
+			sitemaps = append(sitemaps, sitemap.URL{Loc: s.sitemapURL(SitemapChapters, page)})
+		}
+		sitemaps = append(sitemaps, sitemap.URL{Loc: s.sitemapURL(SitemapGenres, 1)})
+
+		body, err := sitemap.Index(sitemaps)
+		if err != nil {
+			return nil, err
+		}
+		return newSitemapDocument(body, time.Now()), nil
+	})
+}
+
+// GetSitemap - 1 trang sitemap con, page bắt đầu từ 1
+func (s *sitemapService) GetSitemap(kind string, page int) (*SitemapDocument, error) {
+	if page < 1 {
+		return nil, errors.New("sitemap không tồn tại")
+	}
+
+	return s.cached(fmt.Sprintf("%s:%d", kind, page), func() (*SitemapDocument, error) {
+		offset := (page - 1) * sitemapPageSize
+		var urls []sitemap.URL
+
+		switch kind {
+		case SitemapStories:
+			entries, err := s.storyRepo.GetPublishedStorySitemap(offset, sitemapPageSize)
+			if err != nil {
+				return nil, err
+			}
+			for _, entry := range entries {
+				urls = append(urls, sitemap.URL{Loc: s.siteURL + "/stories/" + entry.Slug, LastMod: entry.UpdatedAt})
+			}
+		case SitemapChapters:
+			entries, err := s.chapterRepo.GetPublishedChapterSitemap(offset, sitemapPageSize)
+			if err != nil {
+				return nil, err
+			}
+			for _, entry := range entries {
+				urls = append(urls, sitemap.URL{
+					Loc:     fmt.Sprintf("%s/stories/%s/chapters/%d", s.siteURL, entry.StorySlug, entry.ChapterNumber),
+					LastMod: entry.UpdatedAt,
+				})
+			}
+		case SitemapGenres:
+			if page > 1 {
+				return nil, errors.New("sitemap không tồn tại")
+			}
+			entries, err := s.storyRepo.GetGenreSitemap()
+			if err != nil {
+				return nil, err
+			}
+			for _, entry := range entries {
+				urls = append(urls, sitemap.URL{Loc: s.siteURL + "/genres/" + entry.Slug, LastMod: entry.UpdatedAt})
+			}
+		default:
+			return nil, errors.New("sitemap không tồn tại")
+		}
+
+		// Trang vượt quá số lượng -> 404 thay vì urlset rỗng
+		if len(urls) == 0 && page > 1 {
+			return nil, errors.New("sitemap không tồn tại")
+		}
+
+		lastModified := time.Unix(0, 0)
+		for _, u := range urls {
+			if u.LastMod.After(lastModified) {
+				lastModified = u.LastMod
+			}
+		}
+
+		body, err := sitemap.URLSet(urls)
+		if err != nil {
+			return nil, err
+		}
+		return newSitemapDocument(body, lastModified), nil
+	})
+}
+
+// GetRobots - Nội dung robots.txt theo cấu hình SEO
+func (s *sitemapService) GetRobots() string {
+	var b strings.Builder
+	b.WriteString("User-agent: *\n")
+	if s.seo.RobotsBlockAll {
+		b.WriteString("Disallow: /\n")
+		return b.String()
+	}
+	if len(s.seo.RobotsDisallow) == 0 {
+		b.WriteString("Disallow:\n")
+	}
+	for _, path := range s.seo.RobotsDisallow {
+		b.WriteString("Disallow: " + path + "\n")
+	}
+	b.WriteString("\nSitemap: " + s.siteURL + "/sitemap.xml\n")
+	return b.String()
+}
+
+// cached - Trả về bản trong cache nếu còn hạn, nếu không thì dựng lại
+func (s *sitemapService) cached(key string, build func() (*SitemapDocument, error)) (*SitemapDocument, error) {
+	key = cacheKeySitemapPrefix + key
+	var doc SitemapDocument
+	if cache.GetJSON(s.cache, key, &doc) {
+		return &doc, nil
+	}
+
+	built, err := build()
+	if err != nil {
+		return nil, err
+	}
+	cache.SetJSON(s.cache, key, built, sitemapCacheTTL)
+	return built, nil
+}
+
+func (s *sitemapService) sitemapURL(kind string, page int) string {
+	return fmt.Sprintf("%s/sitemaps/%s-%d.xml", s.siteURL, kind, page)
+}
+
+func sitemapPages(total int64) int {
+	return int((total + sitemapPageSize - 1) / sitemapPageSize)
+}
+
+func newSitemapDocument(body []byte, lastModified time.Time) *SitemapDocument {
+	sum := sha1.Sum(body)
+	return &SitemapDocument{
+		Body:         body,
+		ETag:         `"` + hex.EncodeToString(sum[:10]) + `"`,
+		LastModified: lastModified.UTC().Truncate(time.Second),
+	}
+}
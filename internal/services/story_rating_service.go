@@ -0,0 +1,92 @@
+package services
+
+import (
+	"errors"
+
+	"nekozanedex/internal/models"
+	"nekozanedex/internal/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type StoryRatingService interface {
+	RateStory(userID, storyID uuid.UUID, score int) (*models.Story, error)
+	RemoveRating(userID, storyID uuid.UUID) (*models.Story, error)
+	GetMyRating(userID, storyID uuid.UUID) (*models.StoryRating, error)
+}
+
+type storyRatingService struct {
+	ratingRepo  repositories.StoryRatingRepository
+	storyRepo   repositories.StoryRepository
+	publicCache *PublicCache
+}
+
+func NewStoryRatingService(
+	ratingRepo repositories.StoryRatingRepository,
+	storyRepo repositories.StoryRepository,
+	publicCache *PublicCache,
+) StoryRatingService {
+	return &storyRatingService{
+		ratingRepo:  ratingRepo,
+		storyRepo:   storyRepo,
+		publicCache: publicCache,
+	}
+}
+
+// RateStory - Đánh giá hoặc đổi điểm đánh giá truyện, trả về truyện với rating mới
+func (s *storyRatingService) RateStory(userID, storyID uuid.UUID, score int) (*models.Story, error) {
+	if score < 1 || score > 5 {
+		return nil, errors.New("điểm đánh giá phải từ 1 đến 5")
+	}
+
+	story, err := s.storyRepo.FindStoryByID(storyID)
+	if err != nil || !story.IsPublished {
+		return nil, errors.New("truyện không tồn tại")
+	}
+
+	rating := &models.StoryRating{
+		UserID:  userID,
+		StoryID: storyID,
+		Score:   score,
+	}
+	if err := s.ratingRepo.UpsertRating(rating); err != nil {
+		return nil, err
+	}
+
+	return s.reloadStory(storyID)
+}
+
+// RemoveRating - Rút lại đánh giá, trả về truyện với rating mới
+func (s *storyRatingService) RemoveRating(userID, storyID uuid.UUID) (*models.Story, error) {
+	if err := s.ratingRepo.DeleteRating(userID, storyID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("bạn chưa đánh giá truyện này")
+		}
+		return nil, err
+	}
+
+	return s.reloadStory(storyID)
+}
+
+// reloadStory - Đọc lại truyện sau khi rating đã được tính lại và xóa cache trang truyện (rating, phân bố sao)
+func (s *storyRatingService) reloadStory(storyID uuid.UUID) (*models.Story, error) {
+	story, err := s.storyRepo.FindStoryByID(storyID)
+	if err != nil {
+		return nil, err
+	}
+	s.publicCache.invalidateStory(story.Slug)
+	return story, nil
+}
+
+// GetMyRating - Lấy đánh giá của user cho truyện (nil nếu chưa đánh giá)
+func (s *storyRatingService) GetMyRating(userID, storyID uuid.UUID) (*models.StoryRating, error) {
+	rating, err := s.ratingRepo.FindRatingByUserAndStory(userID, storyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return rating, nil
+}
@@ -8,6 +8,8 @@ import (
 
 	"nekozanedex/internal/models"
 	"nekozanedex/internal/repositories"
+	"nekozanedex/internal/utils"
+	"nekozanedex/pkg/pagination"
 
 	"github.com/google/uuid"
 	"github.com/gosimple/slug"
@@ -16,12 +18,15 @@ import (
 type StoryService interface {
 	// Public methods
 	GetStoryBySlug(slug string) (*models.Story, error)
-	RecordStoryView(storyID uuid.UUID, userID *uuid.UUID, ipAddress string) error // Fair view counting
+	RecordStoryView(storyID uuid.UUID, userID *uuid.UUID, ipAddress, userAgent string) error // Fair view counting
 	GetAllStories(page, limit int) ([]models.Story, int64, error)
+	GetAllStoriesCursor(cursor *pagination.Cursor, limit int) ([]models.Story, pagination.Cursors, error)
 	GetStoriesByGenre(genreSlug string, page, limit int) ([]models.Story, int64, error)
 	GetLatestStories(limit int) ([]models.Story, error)
 	GetHotStories(limit int) ([]models.Story, error)
+	GetTopRatedStories(limit int) ([]models.Story, error)
 	SearchStories(query string, page, limit int) ([]models.Story, int64, error)
+	BrowseStories(filter repositories.StoryBrowseFilter, page, limit int) ([]models.Story, int64, *repositories.StoryFacets, error)
 	GetRandomStory() (*models.Story, error)
 	GetAllGenres() ([]models.Genre, error)
 
@@ -35,24 +40,30 @@ type StoryService interface {
 	SearchStoriesAdmin(query string, page, limit int) ([]models.Story, int64, error)
 }
 
+// topRatedMinVotes - Số lượt đánh giá tối thiểu để vào bảng xếp hạng, đồng thời là trọng số m của Bayesian average
+const topRatedMinVotes = 5
+
 type storyService struct {
-	storyRepo     repositories.StoryRepository
-	genreRepo     repositories.GenreRepository
-	storyViewRepo repositories.StoryViewRepository
-	uploadService UploadService
+	storyRepo       repositories.StoryRepository
+	genreRepo       repositories.GenreRepository
+	viewAggregator  ViewAggregator
+	storyRatingRepo repositories.StoryRatingRepository
+	publicCache     *PublicCache
 }
 
 func NewStoryService(
 	storyRepo repositories.StoryRepository,
 	genreRepo repositories.GenreRepository,
-	storyViewRepo repositories.StoryViewRepository,
-	uploadService UploadService,
+	viewAggregator ViewAggregator,
+	storyRatingRepo repositories.StoryRatingRepository,
+	publicCache *PublicCache,
 ) StoryService {
 	return &storyService{
-		storyRepo:     storyRepo,
-		genreRepo:     genreRepo,
-		storyViewRepo: storyViewRepo,
-		uploadService: uploadService,
+		storyRepo:       storyRepo,
+		genreRepo:       genreRepo,
+		viewAggregator:  viewAggregator,
+		storyRatingRepo: storyRatingRepo,
+		publicCache:     publicCache,
 	}
 }
 
@@ -72,7 +83,11 @@ func (s *storyService) CreateStory(story *models.Story) error {
 	story.CreatedAt = time.Now()
 	story.UpdatedAt = time.Now()
 
-	return s.storyRepo.CreateStory(story)
+	if err := s.storyRepo.CreateStory(story); err != nil {
+		return err
+	}
+	s.publicCache.invalidateStory(story.Slug)
+	return nil
 }
 
 // UpdateStory - Cập nhật truyện (Admin)
@@ -81,6 +96,7 @@ func (s *storyService) UpdateStory(id uuid.UUID, updatedStory *models.Story) err
 	if err != nil {
 		return errors.New("truyện không tồn tại")
 	}
+	oldSlug := existingStory.Slug
 
 	// Update fields
 	if updatedStory.Title != "" {
@@ -106,37 +122,7 @@ func (s *storyService) UpdateStory(id uuid.UUID, updatedStory *models.Story) err
 		existingStory.Description = updatedStory.Description
 	}
 	if updatedStory.CoverImageURL != nil {
-		// Delete old cover image if it exists and is different from the new one
-		if existingStory.CoverImageURL != nil &&
-			*existingStory.CoverImageURL != "" &&
-			*existingStory.CoverImageURL != *updatedStory.CoverImageURL {
-			
-			// Debug: Log old and new URLs
-			println("[StoryService] Old cover URL:", *existingStory.CoverImageURL)
-			println("[StoryService] New cover URL:", *updatedStory.CoverImageURL)
-			
-			oldPublicID := extractCloudinaryPublicID(*existingStory.CoverImageURL)
-			println("[StoryService] Extracted public_id:", oldPublicID)
-			
-			if oldPublicID != "" && s.uploadService != nil {
-				// Delete asynchronously, don't block update if delete fails
-				go func(publicID string) {
-					println("[StoryService] Attempting to delete:", publicID)
-					if err := s.uploadService.DeleteImage(publicID); err != nil {
-						println("[StoryService] Failed to delete old cover:", err.Error())
-					} else {
-						println("[StoryService] Successfully deleted old cover:", publicID)
-					}
-				}(oldPublicID)
-			} else {
-				if oldPublicID == "" {
-					println("[StoryService] WARNING: Could not extract public_id from URL")
-				}
-				if s.uploadService == nil {
-					println("[StoryService] WARNING: uploadService is nil")
-				}
-			}
-		}
+		// Cover cũ không xóa ở đây, job media GC dọn sau grace period khi không còn được tham chiếu
 		existingStory.CoverImageURL = updatedStory.CoverImageURL
 	}
 	if updatedStory.Status != "" {
@@ -145,7 +131,11 @@ func (s *storyService) UpdateStory(id uuid.UUID, updatedStory *models.Story) err
 	existingStory.IsPublished = updatedStory.IsPublished
 	existingStory.UpdatedAt = time.Now()
 
-	return s.storyRepo.UpdateStory(existingStory)
+	if err := s.storyRepo.UpdateStory(existingStory); err != nil {
+		return err
+	}
+	s.publicCache.invalidateStory(oldSlug, existingStory.Slug)
+	return nil
 }
 
 // UpdateStoryGenres - Cập nhật thể loại của truyện (Admin)
@@ -160,16 +150,24 @@ func (s *storyService) UpdateStoryGenres(storyID uuid.UUID, genreIDStrings []str
 		genreIDs = append(genreIDs, id)
 	}
 	
-	return s.storyRepo.UpdateStoryGenres(storyID, genreIDs)
+	if err := s.storyRepo.UpdateStoryGenres(storyID, genreIDs); err != nil {
+		return err
+	}
+	s.publicCache.invalidateStoryByID(storyID)
+	return nil
 }
 
 // DeleteStory - Xóa truyện (Admin)
 func (s *storyService) DeleteStory(id uuid.UUID) error {
-	_, err := s.storyRepo.FindStoryByID(id)
+	story, err := s.storyRepo.FindStoryByID(id)
 	if err != nil {
 		return errors.New("truyện không tồn tại")
 	}
-	return s.storyRepo.DeleteStory(id)
+	if err := s.storyRepo.DeleteStory(id); err != nil {
+		return err
+	}
+	s.publicCache.invalidateStory(story.Slug)
+	return nil
 }
 
 // GetStoryByID - Lấy truyện theo ID (Admin)
@@ -184,38 +182,32 @@ func (s *storyService) GetStoryByID(id uuid.UUID) (*models.Story, error) {
 // GetStoryBySlug - Lấy truyện theo slug (Public)
 // Note: Does NOT increment view count - call RecordStoryView separately
 func (s *storyService) GetStoryBySlug(storySlug string) (*models.Story, error) {
-	story, err := s.storyRepo.FindStoryBySlug(storySlug)
-	if err != nil {
-		return nil, errors.New("truyện không tồn tại")
-	}
-	return story, nil
+	return cached(s.publicCache, storyCacheKey(storySlug), func() (*models.Story, error) {
+		story, err := s.storyRepo.FindStoryDetailBySlug(storySlug)
+		if err != nil {
+			return nil, errors.New("truyện không tồn tại")
+		}
+
+		// Rating histogram (1-5 sao)
+		if distribution, err := s.storyRatingRepo.GetRatingDistribution(story.ID); err == nil {
+			story.RatingDistribution = distribution
+		}
+		return story, nil
+	})
 }
 
-// RecordStoryView - Fair view counting (1 view per user/IP per 24h)
-func (s *storyService) RecordStoryView(storyID uuid.UUID, userID *uuid.UUID, ipAddress string) error {
-	// Check if already viewed in last 24 hours
-	hasViewed, err := s.storyViewRepo.HasViewedRecently(storyID, userID, ipAddress, 24*time.Hour)
-	if err != nil {
-		return err
-	}
+// viewCountWindow - 1 view per user/IP per window, dùng chung cho story và chapter
+const viewCountWindow = 24 * time.Hour
 
-	if hasViewed {
-		// Already counted, skip
+// RecordStoryView - Fair view counting (1 view per user/IP per 24h, bỏ qua bot)
+// View được gom trong RAM và ghi xuống DB theo lô bởi ViewAggregator
+func (s *storyService) RecordStoryView(storyID uuid.UUID, userID *uuid.UUID, ipAddress, userAgent string) error {
+	if utils.IsBotUserAgent(userAgent) {
 		return nil
 	}
 
-	// Record the view
-	view := &models.StoryView{
-		StoryID:   storyID,
-		UserID:    userID,
-		IPAddress: ipAddress,
-	}
-	if err := s.storyViewRepo.RecordView(view); err != nil {
-		return err
-	}
-
-	// Increment the story's view count
-	return s.storyRepo.IncrementViewCountStory(storyID)
+	s.viewAggregator.Record(storyID, nil, userID, ipAddress)
+	return nil
 }
 
 // GetAllStories - Lấy tất cả truyện đã publish (Public)
@@ -223,6 +215,11 @@ func (s *storyService) GetAllStories(page, limit int) ([]models.Story, int64, er
 	return s.storyRepo.GetAllStories(page, limit, true)
 }
 
+// GetAllStoriesCursor - Lấy truyện đã xuất bản theo cursor (Public, infinite scroll)
+func (s *storyService) GetAllStoriesCursor(cursor *pagination.Cursor, limit int) ([]models.Story, pagination.Cursors, error) {
+	return s.storyRepo.GetAllStoriesCursor(cursor, limit, true)
+}
+
 // GetAllStoriesAdmin - Lấy tất cả truyện (Admin)
 func (s *storyService) GetAllStoriesAdmin(page, limit int) ([]models.Story, int64, error) {
 	return s.storyRepo.GetAllStories(page, limit, false)
@@ -247,12 +244,21 @@ func (s *storyService) GetStoriesByGenre(genreSlug string, page, limit int) ([]m
 
 // GetLatestStories - Lấy truyện mới cập nhật (Public)
 func (s *storyService) GetLatestStories(limit int) ([]models.Story, error) {
-	return s.storyRepo.GetStoriesLatest(limit)
+	return cached(s.publicCache, latestStoriesCacheKey(limit), func() ([]models.Story, error) {
+		return s.storyRepo.GetStoriesLatest(limit)
+	})
 }
 
 // GetHotStories - Lấy truyện hot (Public)
 func (s *storyService) GetHotStories(limit int) ([]models.Story, error) {
-	return s.storyRepo.GetStoriesHot(limit)
+	return cached(s.publicCache, hotStoriesCacheKey(limit), func() ([]models.Story, error) {
+		return s.storyRepo.GetStoriesHot(limit)
+	})
+}
+
+// GetTopRatedStories - Lấy truyện được đánh giá cao (Public)
+func (s *storyService) GetTopRatedStories(limit int) ([]models.Story, error) {
+	return s.storyRepo.GetStoriesTopRated(limit, topRatedMinVotes)
 }
 
 // SearchStories - Tìm kiếm truyện (Public)
@@ -263,6 +269,21 @@ func (s *storyService) SearchStories(query string, page, limit int) ([]models.St
 	return s.storyRepo.SearchStories(query, page, limit)
 }
 
+// BrowseStories - Duyệt truyện theo bộ lọc, kèm facet counts (Public)
+func (s *storyService) BrowseStories(filter repositories.StoryBrowseFilter, page, limit int) ([]models.Story, int64, *repositories.StoryFacets, error) {
+	stories, total, err := s.storyRepo.BrowseStories(filter, page, limit)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	facets, err := s.storyRepo.GetBrowseFacets(filter)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	return stories, total, facets, nil
+}
+
 // GetRandomStory - Lấy truyện ngẫu nhiên (Public)
 func (s *storyService) GetRandomStory() (*models.Story, error) {
 	stories, _, err := s.storyRepo.GetAllStories(1, 100, true)
@@ -277,7 +298,7 @@ func (s *storyService) GetRandomStory() (*models.Story, error) {
 
 // GetAllGenres - Lấy tất cả thể loại (Public)
 func (s *storyService) GetAllGenres() ([]models.Genre, error) {
-	return s.genreRepo.GetAllGenres()
+	return cached(s.publicCache, cacheKeyGenres, s.genreRepo.GetAllGenres)
 }
 
 // Helper: Generate unique slug
@@ -299,65 +320,3 @@ func (s *storyService) generateUniqueSlug(title string) string {
 	return baseSlug + "-" + uuid.New().String()[:8]
 }
 
-// Helper: Extract Cloudinary public ID from URL
-// Cloudinary URLs format: https://res.cloudinary.com/{cloud}/image/upload/{transformations}/{version}/{folder}/{filename}
-// public_id = folder/filename (without extension)
-func extractCloudinaryPublicID(url string) string {
-	if url == "" {
-		return ""
-	}
-
-	// Find "upload/" in URL
-	uploadIdx := strings.Index(url, "/upload/")
-	if uploadIdx == -1 {
-		return ""
-	}
-
-	// Get everything after "upload/"
-	afterUpload := url[uploadIdx+8:]
-
-	// Split by "/"
-	parts := strings.Split(afterUpload, "/")
-	if len(parts) < 2 {
-		return ""
-	}
-
-	// Skip version (starts with "v") and transformations (contains "_" like "f_webp")
-	var publicIDParts []string
-	for _, part := range parts {
-		// Skip version numbers (v123456789)
-		if len(part) > 1 && part[0] == 'v' {
-			isVersion := true
-			for _, c := range part[1:] {
-				if c < '0' || c > '9' {
-					isVersion = false
-					break
-				}
-			}
-			if isVersion {
-				continue
-			}
-		}
-		// Skip transformations (contain specific patterns)
-		if strings.Contains(part, "_") && (strings.HasPrefix(part, "f_") ||
-			strings.HasPrefix(part, "q_") ||
-			strings.HasPrefix(part, "w_") ||
-			strings.HasPrefix(part, "h_")) {
-			continue
-		}
-		publicIDParts = append(publicIDParts, part)
-	}
-
-	if len(publicIDParts) == 0 {
-		return ""
-	}
-
-	// Join and remove file extension
-	publicID := strings.Join(publicIDParts, "/")
-	if lastDot := strings.LastIndex(publicID, "."); lastDot != -1 {
-		publicID = publicID[:lastDot]
-	}
-
-	return publicID
-}
-
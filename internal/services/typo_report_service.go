@@ -0,0 +1,210 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"nekozanedex/internal/models"
+	"nekozanedex/internal/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TypoReportService interface {
+	SubmitReport(userID *uuid.UUID, chapterID uuid.UUID, originalText string, suggestedText, positionHint *string) (*models.TypoReport, error)
+	GetReports(page, limit int, status string) ([]models.TypoReport, int64, error)
+	ResolveReport(id uuid.UUID, adminID uuid.UUID, status string, suggestedOverride *string) (*models.TypoReport, error)
+}
+
+type typoReportService struct {
+	reportRepo          repositories.TypoReportRepository
+	chapterRepo         repositories.ChapterRepository
+	notificationService NotificationService
+	publicCache         *PublicCache
+}
+
+func NewTypoReportService(
+	reportRepo repositories.TypoReportRepository,
+	chapterRepo repositories.ChapterRepository,
+	notificationService NotificationService,
+	publicCache *PublicCache,
+) TypoReportService {
+	return &typoReportService{
+		reportRepo:          reportRepo,
+		chapterRepo:         chapterRepo,
+		notificationService: notificationService,
+		publicCache:         publicCache,
+	}
+}
+
+// SubmitReport - Gửi báo lỗi chính tả (ẩn danh hoặc đã đăng nhập)
+func (s *typoReportService) SubmitReport(userID *uuid.UUID, chapterID uuid.UUID, originalText string, suggestedText, positionHint *string) (*models.TypoReport, error) {
+	if strings.TrimSpace(originalText) == "" {
+		return nil, errors.New("đoạn văn bản lỗi không được để trống")
+	}
+
+	chapter, err := s.chapterRepo.FindByID(chapterID)
+	if err != nil || !chapter.IsPublished {
+		return nil, errors.New("chapter không tồn tại")
+	}
+
+	if !strings.Contains(chapter.Content, originalText) {
+		return nil, errors.New("không tìm thấy đoạn văn bản lỗi trong chapter")
+	}
+
+	report := &models.TypoReport{
+		UserID:        userID,
+		ChapterID:     chapterID,
+		OriginalText:  originalText,
+		SuggestedText: suggestedText,
+		PositionHint:  positionHint,
+		Status:        "pending",
+	}
+	if err := s.reportRepo.CreateReport(report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// GetReports - Lấy hàng đợi báo lỗi (Admin)
+func (s *typoReportService) GetReports(page, limit int, status string) ([]models.TypoReport, int64, error) {
+	return s.reportRepo.GetReports(page, limit, status)
+}
+
+// ResolveReport - Xử lý báo lỗi (Admin)
+// status = fixed: thay OriginalText bằng SuggestedText (hoặc suggestedOverride) trong Chapter.Content
+// status = rejected: chỉ đổi trạng thái
+func (s *typoReportService) ResolveReport(id uuid.UUID, adminID uuid.UUID, status string, suggestedOverride *string) (*models.TypoReport, error) {
+	if status != "fixed" && status != "rejected" {
+		return nil, errors.New("trạng thái không hợp lệ")
+	}
+
+	report, err := s.reportRepo.FindReportByID(id)
+	if err != nil {
+		return nil, errors.New("báo lỗi không tồn tại")
+	}
+	if report.Status != "pending" {
+		return nil, errors.New("báo lỗi đã được xử lý")
+	}
+
+	var fix *repositories.TypoFix
+	if status == "fixed" {
+		suggested := report.SuggestedText
+		if suggestedOverride != nil {
+			suggested = suggestedOverride
+		}
+		if suggested == nil {
+			return nil, errors.New("báo lỗi không có đề xuất sửa")
+		}
+		report.SuggestedText = suggested
+
+		note := "Sửa lỗi chính tả từ báo lỗi"
+		fix = &repositories.TypoFix{
+			ChapterID: report.ChapterID,
+			EditorID:  &adminID,
+			Note:      &note,
+			Apply: func(content string) (string, error) {
+				return applyTypoFix(content, report.OriginalText, *suggested, report.PositionHint)
+			},
+		}
+	}
+
+	// Đổi trạng thái và sửa chapter trong cùng transaction: nếu sửa lỗi thất bại thì báo lỗi vẫn pending
+	chapter, err := s.reportRepo.ResolveReport(id, status, fix)
+	if err != nil {
+		if errors.Is(err, repositories.ErrTypoReportResolved) {
+			return nil, errors.New("báo lỗi đã được xử lý")
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("chapter không tồn tại")
+		}
+		return nil, err
+	}
+	if chapter != nil {
+		s.publicCache.invalidateStory(chapter.Story.Slug)
+	}
+	report.Status = status
+
+	s.notifyReporter(report)
+
+	return report, nil
+}
+
+// notifyReporter - Thông báo cho người báo lỗi (bỏ qua nếu ẩn danh)
+func (s *typoReportService) notifyReporter(report *models.TypoReport) {
+	if report.UserID == nil || s.notificationService == nil {
+		return
+	}
+
+	chapterName := fmt.Sprintf("chapter %d", report.Chapter.ChapterNumber)
+	if report.Chapter.Story.Title != "" {
+		chapterName = report.Chapter.Story.Title + " - " + chapterName
+	}
+
+	title := "✅ Báo lỗi của bạn đã được sửa"
+	content := "Cảm ơn bạn! Lỗi chính tả trong " + chapterName + " đã được sửa"
+	if report.Status == "rejected" {
+		title = "📝 Báo lỗi của bạn đã được xem xét"
+		content = "Báo lỗi chính tả trong " + chapterName + " không được chấp nhận"
+	}
+	link := "/client/stories/" + report.Chapter.Story.Slug
+
+	_ = s.notificationService.CreateNotification(*report.UserID, "typo_report", title, &content, &link)
+}
+
+// applyTypoFix - Thay original bằng suggested trong content
+// positionHint là vị trí ký tự (rune offset) gợi ý; nếu có nhiều chỗ trùng thì chọn chỗ gần nhất với gợi ý
+func applyTypoFix(content, original, suggested string, positionHint *string) (string, error) {
+	var offsets []int
+	for start := 0; ; {
+		idx := strings.Index(content[start:], original)
+		if idx == -1 {
+			break
+		}
+		offsets = append(offsets, start+idx)
+		start += idx + len(original)
+	}
+
+	if len(offsets) == 0 {
+		return "", errors.New("đoạn văn bản lỗi không còn tồn tại trong chapter")
+	}
+
+	target := offsets[0]
+	if len(offsets) > 1 {
+		hint, ok := parsePositionHint(positionHint)
+		if !ok {
+			return "", errors.New("đoạn văn bản lỗi xuất hiện nhiều lần, cần vị trí gợi ý")
+		}
+
+		bestDistance := -1
+		for _, offset := range offsets {
+			distance := utf8.RuneCountInString(content[:offset]) - hint
+			if distance < 0 {
+				distance = -distance
+			}
+			if bestDistance == -1 || distance < bestDistance {
+				bestDistance = distance
+				target = offset
+			}
+		}
+	}
+
+	return content[:target] + suggested + content[target+len(original):], nil
+}
+
+// parsePositionHint - Đọc vị trí gợi ý dạng số nguyên
+func parsePositionHint(positionHint *string) (int, bool) {
+	if positionHint == nil {
+		return 0, false
+	}
+	hint, err := strconv.Atoi(strings.TrimSpace(*positionHint))
+	if err != nil || hint < 0 {
+		return 0, false
+	}
+	return hint, true
+}
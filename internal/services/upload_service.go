@@ -1,120 +1,129 @@
 package services
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"log"
+	"mime"
 	"mime/multipart"
 	"path/filepath"
 	"strings"
 
 	"nekozanedex/internal/config"
+	"nekozanedex/internal/models"
+	"nekozanedex/internal/repositories"
+	"nekozanedex/pkg/storage"
 
-	"github.com/cloudinary/cloudinary-go/v2"
-	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+	"github.com/google/uuid"
 )
 
+// MediaOwner - Entity sở hữu file upload, ghi vào media_assets cho job GC
+type MediaOwner struct {
+	Type       string     // models.MediaOwner*, rỗng = chưa rõ
+	ID         *uuid.UUID // nil nếu entity chưa tồn tại lúc upload
+	UploadedBy *uuid.UUID
+}
+
 type UploadService interface {
-	UploadImage(file multipart.File, filename string, folder string) (string, error)
-	UploadImageBytes(data []byte, filename string, folder string) (string, error)
-	UploadMultipleImages(files []*multipart.FileHeader, folder string) ([]string, error)
+	UploadImage(file multipart.File, filename string, folder string, owner MediaOwner) (string, error)
+	UploadImageBytes(data []byte, filename string, folder string, owner MediaOwner) (string, error)
+	UploadMultipleImages(files []*multipart.FileHeader, folder string, owner MediaOwner) ([]string, error)
 	DeleteImage(publicID string) error
+	// PublicIDFromURL - ID để truyền vào DeleteImage, "" nếu URL không thuộc storage hiện tại
+	PublicIDFromURL(url string) string
+	StorageDriver() string
 }
 
 type uploadService struct {
-	cld *cloudinary.Cloudinary
-	cfg *config.Config
+	driver         storage.Driver
+	mediaAssetRepo repositories.MediaAssetRepository
 }
 
-func NewUploadService(cfg *config.Config) (UploadService, error) {
-	// Validate config
-	if cfg.Cloudinary.CloudName == "" || cfg.Cloudinary.APIKey == "" || cfg.Cloudinary.APISecret == "" {
-		return nil, errors.New("cloudinary config is not set")
-	}
-
-	// Initialize Cloudinary
-	cld, err := cloudinary.NewFromParams(
-		cfg.Cloudinary.CloudName,
-		cfg.Cloudinary.APIKey,
-		cfg.Cloudinary.APISecret,
-	)
+// NewUploadService - Chọn storage driver theo cfg.Storage.Driver (local, s3, cloudinary).
+// mediaAssetRepo nil = không ghi lại file upload (GC sẽ không dọn được)
+func NewUploadService(cfg *config.Config, mediaAssetRepo repositories.MediaAssetRepository) (UploadService, error) {
+	driver, err := newStorageDriver(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize cloudinary: %w", err)
+		return nil, err
 	}
-
-	return &uploadService{
-		cld: cld,
-		cfg: cfg,
-	}, nil
+	return &uploadService{driver: driver, mediaAssetRepo: mediaAssetRepo}, nil
 }
 
-// UploadImage - Upload single image to Cloudinary
-func (s *uploadService) UploadImage(file multipart.File, filename string, folder string) (string, error) {
-	ctx := context.Background()
-
-	// Get file extension
-	ext := strings.ToLower(filepath.Ext(filename))
-	if !isValidImageExtension(ext) {
-		return "", errors.New("định dạng file không hợp lệ (chỉ chấp nhận jpg, jpeg, png, webp, gif)")
+func newStorageDriver(cfg *config.Config) (storage.Driver, error) {
+	switch cfg.Storage.Driver {
+	case "local":
+		return storage.NewLocal(cfg.Storage.LocalDir, cfg.Storage.PublicURL)
+	case "s3":
+		return storage.NewS3(storage.S3Options{
+			Endpoint:  cfg.Storage.S3Endpoint,
+			Region:    cfg.Storage.S3Region,
+			Bucket:    cfg.Storage.S3Bucket,
+			AccessKey: cfg.Storage.S3AccessKey,
+			SecretKey: cfg.Storage.S3SecretKey,
+			PathStyle: cfg.Storage.S3PathStyle,
+			PublicURL: cfg.Storage.S3PublicURL,
+		})
+	case "cloudinary":
+		return storage.NewCloudinary(cfg.Cloudinary.CloudName, cfg.Cloudinary.APIKey, cfg.Cloudinary.APISecret)
+	default:
+		return nil, fmt.Errorf("STORAGE_DRIVER không hợp lệ: %q (local, s3, cloudinary)", cfg.Storage.Driver)
 	}
+}
 
-	// Upload to Cloudinary
-	uploadResult, err := s.cld.Upload.Upload(ctx, file, uploader.UploadParams{
-		Folder:         folder,
-		ResourceType:   "image",
-		AllowedFormats: []string{"jpg", "jpeg", "png", "webp", "gif"},
-		Transformation: "f_webp,q_auto", // Force WebP with auto quality
-	})
+// UploadImage - Upload single image
+func (s *uploadService) UploadImage(file multipart.File, filename string, folder string, owner MediaOwner) (string, error) {
+	data, err := io.ReadAll(file)
 	if err != nil {
 		return "", fmt.Errorf("upload failed: %w", err)
 	}
-
-	// Debug: Log result
-	fmt.Printf("[Cloudinary] Upload result - SecureURL: %s, Error: %v\n", uploadResult.SecureURL, uploadResult.Error)
-	
-	if uploadResult.Error.Message != "" {
-		return "", fmt.Errorf("cloudinary error: %s", uploadResult.Error.Message)
-	}
-
-	return uploadResult.SecureURL, nil
+	return s.UploadImageBytes(data, filename, folder, owner)
 }
 
-// UploadImageBytes - Upload image bytes to Cloudinary (for processed images)
-func (s *uploadService) UploadImageBytes(data []byte, filename string, folder string) (string, error) {
-	ctx := context.Background()
-
+// UploadImageBytes - Upload image bytes (for processed images)
+func (s *uploadService) UploadImageBytes(data []byte, filename string, folder string, owner MediaOwner) (string, error) {
 	// Get file extension
 	ext := strings.ToLower(filepath.Ext(filename))
 	if !isValidImageExtension(ext) {
 		return "", errors.New("định dạng file không hợp lệ (chỉ chấp nhận jpg, jpeg, png, webp, gif)")
 	}
 
-	// Create reader from bytes
-	reader := bytes.NewReader(data)
+	// Key mới cho mỗi lần upload: <folder>/<uuid><ext>
+	key := uuid.New().String() + ext
+	if folder = sanitizeStorageFolder(folder); folder != "" {
+		key = folder + "/" + key
+	}
 
-	// Upload to Cloudinary with WebP conversion
-	uploadResult, err := s.cld.Upload.Upload(ctx, reader, uploader.UploadParams{
-		Folder:         folder,
-		ResourceType:   "image",
-		Transformation: "f_webp,q_auto", // Force WebP format with auto quality
-	})
+	url, err := s.driver.Put(context.Background(), key, data, mime.TypeByExtension(ext))
 	if err != nil {
 		return "", fmt.Errorf("upload failed: %w", err)
 	}
+	s.trackAsset(url, key, int64(len(data)), owner)
+	return url, nil
+}
 
-	// Debug: Log result
-	fmt.Printf("[Cloudinary] Upload bytes result - SecureURL: %s, Error: %v\n", uploadResult.SecureURL, uploadResult.Error)
-
-	if uploadResult.Error.Message != "" {
-		return "", fmt.Errorf("cloudinary error: %s", uploadResult.Error.Message)
+// trackAsset - Ghi file vào media_assets. Lỗi chỉ log: upload vẫn thành công, file chỉ không được GC dọn
+func (s *uploadService) trackAsset(url, key string, size int64, owner MediaOwner) {
+	if s.mediaAssetRepo == nil {
+		return
+	}
+	asset := &models.MediaAsset{
+		URL:        url,
+		StorageKey: key,
+		Driver:     s.driver.Name(),
+		OwnerType:  owner.Type,
+		OwnerID:    owner.ID,
+		UploadedBy: owner.UploadedBy,
+		Size:       size,
+	}
+	if err := s.mediaAssetRepo.Create(asset); err != nil {
+		log.Printf("[Upload] Failed to track media asset %s: %v", url, err)
 	}
-
-	return uploadResult.SecureURL, nil
 }
 
 // UploadMultipleImages - Upload multiple images (for manga chapters)
-func (s *uploadService) UploadMultipleImages(files []*multipart.FileHeader, folder string) ([]string, error) {
+func (s *uploadService) UploadMultipleImages(files []*multipart.FileHeader, folder string, owner MediaOwner) ([]string, error) {
 	urls := make([]string, 0, len(files))
 
 	for i, fileHeader := range files {
@@ -124,7 +133,7 @@ func (s *uploadService) UploadMultipleImages(files []*multipart.FileHeader, fold
 		}
 		defer file.Close()
 
-		url, err := s.UploadImage(file, fileHeader.Filename, folder)
+		url, err := s.UploadImage(file, fileHeader.Filename, folder, owner)
 		if err != nil {
 			return nil, fmt.Errorf("failed to upload file %d: %w", i, err)
 		}
@@ -135,20 +144,42 @@ func (s *uploadService) UploadMultipleImages(files []*multipart.FileHeader, fold
 	return urls, nil
 }
 
-// DeleteImage - Delete image from Cloudinary
+// DeleteImage - Xóa file theo ID của driver (public_id với Cloudinary, key với local/S3)
 func (s *uploadService) DeleteImage(publicID string) error {
-	ctx := context.Background()
-
-	_, err := s.cld.Upload.Destroy(ctx, uploader.DestroyParams{
-		PublicID: publicID,
-	})
-	if err != nil {
+	if err := s.driver.Delete(context.Background(), publicID); err != nil {
 		return fmt.Errorf("delete failed: %w", err)
 	}
-
 	return nil
 }
 
+func (s *uploadService) PublicIDFromURL(url string) string {
+	if url == "" {
+		return ""
+	}
+	return s.driver.KeyFromURL(url)
+}
+
+func (s *uploadService) StorageDriver() string {
+	return s.driver.Name()
+}
+
+// sanitizeStorageFolder - Chỉ giữ a-z 0-9 - _ . / trong folder, bỏ segment rỗng, "." và ".."
+func sanitizeStorageFolder(folder string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(folder) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '_' || r == '.' || r == '/' {
+			b.WriteRune(r)
+		}
+	}
+	segments := make([]string, 0)
+	for _, segment := range strings.Split(b.String(), "/") {
+		if segment != "" && segment != "." && segment != ".." {
+			segments = append(segments, segment)
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
 // Helper: Check valid image extensions
 func isValidImageExtension(ext string) bool {
 	validExts := map[string]bool{
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"nekozanedex/internal/models"
+	"nekozanedex/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+const (
+	viewFlushInterval = 10 * time.Second
+	viewFlushSize     = 2000   // Đủ ngần này view đang chờ thì flush sớm, không đợi ticker
+	maxPendingViews   = 50000  // DB lỗi kéo dài thì bỏ bớt thay vì giữ RAM vô hạn
+	maxSeenViewers    = 200000 // Quá ngưỡng thì xóa bộ nhớ dedupe, DB vẫn dedupe lúc flush
+)
+
+// ViewAggregator - Gom lượt xem story/chapter trong RAM và ghi xuống DB theo lô
+type ViewAggregator interface {
+	Record(storyID uuid.UUID, chapterID, userID *uuid.UUID, ipAddress string)
+	Close() // Flush phần còn lại và dừng, gọi khi shutdown
+}
+
+type viewAggregator struct {
+	viewRepo repositories.StoryViewRepository
+
+	mu      sync.Mutex
+	seen    map[string]time.Time        // viewer key -> hết hạn cửa sổ dedupe
+	pending map[string]models.StoryView // view chờ flush, key giống seen
+	closed  bool
+
+	flushNow  chan struct{}
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+}
+
+func NewViewAggregator(viewRepo repositories.StoryViewRepository) ViewAggregator {
+	a := &viewAggregator{
+		viewRepo: viewRepo,
+		seen:     make(map[string]time.Time),
+		pending:  make(map[string]models.StoryView),
+		flushNow: make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+// Record - Ghi nhận 1 lượt xem (chapterID nil = view cấp truyện)
+// Cùng user (hoặc IP nếu ẩn danh) xem lại trong viewCountWindow thì bỏ qua ngay trong RAM
+func (a *viewAggregator) Record(storyID uuid.UUID, chapterID, userID *uuid.UUID, ipAddress string) {
+	key := viewKey(storyID, chapterID, userID, ipAddress)
+	now := time.Now()
+
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return
+	}
+	if expiresAt, ok := a.seen[key]; ok && now.Before(expiresAt) {
+		a.mu.Unlock()
+		return
+	}
+	a.seen[key] = now.Add(viewCountWindow)
+	if len(a.pending) < maxPendingViews {
+		a.pending[key] = models.StoryView{
+			StoryID:   storyID,
+			ChapterID: chapterID,
+			UserID:    userID,
+			IPAddress: ipAddress,
+			ViewedAt:  now,
+			ViewCount: 1,
+		}
+	}
+	full := len(a.pending) >= viewFlushSize
+	a.mu.Unlock()
+
+	if full {
+		select {
+		case a.flushNow <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Close - Dừng vòng flush và ghi nốt các view đang chờ
+func (a *viewAggregator) Close() {
+	a.closeOnce.Do(func() {
+		close(a.done)
+		<-a.stopped
+	})
+}
+
+func (a *viewAggregator) run() {
+	defer close(a.stopped)
+
+	ticker := time.NewTicker(viewFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			a.flush()
+		case <-a.flushNow:
+			a.flush()
+		case <-a.done:
+			a.mu.Lock()
+			a.closed = true
+			a.mu.Unlock()
+			a.flush()
+			return
+		}
+	}
+}
+
+// flush - Lấy toàn bộ view đang chờ và ghi trong 1 transaction
+func (a *viewAggregator) flush() {
+	a.mu.Lock()
+	batch := a.pending
+	a.pending = make(map[string]models.StoryView)
+	a.pruneSeen(time.Now())
+	a.mu.Unlock()
+
+	if len(batch) == 0 {
+		return
+	}
+
+	views := make([]models.StoryView, 0, len(batch))
+	for _, view := range batch {
+		views = append(views, view)
+	}
+
+	counted, err := a.viewRepo.FlushViews(views, viewCountWindow)
+	if err != nil {
+		log.Printf("❌ Failed to flush %d view(s): %v", len(views), err)
+		// Trả lại hàng đợi để lần sau thử lại (view mới hơn cùng key được giữ)
+		a.mu.Lock()
+		for key, view := range batch {
+			if _, exists := a.pending[key]; !exists && len(a.pending) < maxPendingViews {
+				a.pending[key] = view
+			}
+		}
+		a.mu.Unlock()
+		return
+	}
+	if counted > 0 {
+		log.Printf("👁️ Flushed %d view(s) (%d duplicate(s) skipped)", counted, len(views)-counted)
+	}
+}
+
+// pruneSeen - Xóa key đã hết cửa sổ dedupe, phải giữ a.mu khi gọi
+func (a *viewAggregator) pruneSeen(now time.Time) {
+	for key, expiresAt := range a.seen {
+		if !now.Before(expiresAt) {
+			delete(a.seen, key)
+		}
+	}
+	if len(a.seen) > maxSeenViewers {
+		a.seen = make(map[string]time.Time)
+	}
+}
+
+// viewKey - story|chapter|viewer, viewer = user_id nếu đăng nhập, ngược lại là IP
+func viewKey(storyID uuid.UUID, chapterID, userID *uuid.UUID, ipAddress string) string {
+	target := "s:" + storyID.String()
+	if chapterID != nil {
+		target = "c:" + chapterID.String()
+	}
+	if userID != nil {
+		return target + "|u:" + userID.String()
+	}
+	return target + "|ip:" + ipAddress
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"nekozanedex/internal/models"
+	"nekozanedex/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+type VolumeService interface {
+	GetVolumesByStory(storyID uuid.UUID) ([]models.Volume, error)
+	CreateVolume(storyID uuid.UUID, volume *models.Volume) error
+	UpdateVolume(id uuid.UUID, volume *models.Volume) (*models.Volume, error)
+	DeleteVolume(id uuid.UUID) error
+	MoveChapters(storyID uuid.UUID, chapterIDs []uuid.UUID, volumeID *uuid.UUID) (int64, error)
+}
+
+type volumeService struct {
+	volumeRepo  repositories.VolumeRepository
+	storyRepo   repositories.StoryRepository
+	publicCache *PublicCache
+}
+
+func NewVolumeService(
+	volumeRepo repositories.VolumeRepository,
+	storyRepo repositories.StoryRepository,
+	publicCache *PublicCache,
+) VolumeService {
+	return &volumeService{
+		volumeRepo:  volumeRepo,
+		storyRepo:   storyRepo,
+		publicCache: publicCache,
+	}
+}
+
+// GetVolumesByStory - Danh sách tập của truyện (Admin)
+func (s *volumeService) GetVolumesByStory(storyID uuid.UUID) ([]models.Volume, error) {
+	if _, err := s.storyRepo.FindStoryByID(storyID); err != nil {
+		return nil, errors.New("truyện không tồn tại")
+	}
+	return s.volumeRepo.GetVolumesByStory(storyID)
+}
+
+// CreateVolume - Tạo tập mới (Admin)
+// VolumeNumber = 0 thì tự lấy số tiếp theo
+func (s *volumeService) CreateVolume(storyID uuid.UUID, volume *models.Volume) error {
+	if strings.TrimSpace(volume.Title) == "" {
+		return errors.New("tên tập không được để trống")
+	}
+	if volume.VolumeNumber < 0 {
+		return errors.New("số tập không hợp lệ")
+	}
+
+	story, err := s.storyRepo.FindStoryByID(storyID)
+	if err != nil {
+		return errors.New("truyện không tồn tại")
+	}
+
+	if volume.VolumeNumber == 0 {
+		volumes, err := s.volumeRepo.GetVolumesByStory(storyID)
+		if err != nil {
+			return err
+		}
+		volume.VolumeNumber = 1
+		if len(volumes) > 0 {
+			volume.VolumeNumber = volumes[len(volumes)-1].VolumeNumber + 1
+		}
+	} else {
+		exists, err := s.volumeRepo.ExistsVolumeNumber(storyID, volume.VolumeNumber, uuid.Nil)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return errors.New("số tập đã tồn tại")
+		}
+	}
+
+	volume.StoryID = storyID
+	volume.CreatedAt = time.Now()
+	volume.UpdatedAt = time.Now()
+
+	if err := s.volumeRepo.CreateVolume(volume); err != nil {
+		return err
+	}
+	s.publicCache.invalidateStory(story.Slug)
+	return nil
+}
+
+// UpdateVolume - Cập nhật tập (Admin)
+func (s *volumeService) UpdateVolume(id uuid.UUID, updated *models.Volume) (*models.Volume, error) {
+	volume, err := s.volumeRepo.FindVolumeByID(id)
+	if err != nil {
+		return nil, errors.New("tập không tồn tại")
+	}
+
+	if strings.TrimSpace(updated.Title) != "" {
+		volume.Title = updated.Title
+	}
+	if updated.VolumeNumber > 0 && updated.VolumeNumber != volume.VolumeNumber {
+		exists, err := s.volumeRepo.ExistsVolumeNumber(volume.StoryID, updated.VolumeNumber, volume.ID)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, errors.New("số tập đã tồn tại")
+		}
+		volume.VolumeNumber = updated.VolumeNumber
+	}
+	// Field nil = không gửi lên, giữ nguyên. Chuỗi rỗng = xóa
+	if updated.CoverImageURL != nil {
+		volume.CoverImageURL = nilIfEmpty(updated.CoverImageURL)
+	}
+	if updated.Description != nil {
+		volume.Description = nilIfEmpty(updated.Description)
+	}
+	volume.UpdatedAt = time.Now()
+
+	if err := s.volumeRepo.UpdateVolume(volume); err != nil {
+		return nil, err
+	}
+	s.publicCache.invalidateStoryByID(volume.StoryID)
+	return volume, nil
+}
+
+// DeleteVolume - Xóa tập, chapter bên trong được giữ lại (Admin)
+func (s *volumeService) DeleteVolume(id uuid.UUID) error {
+	volume, err := s.volumeRepo.FindVolumeByID(id)
+	if err != nil {
+		return errors.New("tập không tồn tại")
+	}
+	if err := s.volumeRepo.DeleteVolume(id); err != nil {
+		return err
+	}
+	s.publicCache.invalidateStoryByID(volume.StoryID)
+	return nil
+}
+
+// MoveChapters - Chuyển chapters vào tập (volumeID = nil để bỏ khỏi tập) (Admin)
+func (s *volumeService) MoveChapters(storyID uuid.UUID, chapterIDs []uuid.UUID, volumeID *uuid.UUID) (int64, error) {
+	if len(chapterIDs) == 0 {
+		return 0, errors.New("danh sách chapter trống")
+	}
+	story, err := s.storyRepo.FindStoryByID(storyID)
+	if err != nil {
+		return 0, errors.New("truyện không tồn tại")
+	}
+
+	if volumeID != nil {
+		volume, err := s.volumeRepo.FindVolumeByID(*volumeID)
+		if err != nil || volume.StoryID != storyID {
+			return 0, errors.New("tập không tồn tại")
+		}
+	}
+
+	moved, err := s.volumeRepo.MoveChapters(storyID, chapterIDs, volumeID)
+	if err != nil {
+		return 0, err
+	}
+	s.publicCache.invalidateStory(story.Slug)
+	return moved, nil
+}
+
+// nilIfEmpty - Chuỗi rỗng (sau khi trim) thành nil để xóa giá trị optional
+func nilIfEmpty(value *string) *string {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return nil
+	}
+	return value
+}
@@ -0,0 +1,121 @@
+package utils
+
+import "strings"
+
+// DiffOp - Loại thay đổi của 1 dòng
+type DiffOp string
+
+const (
+	DiffEqual  DiffOp = "equal"
+	DiffInsert DiffOp = "insert"
+	DiffDelete DiffOp = "delete"
+)
+
+// DiffLine - 1 dòng trong kết quả diff
+// OldLine/NewLine là số dòng (bắt đầu từ 1) ở bản cũ/mới, 0 nếu không có
+type DiffLine struct {
+	Op      DiffOp `json:"op"`
+	Text    string `json:"text"`
+	OldLine int    `json:"old_line,omitempty"`
+	NewLine int    `json:"new_line,omitempty"`
+}
+
+// maxDiffCells - Giới hạn bảng LCS (số dòng cũ × mới) để tránh ngốn RAM với chapter rất dài
+// Vượt giới hạn thì phần giữa được coi là thay thế toàn bộ
+const maxDiffCells = 4_000_000
+
+// DiffLines - So sánh 2 đoạn text theo từng dòng (LCS)
+func DiffLines(oldText, newText string) []DiffLine {
+	a := splitLines(oldText)
+	b := splitLines(newText)
+
+	// Bỏ phần đầu/cuối giống nhau để bảng LCS nhỏ lại
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	result := make([]DiffLine, 0, len(a)+len(b))
+	for i := 0; i < prefix; i++ {
+		result = append(result, DiffLine{Op: DiffEqual, Text: a[i], OldLine: i + 1, NewLine: i + 1})
+	}
+
+	midA := a[prefix : len(a)-suffix]
+	midB := b[prefix : len(b)-suffix]
+	result = append(result, diffMiddle(midA, midB, prefix, prefix)...)
+
+	for i := 0; i < suffix; i++ {
+		oldIdx := len(a) - suffix + i
+		newIdx := len(b) - suffix + i
+		result = append(result, DiffLine{Op: DiffEqual, Text: a[oldIdx], OldLine: oldIdx + 1, NewLine: newIdx + 1})
+	}
+
+	return result
+}
+
+func diffMiddle(a, b []string, oldOffset, newOffset int) []DiffLine {
+	n, m := len(a), len(b)
+	var result []DiffLine
+
+	if n == 0 || m == 0 || n*m > maxDiffCells {
+		for i, line := range a {
+			result = append(result, DiffLine{Op: DiffDelete, Text: line, OldLine: oldOffset + i + 1})
+		}
+		for j, line := range b {
+			result = append(result, DiffLine{Op: DiffInsert, Text: line, NewLine: newOffset + j + 1})
+		}
+		return result
+	}
+
+	// lcs[i][j] = độ dài LCS của a[i:] và b[j:]
+	lcs := make([][]int32, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			result = append(result, DiffLine{Op: DiffEqual, Text: a[i], OldLine: oldOffset + i + 1, NewLine: newOffset + j + 1})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			result = append(result, DiffLine{Op: DiffDelete, Text: a[i], OldLine: oldOffset + i + 1})
+			i++
+		default:
+			result = append(result, DiffLine{Op: DiffInsert, Text: b[j], NewLine: newOffset + j + 1})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		result = append(result, DiffLine{Op: DiffDelete, Text: a[i], OldLine: oldOffset + i + 1})
+	}
+	for ; j < m; j++ {
+		result = append(result, DiffLine{Op: DiffInsert, Text: b[j], NewLine: newOffset + j + 1})
+	}
+	return result
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
@@ -0,0 +1,26 @@
+package utils
+
+import "strings"
+
+// botUserAgentMarkers - Chuỗi con (lowercase) thường gặp trong User-Agent của crawler, preview bot, HTTP client
+var botUserAgentMarkers = []string{
+	"bot", "crawl", "spider", "slurp", "scrapy", "archiver",
+	"facebookexternalhit", "embedly", "preview", "lighthouse", "pagespeed",
+	"headlesschrome", "phantomjs", "selenium", "puppeteer", "playwright",
+	"curl/", "wget/", "python-requests", "python-urllib", "aiohttp", "httpclient",
+	"go-http-client", "java/", "okhttp", "libwww", "postmanruntime", "insomnia",
+}
+
+// IsBotUserAgent - User-Agent rỗng hoặc chứa dấu hiệu bot/tool thì không tính lượt xem
+func IsBotUserAgent(userAgent string) bool {
+	ua := strings.ToLower(strings.TrimSpace(userAgent))
+	if ua == "" {
+		return true
+	}
+	for _, marker := range botUserAgentMarkers {
+		if strings.Contains(ua, marker) {
+			return true
+		}
+	}
+	return false
+}
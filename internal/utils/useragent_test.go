@@ -0,0 +1,40 @@
+package utils
+
+import "testing"
+
+func TestIsBotUserAgent(t *testing.T) {
+	tests := []struct {
+		name      string
+		userAgent string
+		want      bool
+	}{
+		{name: "empty", userAgent: "", want: true},
+		{name: "whitespace", userAgent: "   ", want: true},
+		{name: "googlebot", userAgent: "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", want: true},
+		{name: "bingbot", userAgent: "Mozilla/5.0 (compatible; bingbot/2.0; +http://www.bing.com/bingbot.htm)", want: true},
+		{name: "facebook preview", userAgent: "facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)", want: true},
+		{name: "yahoo slurp", userAgent: "Mozilla/5.0 (compatible; Yahoo! Slurp; http://help.yahoo.com/help/us/ysearch/slurp)", want: true},
+		{name: "headless chrome", userAgent: "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) HeadlessChrome/120.0.0.0 Safari/537.36", want: true},
+		{name: "lighthouse", userAgent: "Mozilla/5.0 (Linux; Android 11; moto g power (2022)) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Mobile Safari/537.36 Chrome-Lighthouse", want: true},
+		{name: "curl", userAgent: "curl/8.4.0", want: true},
+		{name: "wget", userAgent: "Wget/1.21.4", want: true},
+		{name: "go client", userAgent: "Go-http-client/1.1", want: true},
+		{name: "python requests", userAgent: "python-requests/2.31.0", want: true},
+		{name: "okhttp", userAgent: "okhttp/4.12.0", want: true},
+		{name: "postman", userAgent: "PostmanRuntime/7.36.0", want: true},
+		{name: "chrome desktop", userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36", want: false},
+		{name: "safari ios", userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1", want: false},
+		{name: "firefox android", userAgent: "Mozilla/5.0 (Android 14; Mobile; rv:127.0) Gecko/127.0 Firefox/127.0", want: false},
+		{name: "coc coc", userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) coc_coc_browser/123.0.216 Chrome/117.0.5938.216 Safari/537.36", want: false},
+		{name: "zalo in-app", userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Mobile/15E148 Zalo iOS/605 ZaloTheme/light ZaloLanguage/vi", want: false},
+		{name: "facebook in-app", userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Mobile/15E148 [FBAN/FBIOS;FBAV/460.0.0.36.105;FBBV/574806389]", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsBotUserAgent(tt.userAgent); got != tt.want {
+				t.Fatalf("IsBotUserAgent(%q) = %v, want %v", tt.userAgent, got, tt.want)
+			}
+		})
+	}
+}
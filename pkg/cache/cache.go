@@ -0,0 +1,45 @@
+// Package cache cung cấp lớp cache key-value cho các read public nóng (in-memory LRU hoặc Redis).
+package cache
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Cache - Kho key-value có TTL. Lỗi backend được coi như cache miss, không làm hỏng request.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration)
+	Delete(keys ...string)
+	DeletePrefix(prefix string)
+}
+
+// GetJSON - Đọc key và decode JSON vào dest, false nếu miss hoặc dữ liệu hỏng
+func GetJSON(c Cache, key string, dest interface{}) bool {
+	data, ok := c.Get(key)
+	if !ok {
+		return false
+	}
+	if err := json.Unmarshal(data, dest); err != nil {
+		c.Delete(key)
+		return false
+	}
+	return true
+}
+
+// SetJSON - Encode value thành JSON và ghi vào cache
+func SetJSON(c Cache, key string, value interface{}, ttl time.Duration) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	c.Set(key, data, ttl)
+}
+
+// Noop - Cache tắt: luôn miss, dùng khi CACHE_DRIVER=none
+type Noop struct{}
+
+func (Noop) Get(string) ([]byte, bool)         { return nil, false }
+func (Noop) Set(string, []byte, time.Duration) {}
+func (Noop) Delete(...string)                  {}
+func (Noop) DeletePrefix(string)               {}
@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type cachedStory struct {
+	Slug   string   `json:"slug"`
+	Genres []string `json:"genres"`
+}
+
+func TestJSONHelpers(t *testing.T) {
+	tests := []struct {
+		name   string
+		stored []byte // nil = dùng SetJSON với value
+		value  cachedStory
+		wantOK bool
+	}{
+		{name: "round trip", value: cachedStory{Slug: "a", Genres: []string{"fantasy"}}, wantOK: true},
+		{name: "corrupt data is a miss", stored: []byte("{not json"), wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewMemory(10)
+			if tt.stored != nil {
+				c.Set("key", tt.stored, time.Minute)
+			} else {
+				SetJSON(c, "key", tt.value, time.Minute)
+			}
+
+			var got cachedStory
+			ok := GetJSON(c, "key", &got)
+			if ok != tt.wantOK {
+				t.Fatalf("GetJSON() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && !reflect.DeepEqual(got, tt.value) {
+				t.Fatalf("GetJSON() = %+v, want %+v", got, tt.value)
+			}
+			if !ok {
+				// Dữ liệu hỏng bị xóa để lần sau load lại từ DB
+				if _, exists := c.Get("key"); exists {
+					t.Fatal("key hỏng phải bị xóa")
+				}
+			}
+		})
+	}
+
+	var miss cachedStory
+	if GetJSON(NewMemory(10), "missing", &miss) {
+		t.Fatal("GetJSON() key không tồn tại phải miss")
+	}
+	SetJSON(NewMemory(10), "fn", func() {}, time.Minute) // Không encode được thì bỏ qua, không panic
+}
+
+func TestNoop(t *testing.T) {
+	var c Cache = Noop{}
+	c.Set("k", []byte("v"), time.Minute)
+	if _, ok := c.Get("k"); ok {
+		t.Fatal("Noop phải luôn miss")
+	}
+	c.Delete("k")
+	c.DeletePrefix("")
+}
@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// Memory - LRU trong RAM, mỗi entry có TTL riêng. An toàn cho nhiều goroutine.
+type Memory struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List // Front = dùng gần nhất
+	items      map[string]*list.Element
+}
+
+// NewMemory - Tạo LRU giữ tối đa maxEntries key
+func NewMemory(maxEntries int) *Memory {
+	if maxEntries <= 0 {
+		maxEntries = 10000
+	}
+	return &Memory{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+// Get - Lấy value, entry hết hạn bị xóa ngay
+func (m *Memory) Get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	el, ok := m.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*memoryEntry)
+	if time.Now().After(entry.expiresAt) {
+		m.removeElement(el)
+		return nil, false
+	}
+	m.ll.MoveToFront(el)
+	return entry.value, true
+}
+
+// Set - Ghi value, đẩy entry ít dùng nhất ra khi đầy
+func (m *Memory) Set(key string, value []byte, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	expiresAt := time.Now().Add(ttl)
+	if el, ok := m.items[key]; ok {
+		entry := el.Value.(*memoryEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		m.ll.MoveToFront(el)
+		return
+	}
+
+	m.items[key] = m.ll.PushFront(&memoryEntry{key: key, value: value, expiresAt: expiresAt})
+	for m.ll.Len() > m.maxEntries {
+		m.removeElement(m.ll.Back())
+	}
+}
+
+// Delete - Xóa các key
+func (m *Memory) Delete(keys ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, key := range keys {
+		if el, ok := m.items[key]; ok {
+			m.removeElement(el)
+		}
+	}
+}
+
+// DeletePrefix - Xóa mọi key bắt đầu bằng prefix
+func (m *Memory) DeletePrefix(prefix string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, el := range m.items {
+		if strings.HasPrefix(key, prefix) {
+			m.removeElement(el)
+		}
+	}
+}
+
+func (m *Memory) removeElement(el *list.Element) {
+	m.ll.Remove(el)
+	delete(m.items, el.Value.(*memoryEntry).key)
+}
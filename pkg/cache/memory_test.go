@@ -0,0 +1,145 @@
+package cache
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func memoryKeys(m *Memory) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	keys := make([]string, 0, len(m.items))
+	for key := range m.items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestMemoryLRU(t *testing.T) {
+	type op struct {
+		kind string // set, get, delete, prefix
+		key  string
+	}
+
+	tests := []struct {
+		name       string
+		maxEntries int
+		ops        []op
+		want       []string
+	}{
+		{
+			name:       "evicts least recently set",
+			maxEntries: 2,
+			ops:        []op{{"set", "a"}, {"set", "b"}, {"set", "c"}},
+			want:       []string{"b", "c"},
+		},
+		{
+			name:       "get refreshes recency",
+			maxEntries: 2,
+			ops:        []op{{"set", "a"}, {"set", "b"}, {"get", "a"}, {"set", "c"}},
+			want:       []string{"a", "c"},
+		},
+		{
+			name:       "overwrite refreshes recency without growing",
+			maxEntries: 2,
+			ops:        []op{{"set", "a"}, {"set", "b"}, {"set", "a"}, {"set", "c"}},
+			want:       []string{"a", "c"},
+		},
+		{
+			name:       "delete",
+			maxEntries: 10,
+			ops:        []op{{"set", "a"}, {"set", "b"}, {"delete", "a"}, {"delete", "missing"}},
+			want:       []string{"b"},
+		},
+		{
+			name:       "delete prefix",
+			maxEntries: 10,
+			ops:        []op{{"set", "story:a"}, {"set", "story:b"}, {"set", "stories:latest"}, {"set", "chapters:a:all"}, {"prefix", "story:"}},
+			want:       []string{"chapters:a:all", "stories:latest"},
+		},
+		{
+			name:       "default size when maxEntries <= 0",
+			maxEntries: 0,
+			ops:        []op{{"set", "a"}, {"set", "b"}, {"set", "c"}},
+			want:       []string{"a", "b", "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMemory(tt.maxEntries)
+			for _, o := range tt.ops {
+				switch o.kind {
+				case "set":
+					m.Set(o.key, []byte(o.key), time.Minute)
+				case "get":
+					m.Get(o.key)
+				case "delete":
+					m.Delete(o.key)
+				case "prefix":
+					m.DeletePrefix(o.key)
+				}
+			}
+			if got := memoryKeys(m); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("keys = %v, want %v", got, tt.want)
+			}
+			if m.ll.Len() != len(m.items) {
+				t.Fatalf("list (%d) và map (%d) lệch nhau", m.ll.Len(), len(m.items))
+			}
+		})
+	}
+}
+
+func TestMemoryTTL(t *testing.T) {
+	m := NewMemory(10)
+	m.Set("short", []byte("1"), 20*time.Millisecond)
+	m.Set("long", []byte("2"), time.Minute)
+	m.Set("zero", []byte("3"), 0)
+	m.Set("negative", []byte("4"), -time.Second)
+
+	if got, ok := m.Get("short"); !ok || string(got) != "1" {
+		t.Fatalf("Get(short) = %q, %v", got, ok)
+	}
+	for _, key := range []string{"zero", "negative"} {
+		if _, ok := m.Get(key); ok {
+			t.Fatalf("TTL <= 0 không được ghi: %s", key)
+		}
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	if _, ok := m.Get("short"); ok {
+		t.Fatal("short phải hết hạn")
+	}
+	if got := memoryKeys(m); len(got) != 1 || got[0] != "long" {
+		t.Fatalf("entry hết hạn phải bị xóa khi Get, keys = %v", got)
+	}
+}
+
+func TestMemoryConcurrent(t *testing.T) {
+	m := NewMemory(50)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				key := fmt.Sprintf("k:%d", (g*31+i)%100)
+				m.Set(key, []byte(key), time.Minute)
+				m.Get(key)
+				if i%50 == 0 {
+					m.DeletePrefix("k:1")
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if n := len(memoryKeys(m)); n > 50 {
+		t.Fatalf("vượt maxEntries: %d", n)
+	}
+}
@@ -0,0 +1,326 @@
+package cache
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RedisOptions - Cấu hình kết nối Redis (hoặc server tương thích RESP như KeyDB/Valkey/Dragonfly)
+type RedisOptions struct {
+	Addr      string
+	Password  string
+	DB        int
+	Namespace string // Prefix cho mọi key, tránh đụng key của app khác dùng chung Redis
+	PoolSize  int
+	Timeout   time.Duration
+}
+
+// Redis - Client RESP2 tối giản (GET/SET PX/DEL/SCAN), chỉ đủ cho cache
+type Redis struct {
+	opts RedisOptions
+	pool chan *redisConn
+	dial func() (net.Conn, error)
+}
+
+type redisConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	w    *bufio.Writer
+}
+
+// redisError - Lỗi server trả về (-ERR ...), kết nối vẫn dùng lại được
+type redisError string
+
+func (e redisError) Error() string { return string(e) }
+
+func isRedisError(err error) bool {
+	var serverErr redisError
+	return errors.As(err, &serverErr)
+}
+
+// NewRedis - Tạo client và PING thử, trả lỗi nếu không kết nối được
+func NewRedis(opts RedisOptions) (*Redis, error) {
+	if opts.Addr == "" {
+		return nil, errors.New("thiếu địa chỉ redis")
+	}
+	if opts.PoolSize <= 0 {
+		opts.PoolSize = 10
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 2 * time.Second
+	}
+
+	dial := func() (net.Conn, error) {
+		return net.DialTimeout("tcp", opts.Addr, opts.Timeout)
+	}
+	return newRedis(opts, dial)
+}
+
+// newRedis - Như NewRedis nhưng tự chọn cách dial (test dùng net.Pipe)
+func newRedis(opts RedisOptions, dial func() (net.Conn, error)) (*Redis, error) {
+	r := &Redis{opts: opts, pool: make(chan *redisConn, opts.PoolSize), dial: dial}
+	if _, err := r.do("PING"); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Get - Lấy value, lỗi kết nối được log và coi như miss
+func (r *Redis) Get(key string) ([]byte, bool) {
+	reply, err := r.do("GET", r.opts.Namespace+key)
+	if err != nil {
+		log.Printf("[Cache] redis GET %s: %v", key, err)
+		return nil, false
+	}
+	value, ok := reply.(string)
+	if !ok {
+		return nil, false
+	}
+	return []byte(value), true
+}
+
+// Set - Ghi value với TTL (millisecond)
+func (r *Redis) Set(key string, value []byte, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	px := strconv.FormatInt(ttl.Milliseconds(), 10)
+	if _, err := r.do("SET", r.opts.Namespace+key, string(value), "PX", px); err != nil {
+		log.Printf("[Cache] redis SET %s: %v", key, err)
+	}
+}
+
+// Delete - Xóa các key
+func (r *Redis) Delete(keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+	args := make([]string, 0, len(keys)+1)
+	args = append(args, "DEL")
+	for _, key := range keys {
+		args = append(args, r.opts.Namespace+key)
+	}
+	if _, err := r.do(args...); err != nil {
+		log.Printf("[Cache] redis DEL: %v", err)
+	}
+}
+
+// DeletePrefix - SCAN theo pattern prefix* rồi DEL từng lô
+func (r *Redis) DeletePrefix(prefix string) {
+	pattern := escapeGlob(r.opts.Namespace+prefix) + "*"
+	cursor := "0"
+	for {
+		reply, err := r.do("SCAN", cursor, "MATCH", pattern, "COUNT", "500")
+		if err != nil {
+			log.Printf("[Cache] redis SCAN %s: %v", prefix, err)
+			return
+		}
+		parts, ok := reply.([]interface{})
+		if !ok || len(parts) != 2 {
+			log.Printf("[Cache] redis SCAN %s: reply không hợp lệ", prefix)
+			return
+		}
+		cursor, _ = parts[0].(string)
+		keys, _ := parts[1].([]interface{})
+		if len(keys) > 0 {
+			args := make([]string, 0, len(keys)+1)
+			args = append(args, "DEL")
+			for _, key := range keys {
+				if s, ok := key.(string); ok {
+					args = append(args, s)
+				}
+			}
+			if _, err := r.do(args...); err != nil {
+				log.Printf("[Cache] redis DEL %s*: %v", prefix, err)
+				return
+			}
+		}
+		if cursor == "0" || cursor == "" {
+			return
+		}
+	}
+}
+
+// Close - Đóng toàn bộ kết nối đang rảnh trong pool
+func (r *Redis) Close() {
+	r.drainPool()
+}
+
+func (r *Redis) drainPool() {
+	for {
+		select {
+		case c := <-r.pool:
+			c.conn.Close()
+		default:
+			return
+		}
+	}
+}
+
+// do - Gửi 1 lệnh và đọc reply. Kết nối lỗi I/O bị bỏ, không trả lại pool.
+// Kết nối lấy từ pool có thể đã bị server đóng (restart, idle timeout) nên lỗi I/O trên nó
+// được thử lại 1 lần bằng kết nối mới. Mọi lệnh client dùng đều idempotent nên gửi lại an toàn.
+func (r *Redis) do(args ...string) (interface{}, error) {
+	c, pooled, err := r.getConn()
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := r.send(c, args...)
+	if err != nil && pooled && !isRedisError(err) {
+		r.drainPool() // Các kết nối rảnh cùng thời điểm thường hỏng cùng lúc
+		if c, err = r.newConn(); err != nil {
+			return nil, err
+		}
+		reply, err = r.send(c, args...)
+	}
+	return reply, err
+}
+
+// send - roundTrip rồi trả kết nối về pool, trừ khi lỗi I/O (trạng thái stream không còn tin được)
+func (r *Redis) send(c *redisConn, args ...string) (interface{}, error) {
+	reply, err := c.roundTrip(r.opts.Timeout, args...)
+	if err != nil && !isRedisError(err) {
+		c.conn.Close()
+		return nil, err
+	}
+	r.putConn(c)
+	return reply, err
+}
+
+// getConn - Lấy kết nối rảnh trong pool (pooled = true) hoặc dial kết nối mới
+func (r *Redis) getConn() (*redisConn, bool, error) {
+	select {
+	case c := <-r.pool:
+		return c, true, nil
+	default:
+	}
+	c, err := r.newConn()
+	return c, false, err
+}
+
+// newConn - Dial và AUTH/SELECT nếu có cấu hình
+func (r *Redis) newConn() (*redisConn, error) {
+	conn, err := r.dial()
+	if err != nil {
+		return nil, err
+	}
+	c := &redisConn{conn: conn, r: bufio.NewReader(conn), w: bufio.NewWriter(conn)}
+
+	if r.opts.Password != "" {
+		if _, err := c.roundTrip(r.opts.Timeout, "AUTH", r.opts.Password); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	if r.opts.DB != 0 {
+		if _, err := c.roundTrip(r.opts.Timeout, "SELECT", strconv.Itoa(r.opts.DB)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (r *Redis) putConn(c *redisConn) {
+	select {
+	case r.pool <- c:
+	default:
+		c.conn.Close() // Pool đầy
+	}
+}
+
+func (c *redisConn) roundTrip(timeout time.Duration, args ...string) (interface{}, error) {
+	if err := c.conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+
+	// Request luôn là array of bulk strings
+	fmt.Fprintf(c.w, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(c.w, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if err := c.w.Flush(); err != nil {
+		return nil, err
+	}
+	return c.readReply()
+}
+
+func (c *redisConn) readReply() (interface{}, error) {
+	line, err := c.readLine()
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errors.New("redis: reply rỗng")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, nil // Null bulk string = key không tồn tại
+		}
+		buf := make([]byte, size+2) // + CRLF
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			item, err := c.readReply()
+			if err != nil && !isRedisError(err) {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: kiểu reply không hỗ trợ %q", line[0])
+	}
+}
+
+func (c *redisConn) readLine() (string, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"), nil
+}
+
+// escapeGlob - Escape ký tự đặc biệt của pattern MATCH để prefix được so khớp nguyên văn
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,410 @@
+package cache
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// fakeRedis - Server RESP2 trong tiến trình, đủ lệnh client dùng. Kết nối qua net.Pipe.
+type fakeRedis struct {
+	mu       sync.Mutex
+	data     map[string]string
+	password string
+	dials    int
+	conns    []net.Conn
+	commands [][]string
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{data: make(map[string]string)}
+}
+
+func (f *fakeRedis) dial() (net.Conn, error) {
+	client, server := net.Pipe()
+	f.mu.Lock()
+	f.dials++
+	f.conns = append(f.conns, server)
+	f.mu.Unlock()
+	go f.serve(server)
+	return client, nil
+}
+
+// restart - Đóng mọi kết nối phía server như khi Redis restart
+func (f *fakeRedis) restart() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, conn := range f.conns {
+		conn.Close()
+	}
+	f.conns = nil
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	authed := false
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		f.mu.Lock()
+		f.commands = append(f.commands, args)
+		reply := f.exec(args, &authed)
+		f.mu.Unlock()
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+func (f *fakeRedis) exec(args []string, authed *bool) string {
+	cmd := strings.ToUpper(args[0])
+	if f.password != "" && !*authed && cmd != "AUTH" {
+		return "-NOAUTH Authentication required.\r\n"
+	}
+
+	switch cmd {
+	case "PING":
+		return "+PONG\r\n"
+	case "AUTH":
+		if args[1] != f.password {
+			return "-WRONGPASS invalid password\r\n"
+		}
+		*authed = true
+		return "+OK\r\n"
+	case "SELECT":
+		return "+OK\r\n"
+	case "GET":
+		value, ok := f.data[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return bulk(value)
+	case "SET":
+		f.data[args[1]] = args[2]
+		return "+OK\r\n"
+	case "DEL":
+		deleted := 0
+		for _, key := range args[1:] {
+			if _, ok := f.data[key]; ok {
+				delete(f.data, key)
+				deleted++
+			}
+		}
+		return ":" + strconv.Itoa(deleted) + "\r\n"
+	case "SCAN":
+		// Trả 1 key mỗi lần để client phải đi hết cursor. Cursor = "k" + key vừa trả,
+		// nên key bị xóa giữa chừng không làm bỏ sót key khác (giống đảm bảo của SCAN thật)
+		var pattern string
+		for i := 2; i+1 < len(args); i += 2 {
+			if strings.ToUpper(args[i]) == "MATCH" {
+				pattern = args[i+1]
+			}
+		}
+		var keys []string
+		for key := range f.data {
+			if ok, _ := path.Match(pattern, key); ok && (args[1] == "0" || key > args[1][1:]) {
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) == 0 {
+			return "*2\r\n" + bulk("0") + "*0\r\n"
+		}
+		sort.Strings(keys)
+		return "*2\r\n" + bulk("k"+keys[0]) + "*1\r\n" + bulk(keys[0])
+	default:
+		return "-ERR unknown command '" + args[0] + "'\r\n"
+	}
+}
+
+func bulk(s string) string {
+	return "$" + strconv.Itoa(len(s)) + "\r\n" + s + "\r\n"
+}
+
+// readCommand - Đọc 1 request dạng array of bulk strings
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return nil, fmt.Errorf("request không phải array: %q", line)
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, count)
+	for i := range args {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func newTestRedis(t *testing.T, f *fakeRedis, opts RedisOptions) *Redis {
+	t.Helper()
+	opts.PoolSize = 2
+	opts.Timeout = time.Second
+	r, err := newRedis(opts, f.dial)
+	if err != nil {
+		t.Fatalf("newRedis: %v", err)
+	}
+	t.Cleanup(r.Close)
+	return r
+}
+
+func TestRedisReadReply(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    interface{}
+		wantErr string
+	}{
+		{name: "simple string", raw: "+OK\r\n", want: "OK"},
+		{name: "integer", raw: ":42\r\n", want: int64(42)},
+		{name: "bulk string", raw: "$5\r\nhello\r\n", want: "hello"},
+		{name: "empty bulk string", raw: "$0\r\n\r\n", want: ""},
+		{name: "bulk string with CRLF inside", raw: "$4\r\na\r\nb\r\n", want: "a\r\nb"},
+		{name: "null bulk string", raw: "$-1\r\n", want: nil},
+		{name: "null array", raw: "*-1\r\n", want: nil},
+		{name: "nested array", raw: "*2\r\n$1\r\n0\r\n*2\r\n$1\r\na\r\n:1\r\n", want: []interface{}{"0", []interface{}{"a", int64(1)}}},
+		{name: "server error", raw: "-ERR boom\r\n", wantErr: "ERR boom"},
+		{name: "truncated bulk string", raw: "$5\r\nhel", wantErr: io.ErrUnexpectedEOF.Error()},
+		{name: "truncated line", raw: "+OK", wantErr: io.EOF.Error()},
+		{name: "unknown type", raw: "?x\r\n", wantErr: "kiểu reply không hỗ trợ"},
+		{name: "empty reply", raw: "\r\n", wantErr: "reply rỗng"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// OneByteReader buộc mọi lần đọc đều là đọc dở dang
+			c := &redisConn{r: bufio.NewReader(iotest.OneByteReader(strings.NewReader(tt.raw)))}
+			got, err := c.readReply()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("readReply() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readReply() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("readReply() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedisRoundTripEncoding(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "single", args: []string{"PING"}, want: "*1\r\n$4\r\nPING\r\n"},
+		{name: "binary value", args: []string{"SET", "k", "a\r\nb"}, want: "*3\r\n$3\r\nSET\r\n$1\r\nk\r\n$4\r\na\r\nb\r\n"},
+		{name: "unicode", args: []string{"GET", "truyện"}, want: "*2\r\n$3\r\nGET\r\n$8\r\ntruyện\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			defer client.Close()
+			defer server.Close()
+
+			received := make(chan string, 1)
+			go func() {
+				buf := make([]byte, len(tt.want))
+				n, _ := io.ReadFull(server, buf)
+				received <- string(buf[:n])
+				io.WriteString(server, "+OK\r\n")
+			}()
+
+			c := &redisConn{conn: client, r: bufio.NewReader(client), w: bufio.NewWriter(client)}
+			reply, err := c.roundTrip(time.Second, tt.args...)
+			if err != nil {
+				t.Fatalf("roundTrip() error = %v", err)
+			}
+			if reply != "OK" {
+				t.Fatalf("roundTrip() = %#v, want OK", reply)
+			}
+			if got := <-received; got != tt.want {
+				t.Fatalf("request = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedisCommands(t *testing.T) {
+	f := newFakeRedis()
+	r := newTestRedis(t, f, RedisOptions{Namespace: "app:"})
+
+	r.Set("story:a", []byte("1"), time.Minute)
+	r.Set("story:b", []byte("2"), time.Minute)
+	r.Set("story*:c", []byte("3"), time.Minute) // Ký tự glob trong prefix phải được escape
+	r.Set("genres:all", []byte("4"), time.Minute)
+	r.Set("no-ttl", []byte("5"), 0) // TTL <= 0 không ghi
+
+	if got, ok := r.Get("story:a"); !ok || string(got) != "1" {
+		t.Fatalf("Get(story:a) = %q, %v", got, ok)
+	}
+	if _, ok := r.Get("no-ttl"); ok {
+		t.Fatal("Set với TTL 0 không được ghi")
+	}
+	if _, ok := f.data["app:story:a"]; !ok {
+		t.Fatal("key phải có namespace")
+	}
+
+	r.DeletePrefix("story:")
+	for key, want := range map[string]bool{"story:a": false, "story:b": false, "story*:c": true, "genres:all": true} {
+		if _, ok := r.Get(key); ok != want {
+			t.Errorf("sau DeletePrefix, Get(%s) ok = %v, want %v", key, ok, want)
+		}
+	}
+
+	r.Delete("genres:all", "story*:c")
+	if len(f.data) != 0 {
+		t.Fatalf("sau Delete còn %v", f.data)
+	}
+}
+
+func TestRedisAuthAndSelect(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		opts     RedisOptions
+		wantErr  bool
+		wantCmds []string
+	}{
+		{name: "no auth", wantCmds: []string{"PING"}},
+		{name: "auth and select", password: "secret", opts: RedisOptions{Password: "secret", DB: 2}, wantCmds: []string{"AUTH", "SELECT", "PING"}},
+		{name: "wrong password", password: "secret", opts: RedisOptions{Password: "nope"}, wantErr: true},
+		{name: "missing password", password: "secret", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeRedis()
+			f.password = tt.password
+			tt.opts.Timeout = time.Second
+			tt.opts.PoolSize = 1
+			r, err := newRedis(tt.opts, f.dial)
+			if tt.wantErr {
+				if err == nil {
+					r.Close()
+					t.Fatal("newRedis() phải lỗi")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newRedis() error = %v", err)
+			}
+			defer r.Close()
+
+			var cmds []string
+			for _, args := range f.commands {
+				cmds = append(cmds, args[0])
+			}
+			if !reflect.DeepEqual(cmds, tt.wantCmds) {
+				t.Fatalf("commands = %v, want %v", cmds, tt.wantCmds)
+			}
+		})
+	}
+}
+
+func TestRedisReconnect(t *testing.T) {
+	f := newFakeRedis()
+	r := newTestRedis(t, f, RedisOptions{})
+	r.Set("k", []byte("v"), time.Minute)
+
+	// Kết nối trong pool đã chết: lệnh kế tiếp phải tự dial lại thay vì trả miss
+	f.restart()
+	if got, ok := r.Get("k"); !ok || string(got) != "v" {
+		t.Fatalf("Get sau restart = %q, %v", got, ok)
+	}
+	if f.dials != 2 {
+		t.Fatalf("dials = %d, want 2", f.dials)
+	}
+
+	// Kết nối mới vừa dial mà lỗi thì không thử lại vô hạn
+	dialErr := errors.New("connection refused")
+	f.restart()
+	r.dial = func() (net.Conn, error) { return nil, dialErr }
+	if _, ok := r.Get("k"); ok {
+		t.Fatal("Get khi server chết phải là miss")
+	}
+	if _, err := r.do("PING"); !errors.Is(err, dialErr) {
+		t.Fatalf("do() error = %v, want %v", err, dialErr)
+	}
+}
+
+func TestRedisServerErrorKeepsConnection(t *testing.T) {
+	f := newFakeRedis()
+	r := newTestRedis(t, f, RedisOptions{})
+
+	if _, err := r.do("BOOM"); !isRedisError(err) {
+		t.Fatalf("do(BOOM) error = %v, want server error", err)
+	}
+	if _, err := r.do("PING"); err != nil {
+		t.Fatalf("do(PING) error = %v", err)
+	}
+	if f.dials != 1 {
+		t.Fatalf("dials = %d, want 1 (lỗi server không được bỏ kết nối)", f.dials)
+	}
+}
+
+// TestRedisServer - Chạy với Redis thật khi có REDIS_TEST_ADDR (vd: REDIS_TEST_ADDR=localhost:6379 go test ./pkg/cache)
+func TestRedisServer(t *testing.T) {
+	addr := os.Getenv("REDIS_TEST_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_TEST_ADDR không được set")
+	}
+
+	r, err := NewRedis(RedisOptions{Addr: addr, Namespace: fmt.Sprintf("nekozanedex-test:%d:", time.Now().UnixNano())})
+	if err != nil {
+		t.Fatalf("NewRedis: %v", err)
+	}
+	defer r.Close()
+	defer r.DeletePrefix("")
+
+	r.Set("a:1", []byte("x"), time.Minute)
+	r.Set("a:2", []byte("y"), 50*time.Millisecond)
+	if got, ok := r.Get("a:1"); !ok || string(got) != "x" {
+		t.Fatalf("Get(a:1) = %q, %v", got, ok)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if _, ok := r.Get("a:2"); ok {
+		t.Fatal("a:2 phải hết hạn")
+	}
+	r.DeletePrefix("a:")
+	if _, ok := r.Get("a:1"); ok {
+		t.Fatal("a:1 phải bị xóa")
+	}
+}
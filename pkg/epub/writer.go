@@ -0,0 +1,330 @@
+// Package epub ghi file EPUB 3 theo kiểu streaming: chapter và ảnh được ghi thẳng
+// vào zip khi thêm vào, package document (OPF), nav và NCX được ghi lúc Close.
+package epub
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"html"
+	"io"
+	"strings"
+	"time"
+)
+
+// Metadata - Thông tin sách ghi vào content.opf
+type Metadata struct {
+	Identifier  string // VD: "urn:uuid:<story id>"
+	Title       string
+	Language    string // BCP 47, VD: "vi"
+	Description string
+	Authors     []string
+	Translators []string
+	Subjects    []string // Thể loại
+	Modified    time.Time
+}
+
+// Writer - Ghi 1 file EPUB ra io.Writer
+type Writer struct {
+	zw         *zip.Writer
+	meta       Metadata
+	items      []manifestItem
+	spine      []string
+	toc        []tocEntry
+	hasCover   bool
+	imageSeq   int
+	chapterSeq int
+	closed     bool
+}
+
+type manifestItem struct {
+	ID         string
+	Href       string
+	MediaType  string
+	Properties string
+}
+
+type tocEntry struct {
+	Title string
+	Href  string
+}
+
+const contentDir = "OEBPS/"
+
+// NewWriter - Khởi tạo EPUB, ghi ngay mimetype và container.xml
+func NewWriter(w io.Writer, meta Metadata) (*Writer, error) {
+	if meta.Identifier == "" || meta.Title == "" {
+		return nil, errors.New("epub: thiếu identifier hoặc title")
+	}
+	if meta.Language == "" {
+		meta.Language = "vi"
+	}
+	if meta.Modified.IsZero() {
+		meta.Modified = time.Now()
+	}
+
+	ew := &Writer{zw: zip.NewWriter(w), meta: meta}
+
+	// mimetype phải là entry đầu tiên, không nén và không có data descriptor
+	mimetype := []byte("application/epub+zip")
+	mw, err := ew.zw.CreateRaw(&zip.FileHeader{
+		Name:               "mimetype",
+		Method:             zip.Store,
+		CRC32:              crc32.ChecksumIEEE(mimetype),
+		CompressedSize64:   uint64(len(mimetype)),
+		UncompressedSize64: uint64(len(mimetype)),
+		Modified:           meta.Modified,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := mw.Write(mimetype); err != nil {
+		return nil, err
+	}
+
+	if err := ew.writeFile("META-INF/container.xml", containerXML); err != nil {
+		return nil, err
+	}
+	return ew, nil
+}
+
+// SetCover - Thêm ảnh bìa và trang bìa (gọi trước khi thêm chapter để bìa nằm đầu spine)
+func (w *Writer) SetCover(data []byte, mediaType string) error {
+	if w.hasCover {
+		return errors.New("epub: đã có ảnh bìa")
+	}
+	href := "images/cover" + extensionFor(mediaType)
+	if err := w.writeBytes(contentDir+href, data); err != nil {
+		return err
+	}
+	w.items = append(w.items, manifestItem{ID: "cover-image", Href: href, MediaType: mediaType, Properties: "cover-image"})
+
+	page := xhtmlPage(w.meta.Language, "Cover",
+		fmt.Sprintf(`<div class="cover"><img src="%s" alt="%s"/></div>`, href, escape(w.meta.Title)))
+	if err := w.writeFile(contentDir+"cover.xhtml", page); err != nil {
+		return err
+	}
+	w.items = append(w.items, manifestItem{ID: "cover", Href: "cover.xhtml", MediaType: "application/xhtml+xml"})
+	w.spine = append(w.spine, "cover")
+	w.hasCover = true
+	return nil
+}
+
+// AddImage - Thêm ảnh vào sách, trả về href dùng trong body của chapter
+func (w *Writer) AddImage(data []byte, mediaType string) (string, error) {
+	w.imageSeq++
+	id := fmt.Sprintf("img-%05d", w.imageSeq)
+	href := "images/" + id + extensionFor(mediaType)
+	if err := w.writeBytes(contentDir+href, data); err != nil {
+		return "", err
+	}
+	w.items = append(w.items, manifestItem{ID: id, Href: href, MediaType: mediaType})
+	return href, nil
+}
+
+// AddChapter - Thêm 1 chapter. body là XHTML hợp lệ (đã escape), title hiển thị trong mục lục
+func (w *Writer) AddChapter(title, body string) error {
+	w.chapterSeq++
+	id := fmt.Sprintf("chapter-%04d", w.chapterSeq)
+	href := "text/" + id + ".xhtml"
+
+	// Ảnh nằm ở OEBPS/images, chapter ở OEBPS/text nên đổi đường dẫn tương đối
+	body = strings.ReplaceAll(body, `src="images/`, `src="../images/`)
+	page := xhtmlPage(w.meta.Language, title, "<h1>"+escape(title)+"</h1>\n"+body)
+	if err := w.writeFile(contentDir+href, page); err != nil {
+		return err
+	}
+
+	w.items = append(w.items, manifestItem{ID: id, Href: href, MediaType: "application/xhtml+xml"})
+	w.spine = append(w.spine, id)
+	w.toc = append(w.toc, tocEntry{Title: title, Href: href})
+	return nil
+}
+
+// Close - Ghi nav, NCX, OPF và đóng zip
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	if len(w.toc) == 0 {
+		return errors.New("epub: sách không có chapter nào")
+	}
+
+	if err := w.writeFile(contentDir+"nav.xhtml", w.navXHTML()); err != nil {
+		return err
+	}
+	if err := w.writeFile(contentDir+"toc.ncx", w.tocNCX()); err != nil {
+		return err
+	}
+	if err := w.writeFile(contentDir+"content.opf", w.packageOPF()); err != nil {
+		return err
+	}
+	return w.zw.Close()
+}
+
+func (w *Writer) writeFile(name, content string) error {
+	return w.writeBytes(name, []byte(content))
+}
+
+func (w *Writer) writeBytes(name string, data []byte) error {
+	fw, err := w.zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: w.meta.Modified,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = fw.Write(data)
+	return err
+}
+
+func (w *Writer) navXHTML() string {
+	var b strings.Builder
+	b.WriteString(`<nav epub:type="toc" id="toc"><h1>Mục lục</h1><ol>`)
+	for _, entry := range w.toc {
+		fmt.Fprintf(&b, "\n<li><a href=\"%s\">%s</a></li>", entry.Href, escape(entry.Title))
+	}
+	b.WriteString("\n</ol></nav>")
+	return xhtmlPage(w.meta.Language, "Mục lục", b.String())
+}
+
+// tocNCX - Mục lục dạng EPUB 2 cho các máy đọc cũ
+func (w *Writer) tocNCX() string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">
+<head><meta name="dtb:uid" content="` + escape(w.meta.Identifier) + `"/></head>
+<docTitle><text>` + escape(w.meta.Title) + `</text></docTitle>
+<navMap>`)
+	for i, entry := range w.toc {
+		fmt.Fprintf(&b, "\n<navPoint id=\"nav-%d\" playOrder=\"%d\"><navLabel><text>%s</text></navLabel><content src=\"%s\"/></navPoint>",
+			i+1, i+1, escape(entry.Title), entry.Href)
+	}
+	b.WriteString("\n</navMap>\n</ncx>\n")
+	return b.String()
+}
+
+func (w *Writer) packageOPF() string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id" xml:lang="` + escape(w.meta.Language) + `">
+<metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+<dc:identifier id="book-id">` + escape(w.meta.Identifier) + `</dc:identifier>
+<dc:title>` + escape(w.meta.Title) + `</dc:title>
+<dc:language>` + escape(w.meta.Language) + `</dc:language>
+<meta property="dcterms:modified">` + w.meta.Modified.UTC().Format("2006-01-02T15:04:05Z") + `</meta>`)
+
+	for i, author := range w.meta.Authors {
+		fmt.Fprintf(&b, "\n<dc:creator id=\"author-%d\">%s</dc:creator>", i+1, escape(author))
+		fmt.Fprintf(&b, "\n<meta refines=\"#author-%d\" property=\"role\" scheme=\"marc:relators\">aut</meta>", i+1)
+	}
+	for i, translator := range w.meta.Translators {
+		fmt.Fprintf(&b, "\n<dc:contributor id=\"translator-%d\">%s</dc:contributor>", i+1, escape(translator))
+		fmt.Fprintf(&b, "\n<meta refines=\"#translator-%d\" property=\"role\" scheme=\"marc:relators\">trl</meta>", i+1)
+	}
+	for _, subject := range w.meta.Subjects {
+		fmt.Fprintf(&b, "\n<dc:subject>%s</dc:subject>", escape(subject))
+	}
+	if w.meta.Description != "" {
+		fmt.Fprintf(&b, "\n<dc:description>%s</dc:description>", escape(w.meta.Description))
+	}
+	if w.hasCover {
+		b.WriteString("\n<meta name=\"cover\" content=\"cover-image\"/>")
+	}
+	b.WriteString("\n</metadata>\n<manifest>")
+
+	b.WriteString("\n<item id=\"nav\" href=\"nav.xhtml\" media-type=\"application/xhtml+xml\" properties=\"nav\"/>")
+	b.WriteString("\n<item id=\"ncx\" href=\"toc.ncx\" media-type=\"application/x-dtbncx+xml\"/>")
+	for _, item := range w.items {
+		fmt.Fprintf(&b, "\n<item id=\"%s\" href=\"%s\" media-type=\"%s\"", item.ID, item.Href, item.MediaType)
+		if item.Properties != "" {
+			fmt.Fprintf(&b, " properties=\"%s\"", item.Properties)
+		}
+		b.WriteString("/>")
+	}
+
+	b.WriteString("\n</manifest>\n<spine toc=\"ncx\">")
+	for _, id := range w.spine {
+		fmt.Fprintf(&b, "\n<itemref idref=\"%s\"/>", id)
+	}
+	b.WriteString("\n</spine>\n</package>\n")
+	return b.String()
+}
+
+// TextToXHTML - Chuyển nội dung text (mỗi dòng 1 đoạn) thành các thẻ <p>
+func TextToXHTML(text string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		b.WriteString("<p>")
+		b.WriteString(escape(line))
+		b.WriteString("</p>\n")
+	}
+	return b.String()
+}
+
+// ImagePage - Body XHTML cho 1 trang ảnh (manga)
+func ImagePage(href, alt string) string {
+	return fmt.Sprintf("<div class=\"page\"><img src=\"%s\" alt=\"%s\"/></div>\n", href, escape(alt))
+}
+
+func xhtmlPage(lang, title, body string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="` + escape(lang) + `" lang="` + escape(lang) + `">
+<head>
+<meta charset="UTF-8"/>
+<title>` + escape(title) + `</title>
+<style>img{max-width:100%;height:auto;display:block;margin:0 auto;} .page{page-break-after:always;}</style>
+</head>
+<body>
+` + body + `
+</body>
+</html>
+`
+}
+
+// escape - Escape cho XML (html.EscapeString đủ cho text và attribute),
+// bỏ các ký tự điều khiển không hợp lệ trong XML 1.0
+func escape(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 0x20 && r != '\t' && r != '\n' && r != '\r' {
+			return -1
+		}
+		if r == 0xFFFE || r == 0xFFFF {
+			return -1
+		}
+		return r
+	}, s)
+	return html.EscapeString(s)
+}
+
+func extensionFor(mediaType string) string {
+	switch mediaType {
+	case "image/png":
+		return ".png"
+	case "image/gif":
+		return ".gif"
+	case "image/webp":
+		return ".webp"
+	case "image/svg+xml":
+		return ".svg"
+	default:
+		return ".jpg"
+	}
+}
+
+const containerXML = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+<rootfiles>
+<rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+</rootfiles>
+</container>
+`
@@ -0,0 +1,44 @@
+// Package httpcache - Helper cho HTTP conditional GET (ETag, Last-Modified, 304).
+package httpcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ETag - Strong ETag từ hash nội dung response
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// SetLastModified - Gán Last-Modified (bỏ qua thời điểm zero). Handler gọi trước khi render
+func SetLastModified(header http.Header, t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	header.Set("Last-Modified", t.UTC().Format(http.TimeFormat))
+}
+
+// NotModified - Kiểm tra If-None-Match (ưu tiên) rồi If-Modified-Since theo RFC 9110
+func NotModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == etag || candidate == "*" {
+				return true
+			}
+		}
+		return false
+	}
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		// Header chỉ chính xác tới giây
+		if since, err := http.ParseTime(ims); err == nil && !lastModified.Truncate(time.Second).After(since) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package httpcache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestETag(t *testing.T) {
+	strong := regexp.MustCompile(`^"[0-9a-f]{32}"$`)
+
+	a := ETag([]byte(`{"id":1}`))
+	if !strong.MatchString(a) {
+		t.Fatalf("ETag() = %s, want strong ETag 32 hex", a)
+	}
+	if b := ETag([]byte(`{"id":1}`)); a != b {
+		t.Fatalf("ETag không ổn định: %s != %s", a, b)
+	}
+	if c := ETag([]byte(`{"id":2}`)); a == c {
+		t.Fatal("nội dung khác phải cho ETag khác")
+	}
+	if empty := ETag(nil); !strong.MatchString(empty) {
+		t.Fatalf("ETag(nil) = %s", empty)
+	}
+}
+
+func TestSetLastModified(t *testing.T) {
+	tests := []struct {
+		name string
+		t    time.Time
+		want string
+	}{
+		{name: "zero", t: time.Time{}, want: ""},
+		{name: "utc", t: time.Date(2026, 1, 2, 3, 4, 5, 600, time.UTC), want: "Fri, 02 Jan 2026 03:04:05 GMT"},
+		{name: "local zone", t: time.Date(2026, 1, 2, 10, 4, 5, 0, time.FixedZone("ICT", 7*3600)), want: "Fri, 02 Jan 2026 03:04:05 GMT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			SetLastModified(header, tt.t)
+			if got := header.Get("Last-Modified"); got != tt.want {
+				t.Fatalf("Last-Modified = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	const etag = `"abc"`
+	modified := time.Date(2026, 1, 2, 3, 4, 5, 500_000_000, time.UTC)
+
+	tests := []struct {
+		name         string
+		headers      map[string]string
+		lastModified time.Time
+		want         bool
+	}{
+		{name: "no validators", want: false},
+		{name: "etag match", headers: map[string]string{"If-None-Match": `"abc"`}, want: true},
+		{name: "etag mismatch", headers: map[string]string{"If-None-Match": `"xyz"`}, want: false},
+		{name: "etag in list", headers: map[string]string{"If-None-Match": `"xyz", "abc"`}, want: true},
+		{name: "weak etag compares weakly", headers: map[string]string{"If-None-Match": `W/"abc"`}, want: true},
+		{name: "wildcard", headers: map[string]string{"If-None-Match": `*`}, want: true},
+		{
+			name:         "if-none-match wins over if-modified-since",
+			headers:      map[string]string{"If-None-Match": `"xyz"`, "If-Modified-Since": "Sat, 03 Jan 2026 00:00:00 GMT"},
+			lastModified: modified,
+			want:         false,
+		},
+		{
+			name:         "not modified since (sub-second ignored)",
+			headers:      map[string]string{"If-Modified-Since": "Fri, 02 Jan 2026 03:04:05 GMT"},
+			lastModified: modified,
+			want:         true,
+		},
+		{
+			name:         "modified after",
+			headers:      map[string]string{"If-Modified-Since": "Fri, 02 Jan 2026 03:04:04 GMT"},
+			lastModified: modified,
+			want:         false,
+		},
+		{
+			name:    "if-modified-since without last-modified",
+			headers: map[string]string{"If-Modified-Since": "Fri, 02 Jan 2026 03:04:05 GMT"},
+			want:    false,
+		},
+		{
+			name:         "invalid date",
+			headers:      map[string]string{"If-Modified-Since": "yesterday"},
+			lastModified: modified,
+			want:         false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			if got := NotModified(req, etag, tt.lastModified); got != tt.want {
+				t.Fatalf("NotModified() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
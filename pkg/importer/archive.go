@@ -0,0 +1,184 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// parseZIP - ZIP chứa các file .txt/.md: mỗi file 1 chapter theo thứ tự tên file (so sánh số tự nhiên)
+// ZIP chỉ có 1 file thì tách file đó theo regex
+func parseZIP(data []byte, pattern *regexp.Regexp) ([]ParsedChapter, error) {
+	archive, err := openZip(data)
+	if err != nil {
+		return nil, errors.New("file ZIP không hợp lệ")
+	}
+
+	type textFile struct {
+		name    string
+		content string
+	}
+	var files []textFile
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() || strings.HasPrefix(path.Base(file.Name), ".") || strings.HasPrefix(file.Name, "__MACOSX/") {
+			continue
+		}
+		if format, err := DetectFormat(file.Name); err != nil || format != FormatText {
+			continue
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(io.LimitReader(rc, maxUncompressedBytes))
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, textFile{name: file.Name, content: decodeText(content)})
+	}
+
+	if len(files) == 0 {
+		return nil, errors.New("ZIP không chứa file .txt hoặc .md nào")
+	}
+	if len(files) == 1 {
+		return SplitText(files[0].content, pattern), nil
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return naturalLess(files[i].name, files[j].name)
+	})
+
+	chapters := make([]ParsedChapter, 0, len(files))
+	for _, file := range files {
+		heading, content := splitFirstLine(normalizeNewlines(file.content))
+		number, title := ParseHeading(heading, pattern)
+		if number == 0 {
+			// Không có tiêu đề dạng "Chương N" thì lấy số trong tên file
+			number = firstNumber(path.Base(file.name))
+		}
+		chapters = append(chapters, ParsedChapter{Number: number, Title: title, Content: content})
+	}
+	return chapters, nil
+}
+
+// naturalLess - So sánh tên file theo số tự nhiên ("chap2.txt" < "chap10.txt")
+func naturalLess(a, b string) bool {
+	for a != "" && b != "" {
+		ra, rb := rune(a[0]), rune(b[0])
+		if unicode.IsDigit(ra) && unicode.IsDigit(rb) {
+			na, restA := leadingDigits(a)
+			nb, restB := leadingDigits(b)
+			if na != nb {
+				return na < nb
+			}
+			a, b = restA, restB
+			continue
+		}
+		if ra != rb {
+			return strings.ToLower(a[:1]) < strings.ToLower(b[:1])
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) < len(b)
+}
+
+func leadingDigits(s string) (int, string) {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	n, _ := strconv.Atoi(s[:i])
+	return n, s[i:]
+}
+
+func firstNumber(s string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= '0' && s[i] <= '9' {
+			n, _ := leadingDigits(s[i:])
+			return n
+		}
+	}
+	return 0
+}
+
+// ArchivePage - 1 trang ảnh đọc từ CBZ/ZIP
+type ArchivePage struct {
+	Name string
+	Data []byte
+}
+
+// imageExtensions - Phần mở rộng được coi là trang ảnh trong CBZ
+var imageExtensions = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+	".webp": true,
+	".gif":  true,
+}
+
+// ReadImageArchive - Đọc các trang ảnh trong CBZ/ZIP, sắp xếp theo số tự nhiên (1, 2, 10)
+// Bỏ qua thư mục, file ẩn, __MACOSX và file không phải ảnh (ComicInfo.xml...)
+// Đếm số trang và tổng số byte thật sự giải nén ngay khi đọc (header zip có thể khai sai kích thước)
+func ReadImageArchive(data []byte, maxPageBytes int64, maxPages int) ([]ArchivePage, error) {
+	archive, err := openZip(data)
+	if err != nil {
+		return nil, errors.New("file CBZ/ZIP không hợp lệ")
+	}
+
+	var pages []ArchivePage
+	var total int64
+	for _, file := range archive.File {
+		base := path.Base(file.Name)
+		if file.FileInfo().IsDir() || strings.HasPrefix(base, ".") || strings.HasPrefix(file.Name, "__MACOSX/") {
+			continue
+		}
+		if !imageExtensions[strings.ToLower(path.Ext(base))] {
+			continue
+		}
+		if len(pages) >= maxPages {
+			return nil, fmt.Errorf("quá nhiều trang (tối đa %d)", maxPages)
+		}
+		if int64(file.UncompressedSize64) > maxPageBytes {
+			return nil, errors.New("trang " + base + " quá lớn")
+		}
+
+		limit := maxPageBytes
+		if remaining := maxUncompressedBytes - total; remaining < limit {
+			limit = remaining
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(io.LimitReader(rc, limit+1))
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(content)) > maxPageBytes {
+			return nil, errors.New("trang " + base + " quá lớn")
+		}
+		total += int64(len(content))
+		if total > maxUncompressedBytes {
+			return nil, errors.New("archive quá lớn sau khi giải nén")
+		}
+		pages = append(pages, ArchivePage{Name: file.Name, Data: content})
+	}
+
+	if len(pages) == 0 {
+		return nil, errors.New("không tìm thấy ảnh nào trong file")
+	}
+
+	sort.SliceStable(pages, func(i, j int) bool {
+		return naturalLess(pages[i].Name, pages[j].Name)
+	})
+	return pages, nil
+}
@@ -0,0 +1,174 @@
+package importer
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type docxParagraph struct {
+	Text         string
+	HeadingLevel int // 0 = đoạn thường
+}
+
+// parseDOCX - Tách theo heading (Heading 1, nếu không đủ thì Heading 2), không có heading thì theo regex
+func parseDOCX(data []byte, pattern *regexp.Regexp) ([]ParsedChapter, error) {
+	archive, err := openZip(data)
+	if err != nil {
+		return nil, errors.New("file DOCX không hợp lệ")
+	}
+	file := findZipFile(archive, "word/document.xml")
+	if file == nil {
+		return nil, errors.New("DOCX thiếu word/document.xml")
+	}
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	paragraphs, err := readDocxParagraphs(rc)
+	if err != nil {
+		return nil, errors.New("không đọc được nội dung DOCX")
+	}
+
+	// Chọn cấp heading dùng để tách: cấp cao nhất có ít nhất 2 heading
+	counts := map[int]int{}
+	for _, p := range paragraphs {
+		if p.HeadingLevel > 0 {
+			counts[p.HeadingLevel]++
+		}
+	}
+	splitLevel := 0
+	for level := 1; level <= 2; level++ {
+		if counts[level] >= 2 {
+			splitLevel = level
+			break
+		}
+	}
+
+	if splitLevel == 0 {
+		var text strings.Builder
+		for _, p := range paragraphs {
+			text.WriteString(p.Text)
+			text.WriteString("\n\n")
+		}
+		return SplitText(text.String(), pattern), nil
+	}
+
+	var (
+		chapters []ParsedChapter
+		current  *ParsedChapter
+		body     strings.Builder
+	)
+	finish := func() {
+		if current != nil {
+			current.Content = strings.TrimSpace(body.String())
+			chapters = append(chapters, *current)
+		} else if prologue := strings.TrimSpace(body.String()); prologue != "" {
+			chapters = append(chapters, ParsedChapter{Title: "Mở đầu", Content: prologue})
+		}
+		body.Reset()
+	}
+
+	for _, p := range paragraphs {
+		if p.HeadingLevel == splitLevel {
+			finish()
+			number, title := ParseHeading(p.Text, pattern)
+			current = &ParsedChapter{Number: number, Title: title}
+			continue
+		}
+		if strings.TrimSpace(p.Text) != "" {
+			body.WriteString(p.Text)
+			body.WriteString("\n\n")
+		}
+	}
+	finish()
+
+	return chapters, nil
+}
+
+var headingStylePattern = regexp.MustCompile(`(?i)^(?:heading|title|u?berschrift)\s*(\d)?$`)
+
+// readDocxParagraphs - Đọc các đoạn <w:p> và cấp heading của chúng
+func readDocxParagraphs(r io.Reader) ([]docxParagraph, error) {
+	decoder := xml.NewDecoder(r)
+
+	var (
+		paragraphs []docxParagraph
+		current    strings.Builder
+		level      int
+		inText     bool
+	)
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				current.Reset()
+				level = 0
+			case "pStyle":
+				level = docxHeadingLevel(attrValue(t, "val"))
+			case "outlineLvl":
+				if n, err := strconv.Atoi(attrValue(t, "val")); err == nil && level == 0 && n < 9 {
+					level = n + 1
+				}
+			case "t":
+				inText = true
+			case "tab":
+				current.WriteString("\t")
+			case "br", "cr":
+				current.WriteString("\n")
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				paragraphs = append(paragraphs, docxParagraph{
+					Text:         strings.TrimSpace(current.String()),
+					HeadingLevel: level,
+				})
+			}
+		case xml.CharData:
+			if inText {
+				current.Write(t)
+			}
+		}
+	}
+	return paragraphs, nil
+}
+
+// docxHeadingLevel - "Heading1", "heading 2", "Title" -> cấp heading (Title = 1)
+func docxHeadingLevel(style string) int {
+	match := headingStylePattern.FindStringSubmatch(strings.TrimSpace(style))
+	if match == nil {
+		return 0
+	}
+	if match[1] == "" {
+		return 1
+	}
+	level, _ := strconv.Atoi(match[1])
+	return level
+}
+
+func attrValue(element xml.StartElement, name string) string {
+	for _, attr := range element.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
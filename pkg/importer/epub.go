@@ -0,0 +1,142 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+type epubContainer struct {
+	Rootfiles []struct {
+		FullPath string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+type epubPackage struct {
+	Manifest []struct {
+		ID         string `xml:"id,attr"`
+		Href       string `xml:"href,attr"`
+		MediaType  string `xml:"media-type,attr"`
+		Properties string `xml:"properties,attr"`
+	} `xml:"manifest>item"`
+	Spine []struct {
+		IDRef  string `xml:"idref,attr"`
+		Linear string `xml:"linear,attr"`
+	} `xml:"spine>itemref"`
+}
+
+// parseEPUB - Mỗi tài liệu trong spine là 1 chapter (bỏ nav, trang bìa và trang rỗng)
+// Nếu 1 tài liệu chứa nhiều tiêu đề khớp regex thì tách tiếp theo regex
+func parseEPUB(data []byte, pattern *regexp.Regexp) ([]ParsedChapter, error) {
+	archive, err := openZip(data)
+	if err != nil {
+		return nil, errors.New("file EPUB không hợp lệ")
+	}
+
+	var container epubContainer
+	if err := readZipXML(archive, "META-INF/container.xml", &container); err != nil || len(container.Rootfiles) == 0 {
+		return nil, errors.New("EPUB thiếu META-INF/container.xml")
+	}
+	opfPath := container.Rootfiles[0].FullPath
+
+	var pkg epubPackage
+	if err := readZipXML(archive, opfPath, &pkg); err != nil {
+		return nil, errors.New("không đọc được package document của EPUB")
+	}
+
+	baseDir := path.Dir(opfPath)
+	hrefs := make(map[string]string)
+	for _, item := range pkg.Manifest {
+		if strings.Contains(item.Properties, "nav") || item.MediaType != "application/xhtml+xml" {
+			continue
+		}
+		href, err := url.PathUnescape(item.Href)
+		if err != nil {
+			href = item.Href
+		}
+		hrefs[item.ID] = path.Join(baseDir, href)
+	}
+
+	var chapters []ParsedChapter
+	for _, ref := range pkg.Spine {
+		if ref.Linear == "no" {
+			continue
+		}
+		docPath, ok := hrefs[ref.IDRef]
+		if !ok {
+			continue
+		}
+		file := findZipFile(archive, docPath)
+		if file == nil {
+			continue
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		heading, text, err := xhtmlToText(rc)
+		rc.Close()
+		if err != nil {
+			return nil, errors.New("không đọc được " + docPath)
+		}
+		if text == "" {
+			continue // Trang bìa, trang chỉ có ảnh...
+		}
+
+		if parts := SplitText(text, pattern); len(parts) > 1 {
+			chapters = append(chapters, parts...)
+			continue
+		}
+		number, title := ParseHeading(heading, pattern)
+		chapters = append(chapters, ParsedChapter{Number: number, Title: title, Content: text})
+	}
+	return chapters, nil
+}
+
+func openZip(data []byte) (*zip.Reader, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	if len(archive.File) > maxArchiveFiles {
+		return nil, errors.New("archive có quá nhiều file")
+	}
+	var total uint64
+	for _, file := range archive.File {
+		total += file.UncompressedSize64
+	}
+	if total > maxUncompressedBytes {
+		return nil, errors.New("archive quá lớn sau khi giải nén")
+	}
+	return archive, nil
+}
+
+func findZipFile(archive *zip.Reader, name string) *zip.File {
+	for _, file := range archive.File {
+		if file.Name == name {
+			return file
+		}
+	}
+	return nil
+}
+
+func readZipXML(archive *zip.Reader, name string, v interface{}) error {
+	file := findZipFile(archive, name)
+	if file == nil {
+		return errors.New("không tìm thấy " + name)
+	}
+	rc, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	decoder := xml.NewDecoder(rc)
+	decoder.Strict = false
+	return decoder.Decode(v)
+}
@@ -0,0 +1,194 @@
+// Package importer tách file truyện (EPUB, DOCX, ZIP chứa .txt/.md, hoặc 1 file text lớn)
+// thành danh sách chapter để import hàng loạt, và đọc trang ảnh từ CBZ/ZIP cho manga.
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ParsedChapter - 1 chapter tách được từ file
+type ParsedChapter struct {
+	Number  int    `json:"number"` // Số chapter đọc được từ tiêu đề, 0 nếu không có
+	Title   string `json:"title"`
+	Content string `json:"content"`
+}
+
+// Format - Định dạng file nguồn
+const (
+	FormatEPUB = "epub"
+	FormatDOCX = "docx"
+	FormatZIP  = "zip"
+	FormatText = "text"
+)
+
+const (
+	maxArchiveFiles      = 5000      // Số file tối đa trong 1 archive
+	maxUncompressedBytes = 200 << 20 // Tổng dung lượng giải nén tối đa (chống zip bomb)
+)
+
+// DefaultChapterPattern - Nhận dạng dòng tiêu đề "Chương 12: Tên chương" / "Chapter 12 - Title"
+// Group "number" là số chapter, group "title" là phần tên phía sau (có thể rỗng)
+const DefaultChapterPattern = `(?im)^[ \t]*(?:chương|chuong|chapter|chap\.?|ch\.)[ \t]*(?P<number>\d+)[ \t]*(?:[:：.\-–—][ \t]*)?(?P<title>.*)$`
+
+var defaultPattern = regexp.MustCompile(DefaultChapterPattern)
+
+// CompilePattern - Biên dịch regex tùy chỉnh, rỗng thì dùng mặc định
+func CompilePattern(pattern string) (*regexp.Regexp, error) {
+	if strings.TrimSpace(pattern) == "" {
+		return defaultPattern, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("regex không hợp lệ: %w", err)
+	}
+	return re, nil
+}
+
+// DetectFormat - Xác định định dạng theo phần mở rộng của tên file
+func DetectFormat(filename string) (string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".epub":
+		return FormatEPUB, nil
+	case ".docx":
+		return FormatDOCX, nil
+	case ".zip":
+		return FormatZIP, nil
+	case ".txt", ".md", ".markdown":
+		return FormatText, nil
+	default:
+		return "", errors.New("chỉ hỗ trợ file .epub, .docx, .zip, .txt, .md")
+	}
+}
+
+// Parse - Tách file thành chapters
+// EPUB tách theo spine, DOCX theo heading, ZIP mỗi file 1 chapter, text theo regex
+func Parse(filename string, data []byte, pattern *regexp.Regexp) (string, []ParsedChapter, error) {
+	if pattern == nil {
+		pattern = defaultPattern
+	}
+
+	format, err := DetectFormat(filename)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var chapters []ParsedChapter
+	switch format {
+	case FormatEPUB:
+		chapters, err = parseEPUB(data, pattern)
+	case FormatDOCX:
+		chapters, err = parseDOCX(data, pattern)
+	case FormatZIP:
+		chapters, err = parseZIP(data, pattern)
+	default:
+		chapters = SplitText(decodeText(data), pattern)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	chapters = dropEmpty(chapters)
+	if len(chapters) == 0 {
+		return "", nil, errors.New("không tìm thấy chapter nào trong file")
+	}
+	return format, chapters, nil
+}
+
+// SplitText - Tách 1 đoạn text dài thành chapters theo dòng tiêu đề khớp regex
+// Phần trước tiêu đề đầu tiên (lời mở đầu...) bị bỏ nếu chỉ có khoảng trắng, ngược lại thành "Mở đầu"
+// Không có dòng nào khớp thì trả về 1 chapter duy nhất
+func SplitText(text string, pattern *regexp.Regexp) []ParsedChapter {
+	text = normalizeNewlines(text)
+	matches := pattern.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		title, content := splitFirstLine(text)
+		return []ParsedChapter{{Title: title, Content: content}}
+	}
+
+	var chapters []ParsedChapter
+	if prologue := strings.TrimSpace(text[:matches[0][0]]); prologue != "" {
+		chapters = append(chapters, ParsedChapter{Title: "Mở đầu", Content: prologue})
+	}
+
+	for i, match := range matches {
+		end := len(text)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		number, title := headingParts(pattern, text, match)
+		chapters = append(chapters, ParsedChapter{
+			Number:  number,
+			Title:   title,
+			Content: strings.TrimSpace(text[match[1]:end]),
+		})
+	}
+	return chapters
+}
+
+// ParseHeading - Đọc số và tên chapter từ 1 dòng tiêu đề (dùng cho heading của EPUB/DOCX)
+func ParseHeading(heading string, pattern *regexp.Regexp) (int, string) {
+	heading = strings.TrimSpace(heading)
+	match := pattern.FindStringSubmatchIndex(heading)
+	if match == nil {
+		return 0, heading
+	}
+	return headingParts(pattern, heading, match)
+}
+
+// headingParts - Lấy group "number"/"title" của 1 match, tên rỗng thì dùng cả dòng tiêu đề
+func headingParts(pattern *regexp.Regexp, text string, match []int) (int, string) {
+	number := 0
+	title := ""
+	for i, name := range pattern.SubexpNames() {
+		if 2*i+1 >= len(match) || match[2*i] < 0 {
+			continue
+		}
+		value := strings.TrimSpace(text[match[2*i]:match[2*i+1]])
+		switch name {
+		case "number":
+			number, _ = strconv.Atoi(value)
+		case "title":
+			title = value
+		}
+	}
+	if title == "" {
+		title = strings.TrimSpace(text[match[0]:match[1]])
+	}
+	return number, title
+}
+
+func splitFirstLine(text string) (string, string) {
+	text = strings.TrimSpace(text)
+	title, rest, _ := strings.Cut(text, "\n")
+	title = strings.TrimSpace(strings.TrimLeft(title, "# "))
+	return title, strings.TrimSpace(rest)
+}
+
+func dropEmpty(chapters []ParsedChapter) []ParsedChapter {
+	result := chapters[:0]
+	for _, chapter := range chapters {
+		if strings.TrimSpace(chapter.Content) == "" && strings.TrimSpace(chapter.Title) == "" {
+			continue
+		}
+		if chapter.Title == "" {
+			chapter.Title = fmt.Sprintf("Chương %d", len(result)+1)
+		}
+		result = append(result, chapter)
+	}
+	return result
+}
+
+func normalizeNewlines(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(text, "\r", "\n")
+}
+
+// decodeText - Bỏ BOM UTF-8 ở đầu file text
+func decodeText(data []byte) string {
+	return strings.TrimPrefix(string(data), "\uFEFF")
+}
@@ -0,0 +1,108 @@
+package importer
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// blockElements - Thẻ XHTML tạo xuống dòng khi chuyển sang text
+var blockElements = map[string]bool{
+	"p": true, "div": true, "br": true, "li": true, "tr": true, "section": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"blockquote": true, "hr": true,
+}
+
+// xhtmlToText - Chuyển XHTML (chapter trong EPUB) thành text theo đoạn
+// Trả về thêm heading đầu tiên (h1-h3) hoặc <title> làm tiêu đề
+func xhtmlToText(r io.Reader) (heading, text string, err error) {
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	var (
+		body        strings.Builder
+		current     strings.Builder
+		title       string
+		inHeading   bool
+		inTitle     bool
+		skipDepth   int
+		headingText strings.Builder
+	)
+
+	flush := func() {
+		line := strings.Join(strings.Fields(current.String()), " ")
+		if line != "" {
+			body.WriteString(line)
+			body.WriteString("\n\n")
+		}
+		current.Reset()
+	}
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", "", err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			switch {
+			case name == "script" || name == "style":
+				skipDepth++
+			case name == "title":
+				inTitle = true
+			case name == "h1" || name == "h2" || name == "h3":
+				flush()
+				if heading == "" {
+					inHeading = true
+				}
+			case blockElements[name]:
+				flush()
+			}
+		case xml.EndElement:
+			name := strings.ToLower(t.Name.Local)
+			switch {
+			case name == "script" || name == "style":
+				if skipDepth > 0 {
+					skipDepth--
+				}
+			case name == "title":
+				inTitle = false
+			case name == "h1" || name == "h2" || name == "h3":
+				if inHeading {
+					heading = strings.Join(strings.Fields(headingText.String()), " ")
+					inHeading = false
+					current.Reset() // Heading không nằm trong nội dung
+					continue
+				}
+				flush()
+			case blockElements[name]:
+				flush()
+			}
+		case xml.CharData:
+			if skipDepth > 0 {
+				continue
+			}
+			if inTitle {
+				title += string(t)
+				continue
+			}
+			if inHeading {
+				headingText.Write(t)
+			}
+			current.Write(t)
+		}
+	}
+	flush()
+
+	if heading == "" {
+		heading = strings.TrimSpace(title)
+	}
+	return heading, strings.TrimSpace(body.String()), nil
+}
@@ -0,0 +1,83 @@
+// Package pagination - Cursor (keyset) pagination: cursor mờ (opaque) cho client infinite-scroll.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidCursor - Cursor không decode được (client tự chế hoặc bị cắt)
+var ErrInvalidCursor = errors.New("cursor không hợp lệ")
+
+// Cursor - Khóa sort của 1 dòng làm mốc. Danh sách luôn sort giảm dần theo (Pinned, Time, ID).
+// Backward = lấy trang phía trước mốc (prev_cursor).
+type Cursor struct {
+	Pinned   bool      `json:"p,omitempty"` // Chỉ dùng cho danh sách có ghim (comments)
+	Time     time.Time `json:"t"`
+	ID       uuid.UUID `json:"id"`
+	Backward bool      `json:"b,omitempty"`
+}
+
+// Cursors - Cursor trả về cho client, rỗng = hết dữ liệu theo hướng đó
+type Cursors struct {
+	Next string
+	Prev string
+}
+
+// Encode - Cursor -> chuỗi base64url để đặt vào query string
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode - Chuỗi cursor từ client -> Cursor. Chuỗi rỗng = trang đầu (nil)
+func Decode(s string) (*Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == uuid.Nil || c.Time.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// Page - Cắt kết quả keyset (đã lấy limit+1 dòng theo hướng của cursor) và sinh next/prev cursor.
+// Trang backward được đảo lại để luôn trả về theo thứ tự giảm dần.
+func Page[T any](rows []T, after *Cursor, limit int, key func(*T) Cursor) ([]T, Cursors) {
+	hasMore := len(rows) > limit
+	if hasMore {
+		rows = rows[:limit]
+	}
+	backward := after != nil && after.Backward
+	if backward {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+
+	var cursors Cursors
+	if len(rows) == 0 {
+		return rows, cursors
+	}
+	// Đi tới: còn trang sau nếu dư dòng, có trang trước nếu đang đứng sau mốc.
+	// Đi lùi: luôn còn trang sau (trang vừa rời), có trang trước nếu dư dòng.
+	if (!backward && hasMore) || backward {
+		next := key(&rows[len(rows)-1])
+		cursors.Next = next.Encode()
+	}
+	if (!backward && after != nil) || (backward && hasMore) {
+		prev := key(&rows[0])
+		prev.Backward = true
+		cursors.Prev = prev.Encode()
+	}
+	return rows, cursors
+}
@@ -0,0 +1,146 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 30, 45, 123456789, time.UTC)
+	id := uuid.MustParse("6f1c2a9e-4b7d-4c1e-9a55-0d2f3b4c5d6e")
+
+	tests := []struct {
+		name   string
+		cursor Cursor
+	}{
+		{name: "forward", cursor: Cursor{Time: at, ID: id}},
+		{name: "backward", cursor: Cursor{Time: at, ID: id, Backward: true}},
+		{name: "pinned", cursor: Cursor{Pinned: true, Time: at, ID: id}},
+		{name: "non-UTC time", cursor: Cursor{Time: at.In(time.FixedZone("ICT", 7*3600)), ID: id}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := tt.cursor.Encode()
+			if _, err := base64.RawURLEncoding.DecodeString(encoded); err != nil {
+				t.Fatalf("Encode() = %q không phải base64url: %v", encoded, err)
+			}
+			got, err := Decode(encoded)
+			if err != nil {
+				t.Fatalf("Decode(%q) error = %v", encoded, err)
+			}
+			if !got.Time.Equal(tt.cursor.Time) || got.ID != tt.cursor.ID ||
+				got.Pinned != tt.cursor.Pinned || got.Backward != tt.cursor.Backward {
+				t.Fatalf("Decode(Encode()) = %+v, want %+v", *got, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "not base64", input: "%%%"},
+		{name: "padded base64", input: base64.URLEncoding.EncodeToString([]byte(`{"t":"2026-01-01T00:00:00Z"}`))},
+		{name: "not json", input: encode("hello")},
+		{name: "missing id", input: encode(`{"t":"2026-01-01T00:00:00Z"}`)},
+		{name: "missing time", input: encode(`{"id":"6f1c2a9e-4b7d-4c1e-9a55-0d2f3b4c5d6e"}`)},
+		{name: "bad id", input: encode(`{"t":"2026-01-01T00:00:00Z","id":"nope"}`)},
+		{name: "truncated", input: Cursor{Time: time.Now(), ID: uuid.New()}.Encode()[:10]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := Decode(tt.input); !errors.Is(err, ErrInvalidCursor) {
+				t.Fatalf("Decode(%q) = %+v, %v, want ErrInvalidCursor", tt.input, got, err)
+			}
+		})
+	}
+}
+
+func TestDecodeEmpty(t *testing.T) {
+	got, err := Decode("")
+	if got != nil || err != nil {
+		t.Fatalf("Decode(\"\") = %+v, %v, want nil, nil", got, err)
+	}
+}
+
+// row - Dòng giả, ID tăng theo thứ tự để kiểm tra cursor trỏ đúng dòng
+type row struct {
+	n int
+}
+
+func rowKey(r *row) Cursor {
+	return Cursor{Time: time.Unix(int64(r.n), 0).UTC(), ID: uuid.UUID{15: byte(r.n)}}
+}
+
+func rows(ns ...int) []row {
+	out := make([]row, len(ns))
+	for i, n := range ns {
+		out[i] = row{n: n}
+	}
+	return out
+}
+
+func TestPage(t *testing.T) {
+	forward := &Cursor{Time: time.Unix(100, 0), ID: uuid.New()}
+	backward := &Cursor{Time: time.Unix(100, 0), ID: uuid.New(), Backward: true}
+
+	tests := []struct {
+		name     string
+		rows     []row // Như repo trả về: limit+1 dòng theo hướng của cursor
+		after    *Cursor
+		limit    int
+		wantRows []row
+		wantNext int // n của dòng làm next cursor, 0 = không có
+		wantPrev int // n của dòng làm prev cursor, 0 = không có
+	}{
+		{name: "first page, more", rows: rows(9, 8, 7, 6), limit: 3, wantRows: rows(9, 8, 7), wantNext: 7},
+		{name: "first page, last", rows: rows(9, 8), limit: 3, wantRows: rows(9, 8)},
+		{name: "first page, empty", rows: nil, limit: 3, wantRows: nil},
+		{name: "forward, more", rows: rows(6, 5, 4, 3), after: forward, limit: 3, wantRows: rows(6, 5, 4), wantNext: 4, wantPrev: 6},
+		{name: "forward, last", rows: rows(3, 2), after: forward, limit: 3, wantRows: rows(3, 2), wantPrev: 3},
+		{name: "forward, empty", rows: rows(), after: forward, limit: 3, wantRows: rows()},
+		// Đi lùi: repo trả tăng dần (gần mốc nhất trước), Page đảo lại thành giảm dần
+		{name: "backward, more", rows: rows(7, 8, 9, 10), after: backward, limit: 3, wantRows: rows(9, 8, 7), wantNext: 7, wantPrev: 9},
+		{name: "backward, reached start", rows: rows(7, 8), after: backward, limit: 3, wantRows: rows(8, 7), wantNext: 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, cursors := Page(tt.rows, tt.after, tt.limit, rowKey)
+			if !reflect.DeepEqual(got, tt.wantRows) {
+				t.Fatalf("rows = %v, want %v", got, tt.wantRows)
+			}
+			checkCursor(t, "Next", cursors.Next, tt.wantNext, false)
+			checkCursor(t, "Prev", cursors.Prev, tt.wantPrev, true)
+		})
+	}
+}
+
+func checkCursor(t *testing.T, field, encoded string, wantN int, wantBackward bool) {
+	t.Helper()
+	if wantN == 0 {
+		if encoded != "" {
+			t.Fatalf("%s = %q, want rỗng", field, encoded)
+		}
+		return
+	}
+	c, err := Decode(encoded)
+	if err != nil || c == nil {
+		t.Fatalf("%s = %q không decode được: %v", field, encoded, err)
+	}
+	want := rowKey(&row{n: wantN})
+	if !c.Time.Equal(want.Time) || c.ID != want.ID || c.Backward != wantBackward {
+		t.Fatalf("%s = %+v, want dòng %d (backward=%v)", field, *c, wantN, wantBackward)
+	}
+}
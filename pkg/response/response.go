@@ -26,6 +26,8 @@ type Meta struct {
 	Limit		int				`json:"limit"`
 	Total		int64			`json:"total"`
 	TotalPages	int				`json:"total_pages"`
+	NextCursor	string			`json:"next_cursor,omitempty"`
+	PrevCursor	string			`json:"prev_cursor,omitempty"`
 }
 
 //Phản hồi Thành Công (Response Success)
@@ -46,22 +48,40 @@ func Created(c *gin.Context, data interface{}){
 
 //Phân Trang - Pagination
 func PaginatedResponse(c *gin.Context, data interface{},page,limit int, total int64){
-	totalPages := int(total)/limit
-	if int(total)%limit>0{
-		totalPages++
-	}
+	c.JSON(http.StatusOK, Pagination{
+		Success: true,
+		Data: data,
+		Meta: NewMeta(page, limit, total),
+	})
+}
+
+//Phân Trang Cursor - Keyset pagination (?cursor=), không có page/total
+func CursorPaginatedResponse(c *gin.Context, data interface{}, limit int, nextCursor, prevCursor string){
 	c.JSON(http.StatusOK, Pagination{
 		Success: true,
 		Data: data,
 		Meta: Meta{
-			Page: page,
 			Limit: limit,
-			Total: total,
-			TotalPages: totalPages,
+			NextCursor: nextCursor,
+			PrevCursor: prevCursor,
 		},
 	})
 }
 
+//Tạo Meta phân trang - Build pagination meta (dùng khi response có thêm dữ liệu khác ngoài danh sách)
+func NewMeta(page, limit int, total int64) Meta {
+	totalPages := int(total)/limit
+	if int(total)%limit>0{
+		totalPages++
+	}
+	return Meta{
+		Page: page,
+		Limit: limit,
+		Total: total,
+		TotalPages: totalPages,
+	}
+}
+
 //400 - Phản hồi Thất Bại (Response Error)
 func BadRequest(c *gin.Context, message string){
 	c.JSON(http.StatusBadRequest, Response{
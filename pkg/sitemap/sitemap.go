@@ -0,0 +1,68 @@
+// Package sitemap dựng sitemap.xml (urlset) và sitemap index theo sitemaps.org 0.9.
+package sitemap
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+const (
+	xmlns = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+	// MaxURLs - Giới hạn số URL trong 1 file sitemap theo chuẩn
+	MaxURLs = 50000
+)
+
+// URL - 1 mục trong urlset hoặc sitemap index (LastMod zero = bỏ qua)
+type URL struct {
+	Loc     string
+	LastMod time.Time
+}
+
+type urlEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type urlSet struct {
+	XMLName xml.Name   `xml:"urlset"`
+	XMLNS   string     `xml:"xmlns,attr"`
+	URLs    []urlEntry `xml:"url"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name   `xml:"sitemapindex"`
+	XMLNS    string     `xml:"xmlns,attr"`
+	Sitemaps []urlEntry `xml:"sitemap"`
+}
+
+// URLSet - Dựng <urlset> từ danh sách URL
+func URLSet(urls []URL) ([]byte, error) {
+	return marshal(urlSet{XMLNS: xmlns, URLs: entries(urls)})
+}
+
+// Index - Dựng <sitemapindex> trỏ tới các sitemap con
+func Index(sitemaps []URL) ([]byte, error) {
+	return marshal(sitemapIndex{XMLNS: xmlns, Sitemaps: entries(sitemaps)})
+}
+
+func entries(urls []URL) []urlEntry {
+	out := make([]urlEntry, 0, len(urls))
+	for _, u := range urls {
+		e := urlEntry{Loc: u.Loc}
+		if !u.LastMod.IsZero() {
+			e.LastMod = u.LastMod.UTC().Format(time.RFC3339) // W3C Datetime
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// marshal - XML kèm header
+func marshal(v interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/cloudinary/cloudinary-go/v2"
+	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+)
+
+// Cloudinary - Driver Cloudinary. Key trên Cloudinary là public_id (folder/name, không có đuôi file)
+type Cloudinary struct {
+	cld *cloudinary.Cloudinary
+}
+
+func NewCloudinary(cloudName, apiKey, apiSecret string) (*Cloudinary, error) {
+	if cloudName == "" || apiKey == "" || apiSecret == "" {
+		return nil, errors.New("cloudinary config is not set")
+	}
+	cld, err := cloudinary.NewFromParams(cloudName, apiKey, apiSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cloudinary: %w", err)
+	}
+	return &Cloudinary{cld: cld}, nil
+}
+
+func (c *Cloudinary) Name() string { return "cloudinary" }
+
+// Put - Upload với public_id = key bỏ đuôi, Cloudinary tự convert sang WebP
+func (c *Cloudinary) Put(ctx context.Context, key string, data []byte, _ string) (string, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	publicID := strings.TrimSuffix(key, path.Ext(key))
+
+	result, err := c.cld.Upload.Upload(ctx, bytes.NewReader(data), uploader.UploadParams{
+		PublicID:       publicID,
+		ResourceType:   "image",
+		Transformation: "f_webp,q_auto", // Force WebP with auto quality
+	})
+	if err != nil {
+		return "", fmt.Errorf("upload failed: %w", err)
+	}
+	if result.Error.Message != "" {
+		return "", fmt.Errorf("cloudinary error: %s", result.Error.Message)
+	}
+	return result.SecureURL, nil
+}
+
+// Delete - key là public_id (đuôi file nếu có sẽ bị bỏ)
+func (c *Cloudinary) Delete(ctx context.Context, key string) error {
+	key, err := CleanKey(key)
+	if err != nil {
+		return err
+	}
+	_, err = c.cld.Upload.Destroy(ctx, uploader.DestroyParams{
+		PublicID: strings.TrimSuffix(key, path.Ext(key)),
+	})
+	if err != nil {
+		return fmt.Errorf("delete failed: %w", err)
+	}
+	return nil
+}
+
+// KeyFromURL - Lấy public_id từ URL Cloudinary
+// Format: https://res.cloudinary.com/{cloud}/image/upload/{transformations}/{version}/{folder}/{filename}
+func (c *Cloudinary) KeyFromURL(rawURL string) string {
+	uploadIdx := strings.Index(rawURL, "/upload/")
+	if uploadIdx == -1 {
+		return ""
+	}
+	afterUpload := rawURL[uploadIdx+len("/upload/"):]
+	if i := strings.IndexAny(afterUpload, "?#"); i != -1 {
+		afterUpload = afterUpload[:i]
+	}
+
+	// Bỏ version (v123456789) và transformation (f_webp, q_auto, w_300, h_200...)
+	var parts []string
+	for _, part := range strings.Split(afterUpload, "/") {
+		if part == "" || isCloudinaryVersion(part) || isCloudinaryTransformation(part) {
+			continue
+		}
+		parts = append(parts, part)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+
+	publicID := strings.Join(parts, "/")
+	return strings.TrimSuffix(publicID, path.Ext(publicID))
+}
+
+func isCloudinaryVersion(part string) bool {
+	if len(part) < 2 || part[0] != 'v' {
+		return false
+	}
+	for _, c := range part[1:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isCloudinaryTransformation(part string) bool {
+	for _, prefix := range []string{"f_", "q_", "w_", "h_"} {
+		if strings.HasPrefix(part, prefix) {
+			return true
+		}
+	}
+	return false
+}
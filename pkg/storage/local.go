@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Local - Lưu file xuống disk, Gin serve thư mục gốc tại baseURL (xem routes)
+type Local struct {
+	root    string
+	baseURL string
+}
+
+// NewLocal - root là thư mục lưu file, baseURL là URL public tương ứng (vd: http://localhost:9091/uploads)
+func NewLocal(root, baseURL string) (*Local, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	return &Local{root: root, baseURL: strings.TrimRight(baseURL, "/")}, nil
+}
+
+func (l *Local) Name() string { return "local" }
+
+// Put - Ghi file qua file tạm rồi rename để không bao giờ serve file ghi dở
+func (l *Local) Put(_ context.Context, key string, data []byte, _ string) (string, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	target := filepath.Join(l.root, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return "", err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return "", err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+
+	return l.baseURL + "/" + encodeKey(key), nil
+}
+
+// Delete - Xóa file, không lỗi nếu file đã không còn
+func (l *Local) Delete(_ context.Context, key string) error {
+	key, err := CleanKey(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(filepath.Join(l.root, filepath.FromSlash(key)))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (l *Local) KeyFromURL(rawURL string) string {
+	return keyFromBaseURL(l.baseURL, rawURL)
+}
@@ -0,0 +1,235 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Options - Cấu hình bucket S3 hoặc server tương thích (MinIO, R2, Wasabi...)
+type S3Options struct {
+	Endpoint  string // vd: https://s3.ap-southeast-1.amazonaws.com, http://localhost:9000
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool   // endpoint/bucket/key thay vì bucket.endpoint/key (MinIO cần true)
+	PublicURL string // URL public trước key (CDN), mặc định là URL object trên endpoint
+}
+
+// S3 - Driver S3-compatible tự ký request bằng AWS Signature V4 (PUT/DELETE object)
+type S3 struct {
+	opts       S3Options
+	endpoint   *url.URL
+	publicURL  string
+	httpClient *http.Client
+}
+
+func NewS3(opts S3Options) (*S3, error) {
+	if opts.Endpoint == "" || opts.Bucket == "" || opts.AccessKey == "" || opts.SecretKey == "" {
+		return nil, errors.New("thiếu cấu hình S3 (endpoint, bucket, access key, secret key)")
+	}
+	if opts.Region == "" {
+		opts.Region = "us-east-1"
+	}
+	endpoint, err := url.Parse(strings.TrimRight(opts.Endpoint, "/"))
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("S3 endpoint không hợp lệ: %q", opts.Endpoint)
+	}
+
+	s := &S3{
+		opts:       opts,
+		endpoint:   endpoint,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}
+	s.publicURL = strings.TrimRight(opts.PublicURL, "/")
+	if s.publicURL == "" {
+		s.publicURL = s.bucketURL()
+	}
+	return s, nil
+}
+
+func (s *S3) Name() string { return "s3" }
+
+// Put - PUT object, payload được ký bằng SHA-256 thật (không dùng UNSIGNED-PAYLOAD)
+func (s *S3) Put(ctx context.Context, key string, data []byte, contentType string) (string, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key), bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Cache-Control", "public, max-age=31536000, immutable") // Key luôn mới, không ghi đè
+	if err := s.do(req, sha256Hex(data), http.StatusOK); err != nil {
+		return "", err
+	}
+	return s.publicURL + "/" + encodeKey(key), nil
+}
+
+// Delete - DELETE object (S3 trả 204 kể cả khi object không tồn tại)
+func (s *S3) Delete(ctx context.Context, key string) error {
+	key, err := CleanKey(key)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key), nil)
+	if err != nil {
+		return err
+	}
+	return s.do(req, sha256Hex(nil), http.StatusNoContent, http.StatusOK)
+}
+
+func (s *S3) KeyFromURL(rawURL string) string {
+	if key := keyFromBaseURL(s.publicURL, rawURL); key != "" {
+		return key
+	}
+	return keyFromBaseURL(s.bucketURL(), rawURL)
+}
+
+func (s *S3) do(req *http.Request, payloadHash string, okStatus ...int) error {
+	s.sign(req, payloadHash, time.Now())
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	for _, status := range okStatus {
+		if resp.StatusCode == status {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			return nil
+		}
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("S3 %s %s: %s %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(body)))
+}
+
+// bucketURL - URL gốc của bucket theo path-style hoặc virtual-hosted style
+func (s *S3) bucketURL() string {
+	if s.opts.PathStyle {
+		return s.endpoint.Scheme + "://" + s.endpoint.Host + "/" + s.opts.Bucket
+	}
+	return s.endpoint.Scheme + "://" + s.opts.Bucket + "." + s.endpoint.Host
+}
+
+func (s *S3) objectURL(key string) string {
+	return s.bucketURL() + "/" + encodeKey(key)
+}
+
+// sign - AWS Signature Version 4, ký host + mọi header đã set trên request
+func (s *S3) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.opts.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+s.opts.SecretKey), date)
+	key = hmacSHA256(key, s.opts.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+s.opts.AccessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func canonicalQuery(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		vals := append([]string(nil), values[k]...)
+		sort.Strings(vals)
+		for _, v := range vals {
+			parts = append(parts, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// encodeKey - Encode từng segment của key theo quy tắc SigV4 (giữ nguyên "/")
+func encodeKey(key string) string {
+	return uriEncode(key, false)
+}
+
+// uriEncode - Percent-encode mọi byte trừ A-Z a-z 0-9 - _ . ~ (và "/" nếu encodeSlash = false)
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,61 @@
+// Package storage - Driver lưu file upload (local disk, S3-compatible, Cloudinary).
+package storage
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// ErrInvalidKey - Key rỗng hoặc cố thoát ra ngoài thư mục gốc
+var ErrInvalidKey = errors.New("storage key không hợp lệ")
+
+// Driver - Nơi lưu file. Key dạng "folder/name.ext", URL trả về là URL public của file.
+type Driver interface {
+	Name() string
+	Put(ctx context.Context, key string, data []byte, contentType string) (string, error)
+	Delete(ctx context.Context, key string) error
+	// KeyFromURL - Suy ra key từ URL public, "" nếu URL không thuộc driver này
+	KeyFromURL(url string) string
+}
+
+// CleanKey - Chuẩn hóa key, chặn "..", đường dẫn tuyệt đối và key rỗng
+func CleanKey(key string) (string, error) {
+	key = strings.TrimPrefix(strings.ReplaceAll(key, "\\", "/"), "/")
+	if key == "" {
+		return "", ErrInvalidKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == ".." {
+			return "", ErrInvalidKey
+		}
+	}
+	cleaned := path.Clean(key)
+	if cleaned == "." || strings.HasPrefix(cleaned, "../") {
+		return "", ErrInvalidKey
+	}
+	return cleaned, nil
+}
+
+// keyFromBaseURL - Cắt baseURL khỏi url (dùng cho local và S3)
+func keyFromBaseURL(baseURL, rawURL string) string {
+	prefix := strings.TrimRight(baseURL, "/") + "/"
+	if !strings.HasPrefix(rawURL, prefix) {
+		return ""
+	}
+	key := rawURL[len(prefix):]
+	if i := strings.IndexAny(key, "?#"); i != -1 {
+		key = key[:i]
+	}
+	key, err := url.PathUnescape(key)
+	if err != nil {
+		return ""
+	}
+	key, err = CleanKey(key)
+	if err != nil {
+		return ""
+	}
+	return key
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCleanKey(t *testing.T) {
+	tests := []struct {
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{key: "stories/cover.webp", want: "stories/cover.webp"},
+		{key: "/stories/cover.webp", want: "stories/cover.webp"},
+		{key: `chapters\1\page.webp`, want: "chapters/1/page.webp"},
+		{key: "stories//./cover.webp", want: "stories/cover.webp"},
+		{key: "chapters/chapter-1.5/01.webp", want: "chapters/chapter-1.5/01.webp"},
+		{key: "", wantErr: true},
+		{key: "/", wantErr: true},
+		{key: ".", wantErr: true},
+		{key: "../secret", wantErr: true},
+		{key: "stories/../../secret", wantErr: true},
+		{key: `stories\..\..\secret`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := CleanKey(tt.key)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("CleanKey(%q) = %q, %v, want ErrInvalidKey", tt.key, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("CleanKey(%q) = %q, %v, want %q", tt.key, got, err, tt.want)
+		}
+	}
+}
+
+func TestKeyFromBaseURL(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		rawURL  string
+		want    string
+	}{
+		{baseURL: "https://cdn.example.com", rawURL: "https://cdn.example.com/stories/a.webp", want: "stories/a.webp"},
+		{baseURL: "https://cdn.example.com/", rawURL: "https://cdn.example.com/stories/a%20b.webp?v=1#x", want: "stories/a b.webp"},
+		{baseURL: "https://cdn.example.com", rawURL: "https://other.example.com/stories/a.webp", want: ""},
+		{baseURL: "https://cdn.example.com", rawURL: "https://cdn.example.com.evil/stories/a.webp", want: ""},
+		{baseURL: "https://cdn.example.com", rawURL: "https://cdn.example.com/%2E%2E/secret", want: ""},
+		{baseURL: "https://cdn.example.com", rawURL: "https://cdn.example.com/bad%zz", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := keyFromBaseURL(tt.baseURL, tt.rawURL); got != tt.want {
+			t.Errorf("keyFromBaseURL(%q, %q) = %q, want %q", tt.baseURL, tt.rawURL, got, tt.want)
+		}
+	}
+}
+
+func TestCloudinaryKeyFromURL(t *testing.T) {
+	tests := []struct {
+		rawURL string
+		want   string
+	}{
+		{rawURL: "https://res.cloudinary.com/demo/image/upload/v1712345678/stories/abc.webp", want: "stories/abc"},
+		{rawURL: "https://res.cloudinary.com/demo/image/upload/f_webp,q_auto/v1712345678/stories/abc.jpg", want: "stories/abc"},
+		{rawURL: "https://res.cloudinary.com/demo/image/upload/w_300/h_200/avatars/u1.png?x=1", want: "avatars/u1"},
+		{rawURL: "https://res.cloudinary.com/demo/image/upload/v1712345678/", want: ""},
+		{rawURL: "https://example.com/stories/abc.webp", want: ""},
+	}
+
+	c := &Cloudinary{}
+	for _, tt := range tests {
+		if got := c.KeyFromURL(tt.rawURL); got != tt.want {
+			t.Errorf("KeyFromURL(%q) = %q, want %q", tt.rawURL, got, tt.want)
+		}
+	}
+}
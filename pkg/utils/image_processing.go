@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"image"
+	_ "image/gif" // Register GIF decoder
 	"image/jpeg"
 	_ "image/png" // Register PNG decoder
 	"io"
@@ -12,6 +13,7 @@ import (
 	"strings"
 
 	"github.com/disintegration/imaging"
+	_ "golang.org/x/image/webp" // Register WebP decoder (trang manga trong CBZ thường là webp)
 )
 
 // ImageConfig holds configuration for image processing
@@ -105,6 +107,19 @@ func ProcessChapterImage(file multipart.File, header *multipart.FileHeader) (*Pr
 	return ProcessImage(file, header, ChapterImageConfig)
 }
 
+// bytesFile wraps in-memory data as a multipart.File
+type bytesFile struct {
+	*bytes.Reader
+}
+
+func (bytesFile) Close() error { return nil }
+
+// ProcessChapterImageBytes processes a chapter image already in memory (e.g. a page read from a CBZ)
+func ProcessChapterImageBytes(data []byte, filename string) (*ProcessedImage, error) {
+	header := &multipart.FileHeader{Filename: filename, Size: int64(len(data))}
+	return ProcessChapterImage(bytesFile{bytes.NewReader(data)}, header)
+}
+
 // calculateDimensions calculates new dimensions while maintaining aspect ratio
 func calculateDimensions(origWidth, origHeight, maxWidth, maxHeight int) (int, int) {
 	// If image is smaller than max, keep original size